@@ -0,0 +1,77 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// accessorPrefixes are the method name prefixes of accessors.
+var accessorPrefixes = []string{"Get", "Set", "Is", "Has"}
+
+// accessorsForFunc returns the accessor methods folded under the type tname.
+func (c *Converter) accessorsForFunc(tname string) []*doc.Func {
+	return c.accessorFuncs[tname]
+}
+
+// fieldCount returns the number of parameters or results in fl.
+func fieldCount(fl *ast.FieldList) int {
+	if fl == nil {
+		return 0
+	}
+	return fl.NumFields()
+}
+
+// isAccessor reports whether the method m is a trivially documented
+// getter or setter: a Get/Set/Is/Has method or a plain "Name() T"
+// getter, with at most a one-line doc comment.
+func isAccessor(m *doc.Func) bool {
+	if strings.Contains(strings.TrimSpace(m.Doc), "\n") {
+		return false
+	}
+	params, results := fieldCount(m.Decl.Type.Params), fieldCount(m.Decl.Type.Results)
+	for _, prefix := range accessorPrefixes {
+		if strings.HasPrefix(m.Name, prefix) && (len(m.Name) == len(prefix) || startsWithUppercase(m.Name[len(prefix):])) {
+			if prefix == "Set" {
+				return params == 1 && results == 0
+			}
+			return params == 0 && results == 1
+		}
+	}
+	return params == 0 && results == 1 && strings.HasPrefix(m.Doc, m.Name+" returns ")
+}
+
+// foldAccessors moves the accessor methods without examples of every
+// type out of its method list, to be rendered as a compact table.
+func (c *Converter) foldAccessors(info *godoc.PageInfo) {
+	c.accessorFuncs = make(map[string][]*doc.Func)
+	if info.PDoc == nil {
+		return
+	}
+	hasExample := make(map[string]bool)
+	for _, eg := range info.Examples {
+		hasExample[stripExampleSuffix(eg.Name)] = true
+	}
+	for _, t := range info.PDoc.Types {
+		var rest []*doc.Func
+		for _, m := range t.Methods {
+			if isAccessor(m) && !hasExample[t.Name+"_"+m.Name] {
+				c.accessorFuncs[t.Name] = append(c.accessorFuncs[t.Name], m)
+			} else {
+				rest = append(rest, m)
+			}
+		}
+		t.Methods = rest
+	}
+}
+
+// accessorRowFunc returns the table row describing an accessor method.
+func (c *Converter) accessorRowFunc(info *godoc.PageInfo, tname string, m *doc.Func) string {
+	cell := func(s string) string {
+		return strings.Replace(strings.TrimSpace(s), "|", "\\|", -1)
+	}
+	return "| " + strings.TrimSuffix(c.anchorFunc(tname+"."+m.Name), "\n") + `<a name="` + tname + "." + m.Name + `">` + m.Name + "</a> | `" +
+		cell(c.nodeText(info, m.Decl)) + "` | " + cell(m.Doc) + " |"
+}
@@ -0,0 +1,247 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"html"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// An apiSymbol is an exported symbol of the API of a package: its kind,
+// "const", "var", "func", "type" or "method", and its declaration,
+// without comments and, for the constants and variables, without the
+// other specs of its group.
+type apiSymbol struct {
+	kind, decl string
+}
+
+// mdDeclRx matches the declarations of the Markdown written by Convert:
+// Go code blocks, and the <pre> blocks of the declarations with links.
+var mdDeclRx = regexp.MustCompile("(?s)``` go\n(.*?)\n```|<pre>(.*?)</pre>")
+
+// tagRx matches the HTML tags of the declarations with links.
+var tagRx = regexp.MustCompile(`<[^>]*>`)
+
+// apiSurface returns the exported symbols declared by the sources decls,
+// by name, T.M for the methods. The sources that aren't declarations,
+// such as those of examples, and the functions with bodies are skipped.
+func apiSurface(decls []string) map[string]apiSymbol {
+	symbols := make(map[string]apiSymbol)
+	config := &printer.Config{Mode: printer.UseSpaces, Tabwidth: 8}
+	print := func(fset *token.FileSet, node interface{}) string {
+		var buf bytes.Buffer
+		if err := config.Fprint(&buf, fset, node); err != nil {
+			return ""
+		}
+		return buf.String()
+	}
+	for _, src := range decls {
+		if strings.HasPrefix(src, "package ") {
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", "package p\n"+src, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Body != nil || !d.Name.IsExported() {
+					continue
+				}
+				if d.Recv == nil {
+					symbols[d.Name.Name] = apiSymbol{"func", print(fset, d)}
+				} else if len(d.Recv.List) == 1 {
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					switch x := recv.(type) {
+					case *ast.IndexExpr:
+						recv = x.X
+					case *ast.IndexListExpr:
+						recv = x.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						symbols[id.Name+"."+d.Name.Name] = apiSymbol{"method", print(fset, d)}
+					}
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if s.Name.IsExported() {
+							symbols[s.Name.Name] = apiSymbol{"type", "type " + print(fset, s)}
+						}
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							if name.IsExported() {
+								symbols[name.Name] = apiSymbol{d.Tok.String(), d.Tok.String() + " " + print(fset, s)}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// docDecls returns the declarations of the documentation model p.
+func docDecls(p *PackageDoc) []string {
+	var decls []string
+	values := func(values []ValueDoc) {
+		for _, v := range values {
+			decls = append(decls, v.Decl)
+		}
+	}
+	funcs := func(funcs []FuncDoc) {
+		for _, f := range funcs {
+			decls = append(decls, f.Decl)
+		}
+	}
+	values(p.Consts)
+	values(p.Vars)
+	funcs(p.Funcs)
+	for _, t := range p.Types {
+		decls = append(decls, t.Decl)
+		values(t.Consts)
+		values(t.Vars)
+		funcs(t.Funcs)
+		funcs(t.Methods)
+	}
+	return decls
+}
+
+// previousSurfaces returns the API of the packages documented by old, a
+// previous output of ConvertJSON, a JSON object per package, by import
+// path, or of Convert, by the empty import path, as Markdown doesn't
+// record it.
+func previousSurfaces(old []byte) (map[string]map[string]apiSymbol, error) {
+	surfaces := make(map[string]map[string]apiSymbol)
+	if trimmed := bytes.TrimSpace(old); len(trimmed) > 0 && trimmed[0] == '{' {
+		docs, err := ReadPackageDocs(old)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range docs {
+			surfaces[p.ImportPath] = apiSurface(docDecls(p))
+		}
+		return surfaces, nil
+	}
+	var decls []string
+	for _, m := range mdDeclRx.FindAllStringSubmatch(string(old), -1) {
+		if m[1] != "" {
+			decls = append(decls, m[1])
+		} else {
+			decls = append(decls, html.UnescapeString(tagRx.ReplaceAllString(m[2], "")))
+		}
+	}
+	surfaces[""] = apiSurface(decls)
+	return surfaces, nil
+}
+
+// ConvertAPIDiff writes to w an API changes section, for release notes,
+// listing the exported symbols of the packages paths added, removed and
+// changed since old, a previous output of ConvertJSON or, for a single
+// package, of Convert, with the version of the release if set. The
+// changes of the declarations are shown when they fit on a line, and
+// those of the doc comments are ignored.
+func (c *Converter) ConvertAPIDiff(paths []string, old []byte, version string, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	surfaces, err := previousSurfaces(old)
+	if err != nil {
+		return fmt.Errorf("reading the previous API: %v", err)
+	}
+	if _, ok := surfaces[""]; ok && len(paths) > 1 {
+		return fmt.Errorf("a previous Markdown output documents a single package, use the JSON format for %d packages", len(paths))
+	}
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-api-changes") + c.headingFunc("section", 2) + "<a name=\"pkg-api-changes\">API changes</a>\n\n")
+	if version != "" {
+		buf.WriteString("Version `" + version + "`\n\n")
+	}
+	changed := false
+	for _, path := range paths {
+		info, err := c.pageInfo(ioutil.Discard, path)
+		if err != nil {
+			return err
+		}
+		p := c.packageDoc(info)
+		if p.ImportPath == "" || p.IsCommand {
+			continue
+		}
+		prev, ok := surfaces[p.ImportPath]
+		if !ok {
+			prev = surfaces[""]
+		}
+		md := c.apiChangesMD(prev, apiSurface(docDecls(p)))
+		if md == "" {
+			continue
+		}
+		if len(paths) > 1 {
+			buf.WriteString(c.headingFunc("subsection", 3) + c.mdFunc(p.ImportPath) + "\n\n")
+		}
+		buf.WriteString(md)
+		changed = true
+	}
+	if !changed {
+		buf.WriteString("No changes.\n")
+	}
+	out := buf.String()
+	if c.dialect.plain {
+		out = plainMarkdown(out)
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// apiChangesMD returns the lists of the symbols added, removed and
+// changed between the APIs old and new, or the empty string when they
+// are the same.
+func (c *Converter) apiChangesMD(old, new map[string]apiSymbol) string {
+	var added, removed, changed []string
+	for name, s := range new {
+		if prev, ok := old[name]; !ok {
+			added = append(added, name)
+		} else if prev.decl != s.decl {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	var buf strings.Builder
+	list := func(title string, names []string, symbols map[string]apiSymbol) {
+		if len(names) == 0 {
+			return
+		}
+		sort.Strings(names)
+		buf.WriteString(c.headingFunc("subsection", 4) + title + "\n\n")
+		for _, name := range names {
+			s := symbols[name]
+			buf.WriteString("* " + s.kind + " `" + name + "`")
+			if prev := old[name].decl; title == "Changed" && !strings.Contains(prev, "\n") && !strings.Contains(s.decl, "\n") {
+				buf.WriteString(": `" + prev + "` → `" + s.decl + "`")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	list("Added", added, new)
+	list("Removed", removed, old)
+	list("Changed", changed, new)
+	return buf.String()
+}
@@ -0,0 +1,167 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// recordAsset records an asset referenced from a doc comment, and
+// reports whether ref is one. Absolute paths, URLs and paths escaping
+// the package directory are left alone.
+func (c *Converter) recordAsset(ref string) bool {
+	if strings.Contains(ref, "://") || pathpkg.IsAbs(ref) {
+		return false
+	}
+	ref = pathpkg.Clean(ref)
+	if ref == ".." || strings.HasPrefix(ref, "../") {
+		return false
+	}
+	for _, r := range c.assetRefs {
+		if r == ref {
+			return true
+		}
+	}
+	c.assetRefs = append(c.assetRefs, ref)
+	return true
+}
+
+// recordOutput records the file name as written, or up to date, by the
+// conversions, see Outputs.
+func (c *Converter) recordOutput(name string) {
+	for _, o := range c.outputs {
+		if o == name {
+			return
+		}
+	}
+	c.outputs = append(c.outputs, name)
+}
+
+// Outputs returns the files written, or found up to date, by the
+// conversions to files, such as ConvertTree and ConvertSplit, and the
+// assets copied next to the documentation, for publishing them.
+func (c *Converter) Outputs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.outputs...)
+}
+
+// attachmentName returns the name of the asset ref of the package in the
+// attachments directory of wikis, which is flat: its path prefixed with
+// the name of the package, with hyphens for slashes, such as
+// samp-diagrams-arch.png.
+func (c *Converter) attachmentName(ref string) string {
+	name := strings.Replace(pathpkg.Clean(ref), "/", "-", -1)
+	if c.pdoc != nil {
+		name = c.pdoc.Name + "-" + name
+	}
+	return name
+}
+
+// assetLink returns the link to the asset ref referenced from a doc
+// comment: the URL of its raw contents on the host of the package with
+// WithRawLinks, or ref itself, recording it to be copied.
+func (c *Converter) assetLink(ref string) string {
+	if c.rawLinks && c.pdoc != nil && !strings.Contains(ref, "://") && !pathpkg.IsAbs(ref) {
+		clean := pathpkg.Clean(ref)
+		if clean != ".." && !strings.HasPrefix(clean, "../") {
+			if url, ok := c.rawURL(c.pdoc.ImportPath); ok {
+				return url + "/" + clean
+			}
+		}
+	}
+	if c.recordAsset(ref) && c.dialect.attachments != "" {
+		return c.dialect.attachments + c.attachmentName(ref)
+	}
+	return ref
+}
+
+// assetRefRx matches the relative paths of files under a directory, such
+// as doc/arch.png, referenced from the text of doc comments.
+var assetRefRx = regexp.MustCompile(`(?:\./)?(?:[\w-][\w.-]*/)+[\w-][\w.-]*\.[A-Za-z0-9]+`)
+
+// imageExts are the extensions of the assets referenced as images.
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true}
+
+// plainMD renders the plain text s of a doc comment. With
+// WithEmbedAssets, the paths of the files of the package directory it
+// references, other than Go files, are linked, as images for pictures,
+// and recorded to be copied with the output, see assetLink.
+func (c *Converter) plainMD(s string) string {
+	if !c.embedAssets || c.pdocDir == "" {
+		return c.identsMD(escape(s, c.dialect.prose))
+	}
+	var buf strings.Builder
+	last := 0
+	for _, m := range assetRefRx.FindAllStringIndex(s, -1) {
+		ref := s[m[0]:m[1]]
+		if m[0] > 0 && (isIdentByte(s[m[0]-1]) || strings.IndexByte("/.-", s[m[0]-1]) >= 0) ||
+			pathpkg.Ext(ref) == ".go" || !c.assetExists(ref) {
+			continue // part of another path, or of a URL
+		}
+		buf.WriteString(c.identsMD(escape(s[last:m[0]], c.dialect.prose)))
+		link := "[" + escape(ref, c.dialect.prose) + "]"
+		if ext := pathpkg.Ext(ref); imageExts[strings.ToLower(ext)] {
+			link = "![" + strings.TrimSuffix(pathpkg.Base(ref), ext) + "]"
+		}
+		buf.WriteString(link + "(" + c.assetLink(ref) + ")")
+		last = m[1]
+	}
+	buf.WriteString(c.identsMD(escape(s[last:], c.dialect.prose)))
+	return buf.String()
+}
+
+// assetExists reports whether ref is the relative path of a regular file
+// of the directory of the package being converted.
+func (c *Converter) assetExists(ref string) bool {
+	ref = pathpkg.Clean(ref)
+	if ref == ".." || strings.HasPrefix(ref, "../") {
+		return false
+	}
+	fi, err := c.fs.Stat(pathpkg.Join(c.pdocDir, ref))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// copyAssets copies the recorded assets from the package directory dir
+// of the file system into outDir, the directory of the output file, or
+// into the attachments directory of the wiki root set with WithWikiRoot,
+// outDir by default, for the dialects of wikis. Nothing is copied when
+// outDir is empty, as when writing to stdout the output is expected to
+// live in the package directory.
+func (c *Converter) copyAssets(dir, outDir string) error {
+	if outDir == "" {
+		return nil
+	}
+	for _, ref := range c.assetRefs {
+		data, err := vfs.ReadFile(c.fs, pathpkg.Join(dir, ref))
+		if err != nil {
+			return fmt.Errorf("reading asset %s: %v", ref, err)
+		}
+		dst := filepath.Join(outDir, filepath.FromSlash(ref))
+		if c.dialect.attachments != "" {
+			root := c.wikiRoot
+			if root == "" {
+				root = outDir
+			}
+			dst = filepath.Join(root, filepath.FromSlash(c.dialect.attachments), c.attachmentName(ref))
+		}
+		c.recordOutput(dst)
+		if existing, err := ioutil.ReadFile(dst); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(dst, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package godoc2md
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/godoc"
+)
+
+// badgeTemplates are the built-in badges of WithBadges, by name. The
+// placeholders {import}, {module}, {go} and {license} are replaced with
+// the import path of the package, its module path, the go directive of
+// its go.mod and the identifier of its license; the dashes of the last
+// two are doubled, as shields.io separates the label and the value of
+// badges with dashes.
+var badgeTemplates = map[string]string{
+	"reference":    "[![Go Reference](https://pkg.go.dev/badge/{import}.svg)](https://pkg.go.dev/{import})",
+	"goreportcard": "[![Go Report Card](https://goreportcard.com/badge/{module})](https://goreportcard.com/report/{module})",
+	"license":      "[![License](https://img.shields.io/badge/license-{license}-blue.svg)](https://pkg.go.dev/{import}?tab=licenses)",
+	"goversion":    "![Go version](https://img.shields.io/badge/go-{go}-00ADD8.svg)",
+}
+
+// badgePlaceholderRx matches the placeholders of badge templates.
+var badgePlaceholderRx = regexp.MustCompile(`\{(import|module|go|license)\}`)
+
+// packageModule returns the module of the package importPath, or nil if
+// it isn't part of a module.
+func (c *Converter) packageModule(importPath string) *packages.Module {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedModule}
+	pattern := importPath
+	if dir, ok := c.pkgDirs[importPath]; ok {
+		cfg.Dir, pattern = dir, "."
+	}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil || len(pkgs) == 0 {
+		if c.verbose {
+			log.Printf("loading the module of %s: %v", importPath, err)
+		}
+		return nil
+	}
+	return pkgs[0].Module
+}
+
+// badgesMdFunc returns the badges set with WithBadges and the module
+// metadata enabled with WithMetadata, followed by a blank line. The
+// badges whose placeholders have no value, such as {license} without a
+// license file, are left out.
+func (c *Converter) badgesMdFunc(info *godoc.PageInfo) string {
+	if (len(c.badges) == 0 && !c.metadata) || info.PDoc == nil {
+		return ""
+	}
+	importPath := info.PDoc.ImportPath
+	values := map[string]string{"import": importPath}
+	var licenseFile string
+	if mod := c.packageModule(importPath); mod != nil {
+		values["module"], values["go"] = mod.Path, mod.GoVersion
+		var license string
+		if licenseFile, license = findLicense(mod.Dir); licenseFile != "" {
+			if dir, ok := c.pkgDirs[importPath]; ok {
+				if rel, err := filepath.Rel(dir, filepath.Join(mod.Dir, licenseFile)); err == nil {
+					licenseFile = filepath.ToSlash(rel)
+				}
+			}
+		}
+		if !strings.HasPrefix(license, "see ") {
+			values["license"] = license
+		}
+	}
+
+	var badges []string
+	for _, badge := range c.badges {
+		if t, ok := badgeTemplates[badge]; ok {
+			badge = t
+		}
+		missing := false
+		badge = badgePlaceholderRx.ReplaceAllStringFunc(badge, func(p string) string {
+			name := p[1 : len(p)-1]
+			v := values[name]
+			missing = missing || v == ""
+			if name == "go" || name == "license" {
+				v = strings.Replace(v, "-", "--", -1)
+			}
+			return v
+		})
+		if !missing {
+			badges = append(badges, badge)
+		}
+	}
+	var buf strings.Builder
+	if len(badges) > 0 {
+		buf.WriteString(strings.Join(badges, "\n") + "\n\n")
+	}
+	if c.metadata && values["module"] != "" {
+		fmt.Fprintf(&buf, "* Module: `%s`\n", values["module"])
+		if v := values["go"]; v != "" {
+			fmt.Fprintf(&buf, "* Go version: %s\n", v)
+		}
+		if licenseFile != "" {
+			license := values["license"]
+			if license == "" {
+				license = "see " + filepath.Base(licenseFile)
+			}
+			fmt.Fprintf(&buf, "* License: [%s](%s)\n", license, licenseFile)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,91 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// knownOS and knownArch are the values of GOOS and GOARCH that constrain
+// the files whose names end with them, as listed by go/build.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// nameConstraint returns the constraint implied by the name of the Go
+// file name, such as "linux && amd64" for foo_linux_amd64.go, or the
+// empty string.
+func nameConstraint(name string) string {
+	name = strings.TrimSuffix(strings.TrimSuffix(path.Base(name), ".go"), "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return ""
+	}
+	n := len(parts)
+	if n >= 3 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2] + " && " + parts[n-1]
+	}
+	if knownOS[parts[n-1]] || knownArch[parts[n-1]] {
+		return parts[n-1]
+	}
+	return ""
+}
+
+// fileConstraint returns the build constraint of the Go file name with
+// the content src: its //go:build line, combined with the constraint of
+// its name, or the empty string.
+func fileConstraint(name string, src []byte) string {
+	var expr string
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "//go:build ") {
+			expr = strings.TrimSpace(strings.TrimPrefix(line, "//go:build "))
+			break
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	implied := nameConstraint(name)
+	switch {
+	case expr == "":
+		return implied
+	case implied == "":
+		return expr
+	case strings.Contains(expr, "||"):
+		expr = "(" + expr + ")"
+	}
+	return expr + " && " + implied
+}
+
+// buildMdFunc returns a note telling the build constraint of the file
+// declaring decl, when enabled, followed by a blank line, or the empty
+// string if the file has none.
+func (c *Converter) buildMdFunc(info *godoc.PageInfo, decl ast.Node) string {
+	if !c.buildConstraints || decl == nil {
+		return ""
+	}
+	name := info.FSet.Position(decl.Pos()).Filename
+	f := c.srcFile(name)
+	if f == nil {
+		return ""
+	}
+	if constraint := fileConstraint(name, f.data); constraint != "" {
+		return "> Build constraint: `" + constraint + "`\n\n"
+	}
+	return ""
+}
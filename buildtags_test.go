@@ -0,0 +1,24 @@
+package godoc2md
+
+import "testing"
+
+func TestFileConstraint(t *testing.T) {
+	testData := []struct {
+		name, src string
+		expected  string
+	}{
+		{"foo.go", "package foo\n", ""},
+		{"linux.go", "package foo\n", ""},
+		{"foo_linux.go", "package foo\n", "linux"},
+		{"foo_linux_amd64_test.go", "package foo\n", "linux && amd64"},
+		{"foo_arm64.go", "package foo\n", "arm64"},
+		{"foo_bar.go", "//go:build cgo\n\npackage foo\n", "cgo"},
+		{"foo_windows.go", "// Copyright\n\n//go:build go1.21 || purego\n\npackage foo\n", "(go1.21 || purego) && windows"},
+		{"foo.go", "package foo\n\n//go:build ignore\n", ""},
+	}
+	for _, tt := range testData {
+		if got := fileConstraint(tt.name, []byte(tt.src)); got != tt.expected {
+			t.Errorf("fileConstraint(%s): expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
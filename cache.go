@@ -0,0 +1,172 @@
+package godoc2md
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// readCache reads the cache file name, which maps import paths to the
+// hash of their sources, or to their repository for the vanity cache,
+// written by writeCache. A missing or corrupted cache is empty.
+func readCache(name string) map[string]string {
+	cache := make(map[string]string)
+	f, err := os.Open(name)
+	if err != nil {
+		return cache
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) == 2 {
+			cache[fields[1]] = fields[0]
+		}
+	}
+	return cache
+}
+
+// writeCache writes the cache file name, one "hash importpath" line per
+// package.
+func writeCache(name string, cache map[string]string) error {
+	paths := make([]string, 0, len(cache))
+	for path := range cache {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var buf strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "%s %s\n", cache[path], path)
+	}
+	return ioutil.WriteFile(name, []byte(buf.String()), 0644)
+}
+
+// sourceHash returns the hash of the Go files of the package directory
+// dir, including its tests for the examples, of the header file, of the
+// template and its partials and of the settings of the cache, or the
+// empty string if they can't be read. The other files the page reads are
+// hashed too when they exist: the overview files, the assets referenced
+// from the sources with WithEmbedAssets, the go.mod and license of the
+// module for the badges and metadata, and the package comments of the
+// child directories for their synopses.
+func (c *Converter) sourceHash(dir string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\n%q\n", c.cacheSettings, c.templateText)
+	partials := make([]string, 0, len(c.templatePartials))
+	for name := range c.templatePartials {
+		partials = append(partials, name)
+	}
+	sort.Strings(partials)
+	for _, name := range partials {
+		fmt.Fprintf(h, "%s %q\n", name, c.templatePartials[name])
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return ""
+	}
+	if c.headerFile != "" {
+		files = append(files, c.headerFile)
+	}
+	var others []string
+	for _, name := range overviewFiles {
+		others = append(others, filepath.Join(dir, name))
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(h, "%s %d\n", filepath.Base(file), len(src))
+		h.Write(src)
+		if c.embedAssets {
+			for _, ref := range assetRefRx.FindAllString(string(src), -1) {
+				if ref = pathpkg.Clean(ref); pathpkg.Ext(ref) != ".go" && ref != ".." && !strings.HasPrefix(ref, "../") {
+					others = append(others, filepath.Join(dir, filepath.FromSlash(ref)))
+				}
+			}
+		}
+	}
+	if len(c.badges) > 0 || c.metadata {
+		if mod := moduleDir(dir); mod != "" {
+			others = append(others, filepath.Join(mod, "go.mod"))
+			if license, _ := findLicense(mod); license != "" {
+				others = append(others, filepath.Join(mod, license))
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, file := range others {
+		src, err := ioutil.ReadFile(file)
+		if err != nil || seen[file] {
+			continue
+		}
+		seen[file] = true
+		rel, _ := filepath.Rel(dir, file)
+		fmt.Fprintf(h, "%s %d\n", filepath.ToSlash(rel), len(src))
+		h.Write(src)
+	}
+	h.Write([]byte(subdirDocs(dir)))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// moduleDir returns the closest directory holding a go.mod file among
+// dir and its parents, or the empty string if there's none.
+func moduleDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// subdirDocs returns the package comments of the Go files of the child
+// directories of dir, from which the synopses of the Subdirectories
+// section are taken.
+func subdirDocs(dir string) string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	notTest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	var buf strings.Builder
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "testdata" || strings.HasPrefix(e.Name(), ".") || strings.HasPrefix(e.Name(), "_") {
+			continue
+		}
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, filepath.Join(dir, e.Name()), notTest, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		var names []string
+		for name, pkg := range pkgs {
+			for file, f := range pkg.Files {
+				if f.Doc != nil {
+					names = append(names, fmt.Sprintf("%s %s %q", name, filepath.Base(file), f.Doc.Text()))
+				}
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&buf, "%s %q\n", e.Name(), names)
+	}
+	return buf.String()
+}
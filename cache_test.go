@@ -0,0 +1,74 @@
+package godoc2md
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceHash(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash := func(c *Converter) string {
+		h := c.sourceHash(dir)
+		if h == "" {
+			t.Fatal("sourceHash: empty hash")
+		}
+		return h
+	}
+	base := hash(&Converter{templateText: "{{.}}"})
+	if h := hash(&Converter{templateText: "{{.}}"}); h != base {
+		t.Errorf("sourceHash: expected the same hash for the same template, got %s and %s", base, h)
+	}
+	for _, c := range []*Converter{
+		{templateText: "{{.}}\n"},
+		{templateText: "{{.}}", templatePartials: map[string]string{"a.tmpl": "a"}},
+		{templateText: "{{.}}", cacheSettings: "-layout pkgsite"},
+	} {
+		if h := hash(c); h == base {
+			t.Errorf("sourceHash(%+v): expected another hash than the default template", c)
+		}
+	}
+	partials := hash(&Converter{templatePartials: map[string]string{"a.tmpl": "a"}})
+	if h := hash(&Converter{templatePartials: map[string]string{"a.tmpl": "b"}}); h == partials {
+		t.Error("sourceHash: expected another hash after a change of a partial")
+	}
+
+	// the other inputs of the page, written and edited in turn
+	c := &Converter{embedAssets: true, metadata: true}
+	for _, tt := range []struct{ name, text string }{
+		{"OVERVIEW.md", "# Overview\n"},
+		{"OVERVIEW.md", "# Overview\n\nMore.\n"},
+		{"doc.md", "Doc.\n"},
+		{"a.go", "// Package a, see doc/arch.png.\npackage a\n"},
+		{"doc/arch.png", "png"},
+		{"doc/arch.png", "png2"},
+		{"sub/sub.go", "// Package sub.\npackage sub\n"},
+		{"sub/sub.go", "// Package sub is below.\npackage sub\n"},
+		{"go.mod", "module example.com/a\n"},
+		{"go.mod", "module example.com/a\n\ngo 1.21\n"},
+		{"LICENSE", "MIT License\n"},
+	} {
+		before := hash(c)
+		name := filepath.Join(dir, filepath.FromSlash(tt.name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(tt.text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if h := hash(c); h == before {
+			t.Errorf("sourceHash: expected another hash after writing %s", tt.name)
+		}
+	}
+	before := hash(c)
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "sub_test.go"), []byte("// Package sub is tested.\npackage sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if h := hash(c); h != before {
+		t.Error("sourceHash: expected the same hash after writing a test of a child directory")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/davecheney/godoc2md"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfig is the configuration file of a repository, read from the
+// current directory unless another one is given with -config.
+const defaultConfig = ".godoc2md.yaml"
+
+// applyConfig sets the flags set in the YAML configuration file name that
+// weren't set on the command line, by their name, such as
+//
+//	layout: pkgsite
+//	dialect: gfm
+//	srclink: "{{.File}}#L{{.Line}}"
+//	exclude: [internal/..., examples/...]
+//	heading-levels: {symbol: 3}
+//
+// where lists are comma-separated and maps are lists of key=value. The
+// packages key isn't a flag: it overrides the title, the description and
+// the tags of packages, by import path, such as
+//
+//	packages:
+//	  example.com/api/client:
+//	    title: API client
+//	    tags: [http, client]
+//
+// Nor is the presets key: it adds presets to those of -preset, by name,
+// replacing the built-in ones of the same name, such as
+//
+//	presets:
+//	  team:
+//	    layout: pkgsite
+//	    badges: [reference, license]
+//
+// The configuration file is ignored if it's missing, unless required. It
+// returns the flags set, as command line arguments, and the overrides of
+// the packages.
+func applyConfig(name string, required bool) ([]string, map[string]godoc2md.PackageMeta, error) {
+	data, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) && !required {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", name, err)
+	}
+	var meta struct {
+		Packages map[string]godoc2md.PackageMeta
+		Presets  map[string]map[string]interface{}
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", name, err)
+	}
+	delete(config, "packages")
+	delete(config, "presets")
+	for preset, flags := range meta.Presets {
+		values := make(map[string]string, len(flags))
+		for key, v := range flags {
+			if key == "config" || key == "preset" || flag.Lookup(key) == nil {
+				return nil, nil, fmt.Errorf("%s: presets: %s: unknown flag %q", name, preset, key)
+			}
+			values[key] = configValue(v)
+		}
+		presets[preset] = values
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, key := range keys {
+		if key == "config" || flag.Lookup(key) == nil {
+			return nil, nil, fmt.Errorf("%s: unknown flag %q", name, key)
+		}
+		if set[key] {
+			continue
+		}
+		value := configValue(config[key])
+		if err := flag.Set(key, value); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s: %v", name, key, err)
+		}
+		args = append(args, "-"+key+"="+value)
+	}
+	return args, meta.Packages, nil
+}
+
+// configValue returns the value of a flag set to v in the configuration
+// file: lists are comma-separated, and maps are lists of key=value.
+func configValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, e := range v {
+			values[i] = configValue(e)
+		}
+		return strings.Join(values, ",")
+	case map[string]interface{}:
+		values := make([]string, 0, len(v))
+		for key, e := range v {
+			values = append(values, key+"="+configValue(e))
+		}
+		sort.Strings(values)
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApplyConfigPresets(t *testing.T) {
+	testData := []struct {
+		config   string
+		expected map[string]string
+		err      bool
+	}{
+		{"presets:\n  team:\n    layout: pkgsite\n    badges: [reference, license]\n", map[string]string{"layout": "pkgsite", "badges": "reference,license"}, false},
+		{"presets:\n  team:\n    toc: true\n", map[string]string{"toc": "true"}, false},
+		{"presets:\n  team:\n    nosuchflag: x\n", nil, true},
+		{"presets:\n  team:\n    preset: readme\n", nil, true},
+	}
+	name := filepath.Join(t.TempDir(), defaultConfig)
+	for n, tt := range testData {
+		delete(presets, "team")
+		if err := ioutil.WriteFile(name, []byte(tt.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := applyConfig(name, true)
+		if (err != nil) != tt.err {
+			t.Errorf("%d: applyConfig: error %v, expected error %t", n, err, tt.err)
+			continue
+		}
+		if actual := presets["team"]; !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: presets[team] = %v, expected %v", n, actual, tt.expected)
+		}
+	}
+	delete(presets, "team")
+}
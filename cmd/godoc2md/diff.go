@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/davecheney/godoc2md"
+)
+
+// diffCmd is the subcommand listing the changes of the documentation of
+// the packages since a previous output of -format json, such as
+//
+//	godoc2md diff -format json -old api.json ./...
+const diffCmd = "diff"
+
+// diffArgs are the arguments of the diff subcommand.
+type diffArgs struct {
+	format  string
+	old     string
+	pattern string
+}
+
+// parseDiffArgs returns the arguments args of the diff subcommand.
+func parseDiffArgs(args []string) *diffArgs {
+	fs := flag.NewFlagSet(diffCmd, flag.ExitOnError)
+	format := fs.String("format", "text", "format of the changes: text, or json for an array of objects with the package, symbol, kind, change, and the declaration and doc before and after of each change")
+	old := fs.String("old", "", "previous documentation of the packages, written with -format json")
+	_ = fs.Parse(args)
+	if *old == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return &diffArgs{format: *format, old: *old, pattern: fs.Arg(0)}
+}
+
+// diffDocs writes the changes of the documentation of the packages of
+// the pattern of args since the previous documentation to stdout.
+func diffDocs(c *godoc2md.Converter, args *diffArgs) {
+	data, err := ioutil.ReadFile(args.old)
+	if err != nil {
+		log.Fatal(err)
+	}
+	old, err := godoc2md.ReadPackageDocs(data)
+	if err != nil {
+		log.Fatalf("%s: %v", args.old, err)
+	}
+	var docs []*godoc2md.PackageDoc
+	for _, path := range c.Packages(args.pattern) {
+		p, err := c.PackageDoc(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		docs = append(docs, p)
+	}
+	if err := godoc2md.WriteDocChanges(os.Stdout, godoc2md.DiffDocs(old, docs), args.format); err != nil {
+		log.Fatal(err)
+	}
+}
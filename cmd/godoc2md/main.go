@@ -0,0 +1,871 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// godoc2md converts godoc formatted package documentation into Markdown format.
+//
+//
+// Usage
+//
+//    godoc2md $PACKAGE > $GOPATH/src/$PACKAGE/README.md
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/davecheney/godoc2md"
+)
+
+var (
+	verbose = flag.Bool("v", false, "verbose mode")
+
+	// file system roots
+	// TODO(gri) consider the invariant that goroot always end in '/'
+	goroot = flag.String("goroot", runtime.GOROOT(), "Go root directory")
+
+	// layout control
+	tabWidth       = flag.Int("tabwidth", 4, "tab width")
+	codeLang       = flag.String("code-lang", "", "language of the code blocks of doc comments, such as go, for their syntax highlighting")
+	showTimestamps = flag.Bool("timestamps", false, "show timestamps with directory listings")
+	unexported     = flag.Bool("u", false, "include the unexported symbols, like go doc -u")
+	all            = flag.Bool("all", false, "same as -u")
+	altPkgTemplate = flag.String("template", "", "path to an alternate template file, or to a directory of *.tmpl templates: package.tmpl, including the others by file name, such as {{template \"header.tmpl\" .}}")
+	configFile     = flag.String("config", defaultConfig, "path to a YAML file setting the flags not given on the command line, by name, with lists for the comma-separated flags; ignored if missing, unless given")
+	exclude        = flag.String("exclude", "", "comma-separated list of package patterns left out, such as internal/... for the internal packages and those under them")
+	publishURL     = flag.String("publish", "", "upload the files written, with the images copied next to them, at their path relative to the current directory, or to their common directory if some are outside it, to this bucket, s3://bucket/prefix with the aws command or gs://bucket/prefix with gsutil, or commit them to the git branch of git:branch, such as git:gh-pages, to be pushed")
+	notionParent   = flag.String("notion", "", "publish the documentation of each package to a child page, titled with its import path, of this Notion page ID, with the token of the integration set in NOTION_TOKEN")
+	publishMessage = flag.String("publish-message", "Update documentation", "with -publish git:branch, message of the commit")
+	cname          = flag.String("cname", "", "with -publish git:branch, custom domain written to the CNAME file of the branch, for GitHub Pages")
+	cacheControl   = flag.String("cache-control", "", "with -publish, Cache-Control header of the uploaded files, such as max-age=3600")
+	combine        = flag.String("combine", "", "comma-separated list of package patterns documented with the package in a single document, with a shared table of contents, such as the library packages of a command")
+	excludeFiles   = flag.String("exclude-files", "", "comma-separated list of patterns of the names of the Go files whose declarations are left out, such as *_gen.go,zz_generated*")
+	excludeSymbols = flag.String("exclude-symbols", "", "regular expression matching the names of the symbols left out, and of the methods by name or by T.M, such as ^XXX_")
+	strictTmpl     = flag.Bool("strict-templates", false, "fail with the location of the error when the template uses missing map keys or prints <no value>, instead of writing the output")
+	verifyOutput   = flag.Bool("verify-output", false, "parse the Markdown output as GFM and fail with the lines of its links to missing anchors, duplicate anchors, links without destination and unclosed code blocks, instead of writing it")
+	preset         = flag.String("preset", "", "set the flags of a documentation target: readme, website (front matter, pkgsite layout, table of contents and package links) or wiki (pkgsite layout, table of contents and one file per type); flags given explicitly take precedence")
+	dialect        = flag.String("dialect", "gfm", "flavor of Markdown of the output: gfm, commonmark, mdx, bitbucket, gitlab, azure, azure-wiki (Azure DevOps project wikis) or plain (no tables, HTML or fenced code blocks)")
+	wikiRoot       = flag.String("wiki-root", "", "with -dialect azure-wiki, root directory of the wiki, whose .attachments directory the images of doc comments are copied to; the directory of the output by default")
+	layout         = flag.String("layout", "flat", "built-in template: flat, pkgsite (functions and types in their own sections) or summary (a table of the exported symbols)")
+	showPlayground = flag.Bool("play", false, "enable playground in web interface")
+	showExamples   = flag.Bool("ex", false, "show examples in command line mode")
+	typeDiagram    = flag.String("type-diagram", "", "add a Mermaid diagram of the types, the interfaces they implement and the types they embed to the index: class or graph")
+	implementsRefs = flag.Bool("implements", false, "list the types implementing each interface and the interfaces implemented by each type, within the package, by type-checking it")
+	schemas        = flag.Bool("schemas", false, "add to each struct type a table of its fields with their JSON name, type, required-ness from validate tags and description, for packages of DTOs")
+	dataModel      = flag.Bool("data-model", false, "add a Data model section mapping the structs with gorm, sqlx or bun tags to their tables and columns")
+	localLinks     = flag.String("local-links", "", "link the declarations and files of the package to its sources on disk instead of its code host: relative, for links relative to the output such as ./client.go#L42, or file, for file:// URLs")
+	grpcServices   = flag.Bool("grpc-services", false, "summarize the gRPC services generated by protoc-gen-go-grpc in a table of their methods, request and response types and streaming")
+	embedAssets    = flag.Bool("embed-assets", false, "link the files under the package directory referenced from doc comments by their relative paths, such as doc/arch.png, as images for pictures, and copy them next to the output")
+	routes         = flag.Bool("routes", false, "add a Routes section listing the HTTP routes registered with net/http, chi, gin or echo, with their methods and handlers")
+	observability  = flag.Bool("observability", false, "add an Observability section listing the Prometheus metrics and OpenTelemetry instruments and tracers registered, with their types, labels and declaring symbols")
+	apiDiff        = flag.String("diff", "", "write the API changes of the packages since this previous output instead of converting them: a -format json output, or a Markdown output of a single package, for release notes")
+	apiDiffVersion = flag.String("diff-version", "", "with -diff, version of the release shown with the API changes, git describe --tags by default")
+	configKeys     = flag.Bool("config-keys", false, "add a Configuration section listing the flags defined with flag, pflag or cobra and the keys read with viper or koanf, with their types, defaults and descriptions")
+	benchmarks     = flag.Bool("benchmarks", false, "add a Benchmarks section listing the Benchmark functions of the tests with their doc comments")
+	benchstatTable = flag.Bool("benchstat-table", false, "with -benchmarks, add a table of the benchmarks to paste benchstat results into")
+	tests          = flag.Bool("tests", false, "add a Tests section listing the Test functions of the tests with their doc comments")
+	exampleLevel   = flag.Int("exlevel", 5, "heading level of examples")
+	headingOffset  = flag.Int("heading-offset", 0, "shift the level of all the headings, such as 2 for the title to be a ### heading when embedding the output in a larger document")
+	headingLevels  = flag.String("heading-levels", "", "comma-separated list of role=level setting the heading levels of the flat layout, where role is one of title, section, symbol, member, subsection or comment")
+	exampleTitleF  = flag.String("extitle", "Example [{{.Name}}{{.Suffix}}]({{.Link}}):", "template of example titles, with fields Name, Suffix, RawSuffix and Link")
+	exampleTabs    = flag.String("tabs", "", "render multiple examples of a symbol as tabs for the given renderer: docusaurus or mkdocs")
+	declLinks      = linksVar("links", "true", "link identifiers to their declarations: true, false, or -links=local to also link the types of the package used in declarations to their headings in the document, and the identifiers of other packages to their documentation")
+	htmlAnchors    = flag.Bool("htmlanchors", false, "emit explicit <a id> anchors before headings, for renderers that don't generate heading IDs")
+	lineBreaks     = flag.String("linebreaks", "", "preserve the line breaks of doc comment paragraphs, ending lines with: spaces or br")
+	backticks      = flag.Bool("backticks", false, "wrap the exported identifiers of the package mentioned in doc comments in backticks")
+	lead           = flag.String("lead", "", "style the first sentence of doc comments: bold or italic")
+	pkgLinks       = flag.Bool("xlinks", false, "link the identifiers of other packages mentioned in doc comments and declarations to their documentation")
+	pkgLinkBase    = flag.String("xlinkbase", "https://pkg.go.dev", "base URL of the documentation of other packages, for -xlinks and doc links")
+	groupOpts      = flag.Bool("group-options", false, "group functional options (With... constructors of an Option type) under the type they configure")
+	foldAccess     = flag.Bool("fold-accessors", false, "collapse trivially documented getter and setter methods into a table under their type")
+	permalinks     = flag.Bool("permalinks", false, "emit a row of source, pkg.go.dev and permalink links under each symbol heading")
+	pkgsiteLinks   = flag.Bool("pkgsite-links", false, "append a small pkg.go.dev link to the heading of each exported symbol, pointing at its documentation there")
+	toc            = flag.Bool("toc", false, "extend the links at the top of the output into a table of contents of the symbols and examples")
+	constValues    = flag.Bool("const-values", false, "annotate the constant declarations using iota with a table of their values")
+	constGroups    = flag.Bool("const-groups", false, "put the constants of each type under their own Constants heading in the section of the type")
+	deprecated     = flag.Bool("deprecated", false, "render the Deprecated: paragraphs of doc comments as a caution admonition")
+	deprecatedSect = flag.Bool("deprecated-section", false, "list the deprecated symbols of the packages in a Deprecated APIs section")
+	buildTags      = flag.Bool("build-constraints", false, "note the build constraint of the file declaring each symbol, from its //go:build line and file name suffix")
+	hazards        = flag.Bool("hazards", false, "flag the functions and methods implemented with package unsafe, unsafe reflect features or //go:linkname with a warning")
+	format         = flag.String("format", "markdown", "output format: markdown, json for the documentation model of each package, with its declarations, positions and source links, or asciidoc or rst for AsciiDoc or reStructuredText written from it")
+	lint           = flag.Bool("lint", false, "check the documentation of the packages instead of converting it, and exit with status 1 on findings")
+	textFilter     = flag.String("text-filter", "", "with -lint, shell command checking the prose of the doc comments read from its stdin, such as aspell list or vale --output=line --ext=.md, whose findings are reported at the position of the comments")
+	exCoverage     = flag.Bool("excoverage", false, "report which exported symbols of the packages have examples instead of converting them")
+	minExCoverage  = flag.Float64("min-example-coverage", 0, "with -excoverage, exit with status 1 if the percentage of symbols with examples of a package is lower")
+	watch          = flag.Bool("watch", false, "regenerate the output whenever the Go files of the packages or the header file change, until interrupted")
+	previewAddr    = flag.String("http", "", "with -watch, serve the output rendered as HTML on this address, such as localhost:6060, reloading the page on changes")
+	symbolsFile    = flag.String("symbols", "", "write the output file and anchor of the documentation of each symbol to this JSON file, such as symbols.json, for editor plugins")
+	outFile        = flag.String("o", "", "output file path. Writes to stdout if unspecified or equal to -")
+	headerFile     = flag.String("header", "", "path to a Markdown file prepended to the generated documentation")
+	badges         = flag.String("badges", "", "comma-separated list of badges under the title: reference, goreportcard, license, goversion, or Markdown templates with {import}, {module}, {go} and {license} placeholders")
+	metadata       = flag.Bool("metadata", false, "list the module path, Go version and license of the module under the title")
+	safetyBadges   = flag.Bool("concurrency-badges", false, "show a badge under the types whose doc comment states whether they're safe for concurrent use")
+	frontMatter    = flag.String("frontmatter", "", "prepend front matter in the given format, yaml or toml, for Hugo or Docusaurus")
+	frontMatterF   = flag.String("frontmatter-fields", "", "comma-separated list of key=value front matter fields, such as weight=10, overriding the title, slug and description derived from the package")
+	migrateFile    = flag.String("migrate", "", "path to an existing hand-written README: its non-generated sections are saved to the -header file (README.header.md by default) before generating")
+	splitTypes     = flag.Bool("split-types", false, "write each exported type into its own file next to the -o file, which links to them")
+	targetsFlag    = flag.String("targets", "", "comma-separated list of path=layout writing the documentation to several files in one run, such as README.md=summary,docs/README.md=flat")
+	module         = flag.Bool("module", false, "write the landing page of the module rooted at the given directory: overview, install instructions, packages and metadata")
+	whatsNew       = flag.Bool("whatsnew", false, "list the symbols added since the previous output, given with -o or -r, in a What's new section")
+	whatsNewSince  = flag.String("since", "", "with -whatsnew and -o, git revision of the previous output, instead of the current -o file")
+	commentMode    = flag.Bool("comment", false, "convert the doc comment read from stdin, with or without its comment markers, instead of a package")
+	recursive      = flag.Bool("r", false, "document every package under the given root, each in its own file")
+	reportFormat   = flag.String("report-format", "text", "format of the -report summary: text, json for a line of JSON per file listing the symbol, kind, change and documentation before and after of each change, or digest for a compact Markdown summary to post to Slack or Discord, also used by -excoverage")
+	webhookURL     = flag.String("webhook", "", "with -report-format digest, post the digest of -report or -excoverage to this Slack or Discord incoming webhook URL")
+	reportFile     = flag.String("report", "", "write a summary of the symbols whose documentation changed since the previous output, given with -o or -r, to this file, or stderr if -")
+	redirectsFile  = flag.String("redirects", "", "record the anchors of the symbols renamed since the previous output, given with -o or -r, in this JSON map of file#anchor to file#anchor, for static site generators")
+	cacheFile      = flag.String("cache", "", "in recursive mode, path to a cache of the hashes of the package sources, to only regenerate the packages whose Go files changed")
+	jobs           = flag.Int("jobs", 1, "in recursive mode, number of packages rendered at a time")
+	check          = flag.Bool("check", false, "write nothing, and exit with status 1 if the output given with -o or -r is out of date")
+	outPathFormat  = flag.String("outpath", "{{.Dir}}/README.md", "template of the output file path of each package in recursive mode, with fields ImportPath, Dir and RelPath")
+
+	// The hash format for Github is the default `#L%d`; but other source control platforms do not
+	// use the same format. For example Bitbucket Enterprise uses `#%d`. This option provides the
+	// user the option to switch the format as needed and still remain backwards compatible.
+	srcLinkHashFormat = flag.String("hashformat", "#L%d", "source link URL hash format, passed the first and the last line of the declaration if it has two verbs, such as #L%d-L%d")
+	selections        = flag.Bool("selections", false, "link the declarations to the selection of their source, on the code hosts with column anchors such as GitHub and Azure DevOps")
+	lineRanges        = flag.Bool("line-ranges", false, "link the declarations spanning several lines to the range of their lines, with the range anchors of their code host")
+	srcLinkFormat     = flag.String("srclink", "", "if set, format of source links, appended to the URL of the package directory: a Go template with the fields .ImportPath, .File, .Path, .Line, .EndLine, .Low, .High, .Ref, .Branch and .Commit, or a printf format passed the file name, line, and start and end offsets")
+	branch            = flag.String("branch", "master", "branch that source links point at, or HEAD for the current branch of the git repository")
+	stdlibLinks       = flag.String("stdlib-links", "golang.org", "site of the source links of the standard library packages: golang.org, go.dev, or cs for cs.opensource.google at -branch or -commit")
+	commit            = flag.String("commit", "", "if set, commit that source links point at instead of -branch, or HEAD for the current commit of the git repository")
+	vanity            = flag.Bool("vanity", false, "resolve the vanity import paths, such as go.uber.org/zap, into their repository with their go-import meta tags for source links, falling back to the repositories of well-known vanity import paths offline")
+	vanityCache       = flag.String("vanity-cache", "", "with -vanity, path to a cache of the repositories of the vanity import paths, to resolve them once")
+	srcHostFlag       = flag.String("srchost", "", "comma-separated list of host=template linking import paths host/owner/repo/dir to their sources, where template has the placeholders {host}, {owner}, {repo}, {ref} and {dir}, or is one of github, bitbucket, gitlab, gitea, sourcehut or azure, optionally followed by | and the template of raw contents for -raw-links")
+	rawLinks          = flag.Bool("raw-links", false, "link images and package files to their raw contents on the code host, such as raw.githubusercontent.com, instead of copying images next to the output")
+
+	// notes control
+	notesRx       = flag.String("notes", "BUG", "regular expression matching note markers to show")
+	noteStyleFlag = flag.String("notestyle", "", "comma-separated list of MARKER=style, where style is one of list, note, tip, important, warning or caution")
+	noteTitleFlag = flag.String("notetitle", "", "comma-separated list of MARKER=title overriding the section title of notes")
+)
+
+// A linksFlag is the value of -links: true, false or local. It's a
+// boolean flag, so that -links alone is -links=true.
+type linksFlag string
+
+// linksVar defines the -links flag name.
+func linksVar(name, value, usage string) *linksFlag {
+	f := linksFlag(value)
+	flag.Var(&f, name, usage)
+	return &f
+}
+
+func (f *linksFlag) String() string { return string(*f) }
+
+func (f *linksFlag) Set(s string) error {
+	if s == "local" {
+		*f = linksFlag(s)
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.New("must be true, false or local")
+	}
+	*f = linksFlag(strconv.FormatBool(b))
+	return nil
+}
+
+func (f *linksFlag) IsBoolFlag() bool { return true }
+
+func usage() {
+	fmt.Fprintf(os.Stderr,
+		"usage: godoc2md package[@version] [name ...]\n       godoc2md render-snippet -f template < input\n       godoc2md diff [-format json] -old file package\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+// readTemplates reads the package template of the file path, or of the
+// file package.tmpl of the directory path, along with the other *.tmpl
+// files of the directory, by file name, as the partials it includes.
+func readTemplates(path string) (string, map[string]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !fi.IsDir() {
+		buf, err := ioutil.ReadFile(path)
+		return string(buf), nil, err
+	}
+	names, err := filepath.Glob(filepath.Join(path, "*.tmpl"))
+	if err != nil {
+		return "", nil, err
+	}
+	var text string
+	partials := make(map[string]string)
+	for _, name := range names {
+		buf, err := ioutil.ReadFile(name)
+		if err != nil {
+			return "", nil, err
+		}
+		if filepath.Base(name) == "package.tmpl" {
+			text = string(buf)
+		} else {
+			partials[filepath.Base(name)] = string(buf)
+		}
+	}
+	if text == "" {
+		return "", nil, fmt.Errorf("%s: no package.tmpl template", path)
+	}
+	return text, partials, nil
+}
+
+// parseKeyValues parses a comma-separated list of key=value pairs.
+func parseKeyValues(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	if s == "" {
+		return m, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid key=value pair %q", kv)
+		}
+		m[strings.TrimSpace(kv[:i])] = strings.TrimSpace(kv[i+1:])
+	}
+	return m, nil
+}
+
+// commentText returns the text of a doc comment, without its comment
+// markers if it has them.
+func commentText(src string) string {
+	trimmed := strings.TrimSpace(src)
+	if !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "/*") {
+		return src
+	}
+	var cg ast.CommentGroup
+	if strings.HasPrefix(trimmed, "/*") {
+		cg.List = []*ast.Comment{{Text: trimmed}}
+	} else {
+		for _, line := range strings.Split(trimmed, "\n") {
+			cg.List = append(cg.List, &ast.Comment{Text: strings.TrimSpace(line)})
+		}
+	}
+	return cg.Text()
+}
+
+// reportOutput reports the changes of the output file name since its
+// previous content to report, if any, and records the renamed symbols
+// with -redirects.
+func reportOutput(report io.Writer, reportChanges func(io.Writer, string, []byte, []byte) error, name string, previous []byte) error {
+	if report == nil && *redirectsFile == "" {
+		return nil
+	}
+	new, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		if err := reportChanges(report, name, previous, new); err != nil {
+			return err
+		}
+	}
+	if *redirectsFile != "" {
+		return godoc2md.UpdateRedirects(*redirectsFile, name, previous, new)
+	}
+	return nil
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	configSet := false
+	flag.Visit(func(f *flag.Flag) { configSet = configSet || f.Name == "config" })
+	configArgs, packageMeta, err := applyConfig(*configFile, configSet)
+	if err != nil {
+		log.Fatal("-config: ", err)
+	}
+	if len(packageMeta) > 0 {
+		// the overrides of the packages invalidate the cache too
+		configArgs = append(configArgs, fmt.Sprint(packageMeta))
+	}
+
+	if *commentMode {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = godoc2md.CommentToMarkdown(os.Stdout, commentText(string(src)),
+			godoc2md.WithLineBreaks(*lineBreaks),
+			godoc2md.WithLead(*lead),
+			godoc2md.WithHTMLAnchors(*htmlAnchors),
+			godoc2md.WithPackageLinkBase(*pkgLinkBase),
+			godoc2md.WithDialect(*dialect),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Check usage
+	if flag.NArg() == 0 {
+		usage()
+	}
+	filters := flag.Args()[1:]
+	var snippet string
+	var diff *diffArgs
+	switch flag.Arg(0) {
+	case renderSnippetCmd:
+		snippet, filters = parseSnippetArgs(filters), nil
+	case diffCmd:
+		diff, filters = parseDiffArgs(filters), nil
+	}
+
+	if *preset != "" {
+		if err := applyPreset(*preset); err != nil {
+			log.Fatal("-preset: ", err)
+		}
+	}
+
+	noteTitles, err := parseKeyValues(*noteTitleFlag)
+	if err != nil {
+		log.Fatal("-notetitle: ", err)
+	}
+	noteStyles, err := parseKeyValues(*noteStyleFlag)
+	if err != nil {
+		log.Fatal("-notestyle: ", err)
+	}
+
+	frontMatterFields, err := parseKeyValues(*frontMatterF)
+	if err != nil {
+		log.Fatal("-frontmatter-fields: ", err)
+	}
+
+	levels, err := parseKeyValues(*headingLevels)
+	if err != nil {
+		log.Fatal("-heading-levels: ", err)
+	}
+	headingLevelByRole := make(map[string]int)
+	for role, level := range levels {
+		if headingLevelByRole[role], err = strconv.Atoi(level); err != nil {
+			log.Fatalf("-heading-levels: invalid level %q of %s", level, role)
+		}
+	}
+
+	srcHosts, err := parseKeyValues(*srcHostFlag)
+	if err != nil {
+		log.Fatal("-srchost: ", err)
+	}
+	var hosts []godoc2md.SourceHost
+	for host, tree := range srcHosts {
+		tree, raw := tree, ""
+		if i := strings.Index(tree, "|"); i >= 0 {
+			tree, raw = tree[:i], tree[i+1:]
+		}
+		h := godoc2md.NewSourceHost(host, tree)
+		if raw != "" {
+			h.Raw = raw
+		}
+		hosts = append(hosts, h)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Pattern.String() < hosts[j].Pattern.String() })
+
+	ref := *branch
+	pinned := *commit != ""
+	if pinned {
+		ref = *commit
+	}
+	if ref == "HEAD" {
+		if ref, err = godoc2md.GitHead(".", pinned); err != nil {
+			log.Fatal(err)
+		}
+	}
+	refSet := false
+	flag.Visit(func(f *flag.Flag) { refSet = refSet || f.Name == "branch" || f.Name == "commit" })
+	if strings.Contains(flag.Arg(0), "@") && !refSet {
+		// a remote module: link to the sources of its version
+		if ref, err = godoc2md.ModuleRef(flag.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var tmpl string
+	var partials map[string]string
+	if *altPkgTemplate != "" {
+		var err error
+		if tmpl, partials, err = readTemplates(*altPkgTemplate); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *migrateFile != "" {
+		if *headerFile == "" {
+			*headerFile = godoc2md.DefaultHeaderFile(*migrateFile)
+		}
+		if err := godoc2md.MigrateReadme(*migrateFile, *headerFile); err != nil {
+			log.Fatal("-migrate: ", err)
+		}
+	}
+
+	if *recursive && *outFile != "" {
+		log.Fatal("-o can't be used with -r, use -outpath instead")
+	}
+	targets, err := parseKeyValues(*targetsFlag)
+	if err != nil {
+		log.Fatal("-targets: ", err)
+	}
+	if len(targets) > 0 && (*recursive || *outFile != "" || *splitTypes) {
+		log.Fatal("-targets can't be used with -o, -r or -split-types")
+	}
+	if *splitTypes && (*recursive || *outFile == "" || *outFile == "-") {
+		log.Fatal("-split-types requires -o and can't be used with -r")
+	}
+	if *watch && (*recursive || *splitTypes || *module || *check || *lint || *exCoverage || *format != "markdown" || len(targets) > 0) {
+		log.Fatal("-watch can't be used with -r, -split-types, -module, -check, -lint, -excoverage, -format json or -targets")
+	}
+	if *symbolsFile != "" && (*watch || *module || *check || *lint || *exCoverage || *format != "markdown") {
+		log.Fatal("-symbols can't be used with -watch, -module, -check, -lint, -excoverage or -format json")
+	}
+	if *publishURL != "" && !strings.HasPrefix(*publishURL, "s3://") && !strings.HasPrefix(*publishURL, "gs://") && !strings.HasPrefix(*publishURL, "git:") {
+		log.Fatal("-publish must be s3://bucket/prefix, gs://bucket/prefix or git:branch")
+	}
+	if *cname != "" && !strings.HasPrefix(*publishURL, "git:") {
+		log.Fatal("-cname requires -publish with a git branch")
+	}
+	if *notionParent != "" && (*outFile != "" || *splitTypes || *module || *watch || *check || *lint || *exCoverage || *format != "markdown" || len(targets) > 0 || *publishURL != "") {
+		log.Fatal("-notion can't be used with -o, -split-types, -module, -watch, -check, -lint, -excoverage, -format json, -targets or -publish")
+	}
+	if *apiDiff != "" && (*splitTypes || *module || *watch || *check || *lint || *exCoverage || *notionParent != "" || *format != "markdown" || len(targets) > 0 || *publishURL != "") {
+		log.Fatal("-diff can't be used with -split-types, -module, -watch, -check, -lint, -excoverage, -notion, -format json, -targets or -publish")
+	}
+	if *webhookURL != "" && (*reportFormat != "digest" || *reportFile == "" && !*exCoverage || *watch) {
+		log.Fatal("-webhook requires -report-format digest with -report or -excoverage, and can't be used with -watch")
+	}
+	if *benchstatTable && !*benchmarks {
+		log.Fatal("-benchstat-table requires -benchmarks")
+	}
+	if *previewAddr != "" && !*watch {
+		log.Fatal("-http requires -watch")
+	}
+	if *jobs < 1 || *jobs > 1 && !*recursive {
+		log.Fatal("-jobs must be at least 1, and requires -r")
+	}
+	if *publishURL != "" && (!*recursive && len(targets) == 0 && (*outFile == "" || *outFile == "-") || *watch || *check || *lint || *exCoverage || *format != "markdown") {
+		log.Fatal("-publish requires -o, -r or -targets, and can't be used with -watch, -check, -lint, -excoverage or -format json")
+	}
+	var badgeList []string
+	if *badges != "" {
+		badgeList = strings.Split(*badges, ",")
+	}
+
+	var report io.Writer
+	reportChanges := godoc2md.ReportChanges
+	switch *reportFormat {
+	case "json":
+		reportChanges = godoc2md.ReportChangesJSON
+	case "digest":
+		reportChanges = godoc2md.ReportChangesDigest
+	}
+	switch {
+	case *reportFile == "":
+	case !*recursive && (*outFile == "" || *outFile == "-"):
+		log.Fatal("-report requires -o or -r")
+	case *reportFile == "-":
+		report = os.Stderr
+	default:
+		f, err := os.Create(*reportFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		report = f
+	}
+	var digest bytes.Buffer
+	if *webhookURL != "" {
+		if report != nil {
+			report = io.MultiWriter(report, &digest)
+		}
+		defer postDigest(&digest)
+	}
+
+	if *redirectsFile != "" && !*recursive && (*outFile == "" || *outFile == "-" || *check || len(targets) > 0) {
+		log.Fatal("-redirects requires -o or -r, and can't be used with -check or -targets")
+	}
+
+	var assetDir string
+	if *outFile != "" && *outFile != "-" && !*check {
+		assetDir = filepath.Dir(*outFile)
+	}
+
+	var previous []byte
+	if *outFile != "" && *outFile != "-" {
+		previous, _ = ioutil.ReadFile(*outFile)
+	}
+	since := previous
+	if *whatsNewSince != "" {
+		if *outFile == "" || *outFile == "-" {
+			log.Fatal("-since requires -o")
+		}
+		if since, err = godoc2md.GitShow(*whatsNewSince, *outFile); err != nil {
+			log.Fatal("-since: ", err)
+		}
+	}
+
+	symbolsOutput := *outFile
+	if symbolsOutput == "-" {
+		symbolsOutput = ""
+	}
+
+	opts := []godoc2md.Option{
+		godoc2md.WithVerbose(*verbose),
+		godoc2md.WithGoroot(*goroot),
+		godoc2md.WithTabWidth(*tabWidth),
+		godoc2md.WithCodeLanguage(*codeLang),
+		godoc2md.WithTimestamps(*showTimestamps),
+		godoc2md.WithPlayground(*showPlayground),
+		godoc2md.WithUnexported(*unexported || *all),
+		godoc2md.WithTemplate(tmpl),
+		godoc2md.WithTemplatePartials(partials),
+		godoc2md.WithStrictTemplates(*strictTmpl),
+		godoc2md.WithVerifyOutput(*verifyOutput),
+		godoc2md.WithLayout(*layout),
+		godoc2md.WithDialect(*dialect),
+		godoc2md.WithWikiRoot(*wikiRoot),
+		godoc2md.WithExamples(*showExamples),
+		godoc2md.WithTypeDiagram(*typeDiagram),
+		godoc2md.WithImplementations(*implementsRefs),
+		godoc2md.WithSchemas(*schemas),
+		godoc2md.WithDataModel(*dataModel),
+		godoc2md.WithLocalLinks(*localLinks),
+		godoc2md.WithGRPCServices(*grpcServices),
+		godoc2md.WithEmbedAssets(*embedAssets),
+		godoc2md.WithRoutes(*routes),
+		godoc2md.WithObservability(*observability),
+		godoc2md.WithConfigKeys(*configKeys),
+		godoc2md.WithBenchmarks(*benchmarks),
+		godoc2md.WithBenchstatTable(*benchstatTable),
+		godoc2md.WithTests(*tests),
+		godoc2md.WithExampleLevel(*exampleLevel),
+		godoc2md.WithHeadingOffset(*headingOffset),
+		godoc2md.WithHeadingLevels(headingLevelByRole),
+		godoc2md.WithTextFilter(*textFilter),
+		godoc2md.WithExampleTitle(*exampleTitleF),
+		godoc2md.WithExampleTabs(*exampleTabs),
+		godoc2md.WithDeclLinks(*declLinks != "false"),
+		godoc2md.WithLocalDeclLinks(*declLinks == "local"),
+		godoc2md.WithHTMLAnchors(*htmlAnchors),
+		godoc2md.WithLineBreaks(*lineBreaks),
+		godoc2md.WithBackticks(*backticks),
+		godoc2md.WithLead(*lead),
+		godoc2md.WithPackageLinks(*pkgLinks),
+		godoc2md.WithPackageLinkBase(*pkgLinkBase),
+		godoc2md.WithGroupOptions(*groupOpts),
+		godoc2md.WithFoldAccessors(*foldAccess),
+		godoc2md.WithPermalinks(*permalinks),
+		godoc2md.WithPkgsiteLinks(*pkgsiteLinks),
+		godoc2md.WithTOC(*toc),
+		godoc2md.WithHazards(*hazards),
+		godoc2md.WithDeprecated(*deprecated),
+		godoc2md.WithDeprecatedSection(*deprecatedSect),
+		godoc2md.WithBuildConstraints(*buildTags),
+		godoc2md.WithConstValues(*constValues),
+		godoc2md.WithConstGroups(*constGroups),
+		godoc2md.WithHeaderFile(*headerFile),
+		godoc2md.WithFrontMatter(*frontMatter, frontMatterFields),
+		godoc2md.WithPackageMeta(packageMeta),
+		godoc2md.WithBadges(badgeList...),
+		godoc2md.WithMetadata(*metadata),
+		godoc2md.WithConcurrencyBadges(*safetyBadges),
+		godoc2md.WithAssetDir(assetDir),
+		godoc2md.WithReport(report),
+		godoc2md.WithReportFormat(*reportFormat),
+		godoc2md.WithRedirects(*redirectsFile),
+		godoc2md.WithCache(*cacheFile, strings.Join(append(os.Args[1:], configArgs...), " ")),
+		godoc2md.WithJobs(*jobs),
+		godoc2md.WithCheck(*check),
+		godoc2md.WithSymbols(*symbolsFile, symbolsOutput),
+		godoc2md.WithWhatsNew(*whatsNew),
+		godoc2md.WithPrevious(since),
+		godoc2md.WithOutPath(*outPathFormat),
+		godoc2md.WithHashFormat(*srcLinkHashFormat),
+		godoc2md.WithLineRanges(*lineRanges),
+		godoc2md.WithSelections(*selections),
+		godoc2md.WithSrcLinkFormat(*srcLinkFormat),
+		godoc2md.WithSourceHosts(hosts...),
+		godoc2md.WithVanity(*vanity, *vanityCache),
+		godoc2md.WithRef(ref),
+		godoc2md.WithStdlibLinks(*stdlibLinks),
+		godoc2md.WithRawLinks(*rawLinks),
+		godoc2md.WithNotes(*notesRx),
+		godoc2md.WithNoteStyles(noteStyles),
+		godoc2md.WithNoteTitles(noteTitles),
+		godoc2md.WithFilters(filters...),
+		godoc2md.WithExclude(strings.Split(*exclude, ",")...),
+		godoc2md.WithExcludeFiles(strings.Split(*excludeFiles, ",")...),
+		godoc2md.WithExcludeSymbols(*excludeSymbols),
+	}
+	if *watch {
+		opts = append(opts, godoc2md.WithWatchFiles(*altPkgTemplate))
+	}
+	c, err := godoc2md.New(opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if snippet != "" {
+		renderSnippet(c, snippet)
+		return
+	}
+
+	if diff != nil {
+		diffDocs(c, diff)
+		return
+	}
+
+	if *exCoverage {
+		pattern := flag.Arg(0)
+		if *recursive && !strings.HasSuffix(pattern, "/...") {
+			pattern = strings.TrimSuffix(pattern, "/") + "/..."
+		}
+		low := false
+		for _, path := range c.Packages(pattern) {
+			ec, err := c.ExampleCoverage(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			report := ec.Report
+			if *reportFormat == "digest" {
+				report = ec.ReportDigest
+			}
+			if err := report(io.MultiWriter(os.Stdout, &digest), path); err != nil {
+				log.Fatal(err)
+			}
+			low = low || ec.Percent() < *minExCoverage
+		}
+		if low {
+			postDigest(&digest)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *apiDiff != "" {
+		old, err := ioutil.ReadFile(*apiDiff)
+		if err != nil {
+			log.Fatal(err)
+		}
+		version := *apiDiffVersion
+		if version == "" {
+			version, _ = godoc2md.GitDescribe(".")
+		}
+		pattern := flag.Arg(0)
+		if *recursive && !strings.HasSuffix(pattern, "/...") {
+			pattern = strings.TrimSuffix(pattern, "/") + "/..."
+		}
+		of := os.Stdout
+		if *outFile != "" && *outFile != "-" {
+			if of, err = os.Create(*outFile); err != nil {
+				log.Fatal(err)
+			}
+			defer of.Close()
+		}
+		if err := c.ConvertAPIDiff(c.Packages(pattern), old, version, of); err != nil {
+			log.Fatal("-diff: ", err)
+		}
+		return
+	}
+
+	if *notionParent != "" {
+		pattern := flag.Arg(0)
+		if *recursive && !strings.HasSuffix(pattern, "/...") {
+			pattern = strings.TrimSuffix(pattern, "/") + "/..."
+		}
+		if err := publishNotion(c, c.Packages(pattern), *notionParent); err != nil {
+			log.Fatal("-notion: ", err)
+		}
+		return
+	}
+
+	switch *format {
+	case "markdown":
+	case "json", "asciidoc", "rst":
+		if *recursive || *splitTypes || *module || len(targets) > 0 {
+			log.Fatalf("-format %s can't be used with -r, -split-types, -module or -targets", *format)
+		}
+		of := os.Stdout
+		if *outFile != "" && *outFile != "-" {
+			if of, err = os.Create(*outFile); err != nil {
+				log.Fatal(err)
+			}
+			defer of.Close()
+		}
+		for _, path := range c.Packages(flag.Arg(0)) {
+			convert := c.ConvertJSON
+			if *format != "json" {
+				convert = func(path string, w io.Writer) error { return c.ConvertMarkup(path, *format, w) }
+			}
+			if err := convert(path, of); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	default:
+		log.Fatalf("-format: unknown format %q, want markdown, json, asciidoc or rst", *format)
+	}
+
+	if *lint {
+		pattern := flag.Arg(0)
+		if *recursive && !strings.HasSuffix(pattern, "/...") {
+			pattern = strings.TrimSuffix(pattern, "/") + "/..."
+		}
+		found := false
+		for _, path := range c.Packages(pattern) {
+			findings, err := c.Lint(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, f := range findings {
+				fmt.Printf("%s: %s\n", path, f)
+				found = true
+			}
+		}
+		if found {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *recursive {
+		if err := c.ConvertTree(flag.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+		if err := c.WriteSymbols(); err != nil {
+			log.Fatal(err)
+		}
+		publishOutputs(c, "")
+		return
+	}
+
+	if *module {
+		of := os.Stdout
+		if *outFile != "" && *outFile != "-" {
+			if of, err = os.Create(*outFile); err != nil {
+				log.Fatal(err)
+			}
+			defer of.Close()
+		}
+		if err := c.ConvertModule(flag.Arg(0), of); err != nil {
+			log.Fatal(err)
+		}
+		publishOutputs(c, *outFile)
+		return
+	}
+
+	if len(targets) > 0 {
+		paths := c.Packages(flag.Arg(0))
+		if len(paths) != 1 {
+			log.Fatal("-targets documents a single package")
+		}
+		var ts []godoc2md.Target
+		for path, layout := range targets {
+			ts = append(ts, godoc2md.Target{Layout: layout, Path: path})
+		}
+		sort.Slice(ts, func(i, j int) bool { return ts[i].Path < ts[j].Path })
+		if err := c.ConvertTargets(paths[0], ts...); err != nil {
+			log.Fatal(err)
+		}
+		if err := c.WriteSymbols(); err != nil {
+			log.Fatal(err)
+		}
+		publishOutputs(c, "")
+		return
+	}
+
+	if *splitTypes {
+		paths := c.Packages(flag.Arg(0))
+		if len(paths) != 1 {
+			log.Fatal("-split-types documents a single package")
+		}
+		if err := c.ConvertSplit(paths[0], *outFile); err != nil {
+			log.Fatal(err)
+		}
+		if err := c.WriteSymbols(); err != nil {
+			log.Fatal(err)
+		}
+		if err := reportOutput(report, reportChanges, *outFile, previous); err != nil {
+			log.Fatal(err)
+		}
+		publishOutputs(c, "")
+		return
+	}
+
+	if *check {
+		if *outFile == "" || *outFile == "-" {
+			log.Fatal("-check requires -o or -r")
+		}
+		var buf bytes.Buffer
+		for _, path := range c.Packages(flag.Arg(0)) {
+			if err := c.Convert(path, &buf); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if !bytes.Equal(previous, buf.Bytes()) {
+			log.Fatalf("out of date: %s", *outFile)
+		}
+		return
+	}
+
+	if *watch {
+		if err := watchPackages(c, opts, *altPkgTemplate, *outFile, *previewAddr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	of := os.Stdout
+	if *outFile != "" && *outFile != "-" {
+		of, err = os.Create(*outFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	failed := false
+	if *combine != "" {
+		paths := c.Packages(flag.Arg(0))
+		for _, pattern := range strings.Split(*combine, ",") {
+			paths = append(paths, c.Packages(pattern)...)
+		}
+		if err := c.ConvertCombined(paths, of); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, path := range c.Packages(flag.Arg(0)) {
+			if err := c.Convert(path, of); err != nil {
+				// convert the other packages, then fail
+				log.Print(err)
+				failed = true
+			}
+		}
+	}
+	if err := c.WriteSymbols(); err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+
+	if report != nil || *redirectsFile != "" || *publishURL != "" {
+		if err := of.Close(); err != nil {
+			log.Fatal(err)
+		}
+		if err := reportOutput(report, reportChanges, *outFile, previous); err != nil {
+			log.Fatal(err)
+		}
+	}
+	publishOutputs(c, *outFile)
+}
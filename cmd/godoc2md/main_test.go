@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mainEnv is set in the environment of the test binary run as godoc2md
+// by runMain.
+const mainEnv = "GODOC2MD_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs godoc2md with args in dir and returns its exit status and
+// its output on stderr.
+func runMain(t *testing.T, dir string, args ...string) (int, string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), mainEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	if err, ok := err.(*exec.ExitError); ok {
+		return err.ExitCode(), string(out)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0, string(out)
+}
+
+// writeFiles writes the files of contents, by name, under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":         "module example.com/thing\n",
+		"thing/thing.go": "// Package thing does things.\npackage thing\n\n// Thing is a thing.\ntype Thing struct{}\n",
+		"missing.tmpl":   "# {{.PDoc.Name}}\n{{.Err}}\n",
+		".godoc2md.yaml": "",
+	})
+	testData := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"-o", "README.md", "./thing"}, 0},
+		{[]string{"-template", "missing.tmpl", "-o", "README.md", "./thing"}, 0},
+		{[]string{"-strict-templates", "-template", "missing.tmpl", "-o", "README.md", "./thing"}, 1},
+		{[]string{"-template", "missing.tmpl", "-r", "./thing"}, 0},
+		{[]string{"-strict-templates", "-template", "missing.tmpl", "-r", "./thing"}, 1},
+	}
+	for n, tt := range testData {
+		if status, out := runMain(t, dir, tt.args...); status != tt.expected {
+			t.Errorf("%d: godoc2md %q: exit status %d, expected %d:\n%s", n, tt.args, status, tt.expected, out)
+		}
+	}
+}
+
+func TestVerifyOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"thing/thing.go": "// Package thing does things with a [Thing], see [NewThing] and [Thing.Do].\n//\n// # Usage\n//\n// Read [Max] and [Default].\npackage thing\n\n" +
+			"// Max is the maximum.\nconst Max = 10\n\n// Default is the default [Thing].\nvar Default = NewThing()\n\n" +
+			"// Thing is a thing.\ntype Thing struct{}\n\n// NewThing returns a [Thing].\nfunc NewThing() *Thing { return &Thing{} }\n\n" +
+			"// Do does it, see [Max].\nfunc (t *Thing) Do() error { return nil }\n\n// Helper helps [Thing.Do].\nfunc Helper() {}\n",
+		"thing/example_test.go": "package thing_test\n\nfunc Example() {}\n\nfunc ExampleNewThing() {}\n\nfunc ExampleThing_Do() {}\n\nfunc ExampleThing_Do_second() {}\n",
+		"broken.tmpl":           "# {{.PDoc.Name}}\n[Nowhere](#nowhere)\n",
+		".godoc2md.yaml":        "",
+	})
+	for _, dialect := range []string{"gfm", "commonmark", "mdx", "bitbucket", "gitlab", "azure", "azure-wiki", "plain"} {
+		for _, layout := range []string{"flat", "pkgsite", "summary"} {
+			args := []string{"-verify-output", "-ex", "-toc", "-dialect", dialect, "-layout", layout, "-o", "README.md", "./thing"}
+			if status, out := runMain(t, dir, args...); status != 0 {
+				t.Errorf("godoc2md %q: exit status %d, expected 0:\n%s", args, status, out)
+			}
+		}
+	}
+	args := []string{"-verify-output", "-template", "broken.tmpl", "-o", "README.md", "./thing"}
+	if status, out := runMain(t, dir, args...); status != 1 {
+		t.Errorf("godoc2md %q: exit status %d, expected 1:\n%s", args, status, out)
+	}
+}
+
+func TestCacheRewrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":            "module example.com/thing\n",
+		"thing/thing.go":    "// Package thing does things.\npackage thing\n",
+		"thing/OVERVIEW.md": "Read this first.\n",
+		".godoc2md.yaml":    "",
+	})
+	readme := filepath.Join(dir, "thing", "README.md")
+	for _, overview := range []string{"Read this first.", "Read this again."} {
+		writeFiles(t, dir, map[string]string{"thing/OVERVIEW.md": overview + "\n"})
+		args := []string{"-r", "-cache", ".cache", "./thing"}
+		if status, out := runMain(t, dir, args...); status != 0 {
+			t.Fatalf("godoc2md %q: exit status %d, expected 0:\n%s", args, status, out)
+		}
+		md, err := ioutil.ReadFile(readme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(md), overview) {
+			t.Errorf("godoc2md %q: expected %q in README.md, got:\n%s", args, overview, md)
+		}
+	}
+}
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":         "module example.com/thing\n",
+		"thing/thing.go": "// Package thing does things.\npackage thing\n\n// F fs.\nfunc F() {}\n",
+		".godoc2md.yaml": "",
+	})
+	if status, out := runMain(t, dir, "-format", "json", "-o", "old.json", "./thing"); status != 0 {
+		t.Fatalf("godoc2md -format json: exit status %d:\n%s", status, out)
+	}
+	writeFiles(t, dir, map[string]string{"thing/thing.go": "// Package thing does things.\npackage thing\n\n// G gs.\nfunc G() {}\n"})
+	expected := "example.com/thing/thing:\n\t+ func G\n\t- func F\n"
+	if status, out := runMain(t, dir, "diff", "-old", "old.json", "./thing"); status != 0 || out != expected {
+		t.Errorf("godoc2md diff: exit status %d, output %q, expected 0, %q", status, out, expected)
+	}
+}
+
+func TestLinksFlag(t *testing.T) {
+	testData := []struct {
+		args     []string
+		expected string
+		arg      string
+		err      bool
+	}{
+		{nil, "true", "", false},
+		{[]string{"-links", "."}, "true", ".", false},
+		{[]string{"-links=false", "."}, "false", ".", false},
+		{[]string{"-links=0", "."}, "false", ".", false},
+		{[]string{"-links=local", "."}, "local", ".", false},
+		{[]string{"-links=remote", "."}, "", "", true},
+	}
+	for n, tt := range testData {
+		fs := flag.NewFlagSet("godoc2md", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		f := linksFlag("true")
+		fs.Var(&f, "links", "")
+		if err := fs.Parse(tt.args); (err != nil) != tt.err {
+			t.Errorf("%d: Parse(%q): error %v, expected error %t", n, tt.args, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if string(f) != tt.expected || fs.Arg(0) != tt.arg {
+			t.Errorf("%d: Parse(%q) = %q, %q, expected %q, %q", n, tt.args, f, fs.Arg(0), tt.expected, tt.arg)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/doc/comment"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/davecheney/godoc2md"
+)
+
+const (
+	// notionAPI is the base URL of the Notion API.
+	notionAPI = "https://api.notion.com/v1/"
+
+	// notionVersion is the version of the Notion API the blocks are
+	// written for.
+	notionVersion = "2022-06-28"
+
+	// notionTokenEnv is the environment variable of the token of the
+	// Notion integration publishing the pages.
+	notionTokenEnv = "NOTION_TOKEN"
+
+	// notionMaxText and notionMaxBlocks are the limits of the Notion API
+	// on the length of a text object and on the number of blocks appended
+	// by a request.
+	notionMaxText   = 2000
+	notionMaxBlocks = 100
+)
+
+// A notionBlock is a block of a Notion page, as sent to the Notion API.
+type notionBlock map[string]interface{}
+
+// notionText returns the rich text of s, split in text objects of at
+// most notionMaxText characters, in italics or linking to the URL link if
+// set.
+func notionText(s string, italic bool, link string) []interface{} {
+	var texts []interface{}
+	for r := []rune(s); len(r) > 0; {
+		n := len(r)
+		if n > notionMaxText {
+			n = notionMaxText
+		}
+		text := map[string]interface{}{"content": string(r[:n])}
+		if link != "" {
+			text["link"] = map[string]string{"url": link}
+		}
+		t := map[string]interface{}{"type": "text", "text": text}
+		if italic {
+			t["annotations"] = map[string]bool{"italic": true}
+		}
+		texts = append(texts, t)
+		r = r[n:]
+	}
+	return texts
+}
+
+// newNotionBlock returns a block of the given type, such as "paragraph"
+// or "heading_2", of the rich text texts.
+func newNotionBlock(kind string, texts []interface{}) notionBlock {
+	if texts == nil {
+		texts = []interface{}{}
+	}
+	return notionBlock{"object": "block", "type": kind, kind: map[string]interface{}{"rich_text": texts}}
+}
+
+// notionCode returns a code block of code in the given language.
+func notionCode(code, language string) notionBlock {
+	b := newNotionBlock("code", notionText(strings.TrimRight(code, "\n"), false, ""))
+	b["code"].(map[string]interface{})["language"] = language
+	return b
+}
+
+// notionInline returns the rich text of the text of a doc comment, its
+// links to other packages resolved to pkg.go.dev.
+func notionInline(text []comment.Text) []interface{} {
+	var texts []interface{}
+	var add func(text []comment.Text, italic bool, link string)
+	add = func(text []comment.Text, italic bool, link string) {
+		for _, t := range text {
+			switch t := t.(type) {
+			case comment.Plain:
+				texts = append(texts, notionText(strings.Replace(string(t), "\n", " ", -1), italic, link)...)
+			case comment.Italic:
+				texts = append(texts, notionText(strings.Replace(string(t), "\n", " ", -1), true, link)...)
+			case *comment.Link:
+				add(t.Text, italic, t.URL)
+			case *comment.DocLink:
+				url := link
+				if t.ImportPath != "" {
+					url = t.DefaultURL("https://pkg.go.dev")
+				}
+				add(t.Text, italic, url)
+			}
+		}
+	}
+	add(text, false, "")
+	return texts
+}
+
+// notionDoc returns the blocks of the doc comment text.
+func notionDoc(text string) []notionBlock {
+	var p comment.Parser
+	var blocks []notionBlock
+	for _, b := range p.Parse(text).Content {
+		switch b := b.(type) {
+		case *comment.Heading:
+			blocks = append(blocks, newNotionBlock("heading_3", notionInline(b.Text)))
+		case *comment.Paragraph:
+			blocks = append(blocks, newNotionBlock("paragraph", notionInline(b.Text)))
+		case *comment.Code:
+			blocks = append(blocks, notionCode(b.Text, "go"))
+		case *comment.List:
+			kind := "bulleted_list_item"
+			if b.Items[0].Number != "" {
+				kind = "numbered_list_item"
+			}
+			for _, item := range b.Items {
+				var texts []interface{}
+				for _, c := range item.Content {
+					if p, ok := c.(*comment.Paragraph); ok {
+						texts = append(texts, notionInline(p.Text)...)
+					}
+				}
+				blocks = append(blocks, newNotionBlock(kind, texts))
+			}
+		}
+	}
+	return blocks
+}
+
+// notionBlocks returns the blocks of the Notion page of the package p:
+// its documentation followed by its declarations, each with its doc
+// comment, and its examples.
+func notionBlocks(p *godoc2md.PackageDoc) []notionBlock {
+	blocks := notionDoc(p.Doc)
+	heading := func(kind, title string) {
+		blocks = append(blocks, newNotionBlock(kind, notionText(title, false, "")))
+	}
+	values := func(title string, values []godoc2md.ValueDoc) {
+		if len(values) == 0 {
+			return
+		}
+		heading("heading_2", title)
+		for _, v := range values {
+			blocks = append(blocks, notionCode(v.Decl, "go"))
+			blocks = append(blocks, notionDoc(v.Doc)...)
+		}
+	}
+	funcs := func(kind string, funcs []godoc2md.FuncDoc) {
+		for _, f := range funcs {
+			title := "func " + f.Name
+			if f.Recv != "" {
+				title = "func (" + f.Recv + ") " + f.Name
+			}
+			heading(kind, title)
+			blocks = append(blocks, notionCode(f.Decl, "go"))
+			blocks = append(blocks, notionDoc(f.Doc)...)
+		}
+	}
+	values("Constants", p.Consts)
+	values("Variables", p.Vars)
+	funcs("heading_2", p.Funcs)
+	for _, t := range p.Types {
+		heading("heading_2", "type "+t.Name)
+		blocks = append(blocks, notionCode(t.Decl, "go"))
+		blocks = append(blocks, notionDoc(t.Doc)...)
+		for _, v := range append(t.Consts, t.Vars...) {
+			blocks = append(blocks, notionCode(v.Decl, "go"))
+			blocks = append(blocks, notionDoc(v.Doc)...)
+		}
+		funcs("heading_3", t.Funcs)
+		funcs("heading_3", t.Methods)
+	}
+	if len(p.Examples) > 0 {
+		heading("heading_2", "Examples")
+		for _, eg := range p.Examples {
+			name := eg.Name
+			if name == "" {
+				name = "Package"
+			}
+			heading("heading_3", "Example "+strings.Replace(name, "_", ".", 1))
+			blocks = append(blocks, notionDoc(eg.Doc)...)
+			blocks = append(blocks, notionCode(eg.Code, "go"))
+			if eg.Output != "" {
+				blocks = append(blocks, notionCode(eg.Output, "plain text"))
+			}
+		}
+	}
+	return blocks
+}
+
+// A notionClient calls the Notion API at api, notionAPI, with the token
+// of an integration.
+type notionClient struct {
+	api    string
+	token  string
+	client *http.Client
+}
+
+// do calls the Notion API method on the endpoint path with the JSON of
+// body, if any, and decodes the JSON response into result, if set.
+func (n *notionClient) do(method, path string, body, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, n.api+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Message string }
+		if json.Unmarshal(data, &e) != nil || e.Message == "" {
+			e.Message = resp.Status
+		}
+		return fmt.Errorf("%s %s: %s", method, path, e.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+// children returns the IDs of the child blocks of the block id, and the
+// titles of those that are pages.
+func (n *notionClient) children(id string) (ids, titles []string, err error) {
+	cursor := ""
+	for {
+		var page struct {
+			Results []struct {
+				ID        string
+				Type      string
+				ChildPage struct{ Title string } `json:"child_page"`
+			}
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		path := "blocks/" + id + "/children?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + cursor
+		}
+		if err := n.do("GET", path, nil, &page); err != nil {
+			return nil, nil, err
+		}
+		for _, b := range page.Results {
+			title := ""
+			if b.Type == "child_page" {
+				title = b.ChildPage.Title
+			}
+			ids, titles = append(ids, b.ID), append(titles, title)
+		}
+		if !page.HasMore {
+			return ids, titles, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// append appends the blocks to the block id, by batches of
+// notionMaxBlocks.
+func (n *notionClient) append(id string, blocks []notionBlock) error {
+	for len(blocks) > 0 {
+		size := len(blocks)
+		if size > notionMaxBlocks {
+			size = notionMaxBlocks
+		}
+		if err := n.do("PATCH", "blocks/"+id+"/children", map[string]interface{}{"children": blocks[:size]}, nil); err != nil {
+			return err
+		}
+		blocks = blocks[size:]
+	}
+	return nil
+}
+
+// publishPage writes the blocks to the child page title of the page
+// parent, replacing its content if it exists.
+func (n *notionClient) publishPage(parent, title string, blocks []notionBlock) error {
+	ids, titles, err := n.children(parent)
+	if err != nil {
+		return err
+	}
+	for i, t := range titles {
+		if t != title {
+			continue
+		}
+		old, _, err := n.children(ids[i])
+		if err != nil {
+			return err
+		}
+		for _, id := range old {
+			if err := n.do("DELETE", "blocks/"+id, nil, nil); err != nil {
+				return err
+			}
+		}
+		return n.append(ids[i], blocks)
+	}
+	var page struct{ ID string }
+	err = n.do("POST", "pages", map[string]interface{}{
+		"parent":     map[string]string{"page_id": parent},
+		"properties": map[string]interface{}{"title": map[string]interface{}{"title": notionText(title, false, "")}},
+	}, &page)
+	if err != nil {
+		return err
+	}
+	return n.append(page.ID, blocks)
+}
+
+// publishNotion writes the documentation of the packages paths to pages
+// of the Notion page parent, one per package titled with its import
+// path, created or updated, with the token of the integration read from
+// the NOTION_TOKEN environment variable.
+func publishNotion(c *godoc2md.Converter, paths []string, parent string) error {
+	token := os.Getenv(notionTokenEnv)
+	if token == "" {
+		return fmt.Errorf("%s isn't set to the token of the Notion integration", notionTokenEnv)
+	}
+	n := &notionClient{api: notionAPI, token: token, client: &http.Client{Timeout: 30 * time.Second}}
+	for _, path := range paths {
+		p, err := c.PackageDoc(path)
+		if err != nil {
+			return err
+		}
+		if p.ImportPath == "" {
+			continue
+		}
+		if *verbose {
+			log.Printf("publishing %s to Notion", p.ImportPath)
+		}
+		if err := n.publishPage(parent, p.ImportPath, notionBlocks(p)); err != nil {
+			return fmt.Errorf("%s: %v", p.ImportPath, err)
+		}
+	}
+	return nil
+}
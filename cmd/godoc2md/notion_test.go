@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecheney/godoc2md"
+)
+
+func TestNotionText(t *testing.T) {
+	testData := []struct {
+		s        string
+		italic   bool
+		link     string
+		expected []int // lengths in runes of the text objects
+	}{
+		{"", false, "", nil},
+		{"hello", false, "", []int{5}},
+		{strings.Repeat("é", notionMaxText), true, "", []int{notionMaxText}},
+		{strings.Repeat("é", notionMaxText+1), false, "https://pkg.go.dev/io", []int{notionMaxText, 1}},
+		{strings.Repeat("x", 2*notionMaxText+10), false, "", []int{notionMaxText, notionMaxText, 10}},
+	}
+	for n, tt := range testData {
+		texts := notionText(tt.s, tt.italic, tt.link)
+		var actual []int
+		for _, e := range texts {
+			obj := e.(map[string]interface{})
+			text := obj["text"].(map[string]interface{})
+			actual = append(actual, len([]rune(text["content"].(string))))
+			if _, ok := obj["annotations"]; ok != tt.italic {
+				t.Errorf("%d: italic annotation %t, expected %t", n, ok, tt.italic)
+			}
+			if link, _ := text["link"].(map[string]string); link["url"] != tt.link {
+				t.Errorf("%d: link %q, expected %q", n, link["url"], tt.link)
+			}
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: notionText: lengths %v, expected %v", n, actual, tt.expected)
+		}
+	}
+}
+
+// notionKinds returns the types of blocks.
+func notionKinds(blocks []notionBlock) []string {
+	var kinds []string
+	for _, b := range blocks {
+		kinds = append(kinds, b["type"].(string))
+	}
+	return kinds
+}
+
+func TestNotionDoc(t *testing.T) {
+	testData := []struct {
+		text     string
+		expected []string
+	}{
+		{"", nil},
+		{"Package p does things.\n", []string{"paragraph"}},
+		{"Intro.\n\n# Usage\n\nRun it:\n\n\tp.Run()\n", []string{"paragraph", "heading_3", "paragraph", "code"}},
+		{"Steps:\n  - one\n  - two\n\nOrder:\n  1. first\n  2. second\n", []string{"paragraph", "bulleted_list_item", "bulleted_list_item", "paragraph", "numbered_list_item", "numbered_list_item"}},
+	}
+	for n, tt := range testData {
+		if actual := notionKinds(notionDoc(tt.text)); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: notionDoc(%q) = %v, expected %v", n, tt.text, actual, tt.expected)
+		}
+	}
+}
+
+func TestNotionBlocks(t *testing.T) {
+	testData := []struct {
+		p        *godoc2md.PackageDoc
+		expected []string
+	}{
+		{&godoc2md.PackageDoc{Doc: "Package p.\n"}, []string{"paragraph"}},
+		{&godoc2md.PackageDoc{
+			Consts: []godoc2md.ValueDoc{{Decl: "const A = 1", Doc: "A is one.\n"}},
+			Funcs:  []godoc2md.FuncDoc{{Name: "F", Decl: "func F()"}},
+		}, []string{"heading_2", "code", "paragraph", "heading_2", "code"}},
+		{&godoc2md.PackageDoc{
+			Types: []godoc2md.TypeDoc{{
+				Name:    "T",
+				Decl:    "type T int",
+				Doc:     "T is a type.\n",
+				Methods: []godoc2md.FuncDoc{{Name: "M", Recv: "T", Decl: "func (T) M()"}},
+			}},
+			Examples: []godoc2md.ExampleDoc{{Name: "T_M", Code: "T(0).M()", Output: "ok"}},
+		}, []string{"heading_2", "code", "paragraph", "heading_3", "code", "heading_2", "heading_3", "code", "code"}},
+	}
+	for n, tt := range testData {
+		if actual := notionKinds(notionBlocks(tt.p)); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: notionBlocks = %v, expected %v", n, actual, tt.expected)
+		}
+	}
+}
+
+func TestNotionPublishPage(t *testing.T) {
+	testData := []struct {
+		children string // of the parent page, as returned by the API
+		blocks   int
+		expected []string
+	}{
+		{`{"results": [{"id": "other", "type": "child_page", "child_page": {"title": "example.com/other"}}]}`, 150, []string{
+			"GET /blocks/parent/children",
+			"POST /pages example.com/p",
+			"PATCH /blocks/new/children 100",
+			"PATCH /blocks/new/children 50",
+		}},
+		{`{"results": [{"id": "page", "type": "child_page", "child_page": {"title": "example.com/p"}}]}`, 3, []string{
+			"GET /blocks/parent/children",
+			"GET /blocks/page/children",
+			"DELETE /blocks/old1",
+			"DELETE /blocks/old2",
+			"PATCH /blocks/page/children 3",
+		}},
+	}
+	for n, tt := range testData {
+		var actual []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, `{"message": "unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			call := r.Method + " " + r.URL.Path
+			var req struct {
+				Children   []notionBlock
+				Properties struct {
+					Title struct {
+						Title []struct{ Text struct{ Content string } }
+					}
+				}
+			}
+			json.Unmarshal(body, &req)
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/blocks/parent/children":
+				fmt.Fprint(w, tt.children)
+			case r.Method == "GET":
+				fmt.Fprint(w, `{"results": [{"id": "old1", "type": "paragraph"}, {"id": "old2", "type": "code"}]}`)
+			case r.Method == "POST":
+				call += " " + req.Properties.Title.Title[0].Text.Content
+				fmt.Fprint(w, `{"id": "new"}`)
+			case r.Method == "PATCH":
+				call += fmt.Sprintf(" %d", len(req.Children))
+				fmt.Fprint(w, `{}`)
+			default:
+				fmt.Fprint(w, `{}`)
+			}
+			actual = append(actual, call)
+		}))
+		blocks := make([]notionBlock, tt.blocks)
+		for i := range blocks {
+			blocks[i] = newNotionBlock("paragraph", notionText(fmt.Sprint(i), false, ""))
+		}
+		c := &notionClient{api: ts.URL + "/", token: "secret", client: ts.Client()}
+		if err := c.publishPage("parent", "example.com/p", blocks); err != nil {
+			t.Errorf("%d: publishPage: %v", n, err)
+		}
+		ts.Close()
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: publishPage calls %q, expected %q", n, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// presets bundle the flags of the documentation targets of a
+// repository, by name. An explicitly set flag overrides the value of the
+// preset.
+var presets = map[string]map[string]string{
+	// a README.md next to the sources, rendered by the code host
+	"readme": {
+		"badges": "reference,license",
+	},
+	// a page of a static site generator such as Hugo, with its own
+	// table of contents
+	"website": {
+		"frontmatter": "yaml",
+		"layout":      "pkgsite",
+		"toc":         "true",
+		"xlinks":      "true",
+	},
+	// a wiki, one page per type
+	"wiki": {
+		"layout":      "pkgsite",
+		"split-types": "true",
+		"toc":         "true",
+	},
+}
+
+// applyPreset sets the flags of the named preset that weren't set on the
+// command line.
+func applyPreset(name string) error {
+	preset, ok := presets[name]
+	if !ok {
+		var names []string
+		for n := range presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown preset %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, value := range preset {
+		if set[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/davecheney/godoc2md"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// A preview serves the last output of -watch rendered as HTML, reloading
+// the page in the browser when it's regenerated.
+type preview struct {
+	mu         sync.Mutex
+	page       []byte
+	generation int
+}
+
+// previewScript reloads the page when the generation served at
+// /generation changes.
+const previewScript = `<script>
+const generation = %d;
+setInterval(() => fetch("/generation").then(r => r.text()).then(g => {
+	if (Number(g) !== generation) location.reload();
+}).catch(() => {}), 1000);
+</script>
+`
+
+// update renders the Markdown md as the page of p.
+func (p *preview) update(md []byte) {
+	var body bytes.Buffer
+	gm := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+	if err := gm.Convert(md, &body); err != nil {
+		body.Reset()
+		body.WriteString("<pre>" + html.EscapeString(err.Error()) + "</pre>")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generation++
+	var page bytes.Buffer
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>godoc2md preview</title>\n")
+	fmt.Fprintf(&page, previewScript, p.generation)
+	page.WriteString("</head>\n<body>\n")
+	page.Write(body.Bytes())
+	page.WriteString("</body>\n</html>\n")
+	p.page = page.Bytes()
+}
+
+// ServeHTTP serves the page of p, and its generation at /generation.
+func (p *preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	page, generation := p.page, p.generation
+	p.mu.Unlock()
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	case "/generation":
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strconv.Itoa(generation)))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serve serves p on addr in the background.
+func (p *preview) serve(addr string) {
+	go func() {
+		log.Fatal(http.ListenAndServe(addr, p))
+	}()
+	log.Printf("serving the preview on http://%s/", addr)
+}
+
+// watchPackages converts the packages given on the command line whenever
+// their sources or the template change, writing the output to outFile, or
+// stdout if it's empty or -, and serving it on addr unless it's empty. On
+// a change of the template file or directory tmplPath, the template is
+// read again and the Converter rebuilt from opts, keeping the packages
+// resolved by c.
+func watchPackages(c *godoc2md.Converter, opts []godoc2md.Option, tmplPath, outFile, addr string) error {
+	var p *preview
+	if addr != "" {
+		p = &preview{}
+		p.serve(addr)
+	}
+	paths := c.Packages(flag.Arg(0))
+	current, lastTemplate := c, ""
+	return c.Watch(paths, 500*time.Millisecond, func() error {
+		if tmplPath != "" {
+			if tmpl, partials, err := readTemplates(tmplPath); err != nil {
+				log.Print(err)
+			} else if key := fmt.Sprint(tmpl, partials); key != lastTemplate {
+				rebuilt, err := c.Rebuild(append(opts[:len(opts):len(opts)],
+					godoc2md.WithTemplate(tmpl),
+					godoc2md.WithTemplatePartials(partials),
+				)...)
+				if err != nil {
+					// keep the previous template until this one parses
+					log.Print(err)
+				} else {
+					current, lastTemplate = rebuilt, key
+				}
+			}
+		}
+		var buf bytes.Buffer
+		for _, path := range paths {
+			if err := current.Convert(path, &buf); err != nil {
+				// keep watching, the sources may be in the middle of an edit
+				log.Print(err)
+			}
+		}
+		switch {
+		case outFile != "" && outFile != "-":
+			if err := ioutil.WriteFile(outFile, buf.Bytes(), 0644); err != nil {
+				return err
+			}
+			log.Printf("wrote %s", outFile)
+		case p == nil:
+			if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		if p != nil {
+			p.update(buf.Bytes())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/davecheney/godoc2md"
+)
+
+// contentType returns the content type of the file name uploaded by
+// publish.
+func contentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	case ".json":
+		return "application/json"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// publishCommand returns the command uploading the file name to the
+// object url, s3://bucket/key with the aws command or gs://bucket/key with
+// gsutil, with its content type and the cache control header, if any.
+func publishCommand(name, url, cacheControl string) (*exec.Cmd, error) {
+	switch {
+	case strings.HasPrefix(url, "s3://"):
+		args := []string{"s3", "cp", name, url, "--content-type", contentType(name)}
+		if cacheControl != "" {
+			args = append(args, "--cache-control", cacheControl)
+		}
+		return exec.Command("aws", args...), nil
+	case strings.HasPrefix(url, "gs://"):
+		args := []string{"-h", "Content-Type:" + contentType(name)}
+		if cacheControl != "" {
+			args = append(args, "-h", "Cache-Control:"+cacheControl)
+		}
+		return exec.Command("gsutil", append(args, "cp", name, url)...), nil
+	}
+	return nil, fmt.Errorf("unknown bucket URL %q, want s3://bucket/prefix or gs://bucket/prefix", url)
+}
+
+// publish uploads the files to the bucket URL, such as s3://bucket/docs,
+// each at its key returned by publishKeys.
+func publish(files []string, url, cacheControl string) error {
+	keys, err := publishKeys(files)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		cmd, err := publishCommand(name, strings.TrimSuffix(url, "/")+"/"+keys[name], cacheControl)
+		if err != nil {
+			return err
+		}
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		if *verbose {
+			log.Printf("publishing %s", name)
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// publishKeys returns the keys of the files published from the current
+// directory, by file name: their slash-separated paths relative to the
+// current directory, or to the directory containing all of them if some
+// are outside it, so that they don't collide.
+func publishKeys(files []string) (map[string]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	abs := make(map[string]string, len(files))
+	for _, name := range files {
+		if abs[name], err = filepath.Abs(name); err != nil {
+			return nil, err
+		}
+	}
+	root := cwd
+	for _, name := range files {
+		if rel, err := filepath.Rel(cwd, abs[name]); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			root = commonDir(abs)
+			break
+		}
+	}
+	keys := make(map[string]string, len(files))
+	for _, name := range files {
+		rel, err := filepath.Rel(root, abs[name])
+		if err != nil {
+			return nil, err
+		}
+		keys[name] = path.Clean(filepath.ToSlash(rel))
+	}
+	return keys, nil
+}
+
+// commonDir returns the deepest directory containing all the absolute
+// file names of paths.
+func commonDir(paths map[string]string) string {
+	var dir string
+	for _, p := range paths {
+		d := filepath.Dir(p)
+		if dir == "" {
+			dir = d
+			continue
+		}
+		for dir != d && !strings.HasPrefix(d, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return dir
+}
+
+// publishBranch commits the files, each at its key returned by
+// publishKeys, and a CNAME file of the custom domain cname, if any,
+// to the git branch, such as gh-pages, on top of its previous commit.
+// It builds the commit from a temporary index, leaving the working tree
+// and the current branch untouched, and commits nothing if the files are
+// unchanged. The branch is to be pushed by the caller.
+func publishBranch(files []string, branch, message, cname string) error {
+	keys, err := publishKeys(files)
+	if err != nil {
+		return err
+	}
+	index, err := ioutil.TempFile("", "godoc2md-index")
+	if err != nil {
+		return err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+	git := func(stdin string, args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+index.Name())
+		cmd.Stdin = strings.NewReader(stdin)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	ref := "refs/heads/" + branch
+	parent, err := git("", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	var exit *exec.ExitError
+	if err != nil && !(errors.As(err, &exit) && exit.ExitCode() == 1) {
+		// other than the missing branch, whose commit is the first one
+		return err
+	}
+	if parent != "" {
+		if _, err := git("", "read-tree", parent); err != nil {
+			return err
+		}
+	} else if _, err := git("", "read-tree", "--empty"); err != nil {
+		return err
+	}
+	add := func(blob, key string) error {
+		_, err := git("", "update-index", "--add", "--cacheinfo", "100644,"+blob+","+key)
+		return err
+	}
+	for _, name := range files {
+		blob, err := git("", "hash-object", "-w", "--", name)
+		if err != nil {
+			return err
+		}
+		if *verbose {
+			log.Printf("publishing %s", name)
+		}
+		if err := add(blob, keys[name]); err != nil {
+			return err
+		}
+	}
+	if cname != "" {
+		blob, err := git(cname+"\n", "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		if err := add(blob, "CNAME"); err != nil {
+			return err
+		}
+	}
+	tree, err := git("", "write-tree")
+	if err != nil {
+		return err
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		previous, err := git("", "rev-parse", parent+"^{tree}")
+		if err != nil {
+			return err
+		}
+		if previous == tree {
+			return nil
+		}
+		args = append(args, "-p", parent)
+	}
+	commit, err := git("", args...)
+	if err != nil {
+		return err
+	}
+	_, err = git("", "update-ref", "-m", message, ref, commit)
+	return err
+}
+
+// publishOutputs uploads the files written by c, and the output file name
+// unless it's stdout, to the bucket set with -publish, if any, or commits
+// them to the git branch of the git:branch set with -publish, such as
+// git:gh-pages.
+func publishOutputs(c *godoc2md.Converter, name string) {
+	if *publishURL == "" {
+		return
+	}
+	files := c.Outputs()
+	if name != "" && name != "-" {
+		files = append(files, name)
+	}
+	var err error
+	if branch := strings.TrimPrefix(*publishURL, "git:"); branch != *publishURL {
+		err = publishBranch(files, branch, *publishMessage, *cname)
+	} else {
+		err = publish(files, *publishURL, *cacheControl)
+	}
+	if err != nil {
+		log.Fatal("-publish: ", err)
+	}
+}
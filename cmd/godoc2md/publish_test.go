@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPublishKeys(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(filepath.Dir(cwd), "out")
+	testData := []struct {
+		files    []string
+		expected map[string]string
+	}{
+		{[]string{"README.md", "api/README.md", "./api/img.png"}, map[string]string{"README.md": "README.md", "api/README.md": "api/README.md", "./api/img.png": "api/img.png"}},
+		{[]string{filepath.Join(out, "a", "README.md"), filepath.Join(out, "b", "README.md")}, map[string]string{filepath.Join(out, "a", "README.md"): "a/README.md", filepath.Join(out, "b", "README.md"): "b/README.md"}},
+		{[]string{"README.md", filepath.Join(out, "README.md")}, map[string]string{"README.md": "godoc2md/README.md", filepath.Join(out, "README.md"): "out/README.md"}},
+		{[]string{filepath.Join(out, "a", "README.md")}, map[string]string{filepath.Join(out, "a", "README.md"): "README.md"}},
+	}
+	for n, tt := range testData {
+		actual, err := publishKeys(tt.files)
+		if err != nil {
+			t.Errorf("%d: publishKeys(%q): %v", n, tt.files, err)
+		} else if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%d: publishKeys(%q) = %v, expected %v", n, tt.files, actual, tt.expected)
+		}
+	}
+}
+
+func TestPublishBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(env, "godoc2md@example.com")
+	}
+	git := func(args ...string) string {
+		out, err := exec.Command("git", args...).Output()
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	write := func(name, text string) {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testData := []struct {
+		readme   string
+		commits  string
+		expected string
+	}{
+		{"# api v1\n", "1", "# api v1"},
+		{"# api v1\n", "1", "# api v1"},
+		{"# api v2\n", "2", "# api v2"},
+	}
+	for n, tt := range testData {
+		write("api/README.md", tt.readme)
+		if err := publishBranch([]string{"api/README.md"}, "gh-pages", "Update documentation", "docs.example.com"); err != nil {
+			t.Fatalf("%d: publishBranch: %v", n, err)
+		}
+		if actual := git("rev-list", "--count", "gh-pages"); actual != tt.commits {
+			t.Errorf("%d: %s commits, expected %s", n, actual, tt.commits)
+		}
+		if actual := git("show", "gh-pages:api/README.md"); actual != tt.expected {
+			t.Errorf("%d: api/README.md = %q, expected %q", n, actual, tt.expected)
+		}
+		if actual := git("show", "gh-pages:CNAME"); actual != "docs.example.com" {
+			t.Errorf("%d: CNAME = %q, expected %q", n, actual, "docs.example.com")
+		}
+	}
+	if actual := git("status", "--porcelain"); actual != "?? api/" {
+		t.Errorf("git status = %q, expected the working tree untouched", actual)
+	}
+}
+
+func TestPublishBranchOutsideRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	if err := ioutil.WriteFile("README.md", []byte("# api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = publishBranch([]string{"README.md"}, "gh-pages", "Update documentation", "")
+	if err == nil || !strings.HasPrefix(err.Error(), "git rev-parse: ") {
+		t.Errorf("publishBranch: error %v, expected the error of git rev-parse", err)
+	}
+}
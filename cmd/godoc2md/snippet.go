@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/davecheney/godoc2md"
+)
+
+// renderSnippetCmd is the subcommand rendering a template snippet
+// against the text read from stdin, such as
+//
+//	godoc2md render-snippet -f '{{comment_md .}}' < comment.txt
+const renderSnippetCmd = "render-snippet"
+
+// parseSnippetArgs returns the template snippet given with -f in the
+// arguments args of the render-snippet subcommand.
+func parseSnippetArgs(args []string) string {
+	fs := flag.NewFlagSet(renderSnippetCmd, flag.ExitOnError)
+	text := fs.String("f", "", "template snippet to execute with the text read from stdin as dot, such as {{comment_md .}}")
+	_ = fs.Parse(args)
+	if *text == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return *text
+}
+
+// renderSnippet writes the snippet rendered by c against the text read
+// from stdin, without its comment markers if it has them, to stdout.
+func renderSnippet(c *godoc2md.Converter, snippet string) {
+	src, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := c.RenderSnippet(os.Stdout, snippet, commentText(string(src))); err != nil {
+		log.Fatal(err)
+	}
+}
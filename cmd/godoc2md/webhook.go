@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Message length limits of the webhooks: Discord rejects longer messages,
+// and Slack truncates them.
+const (
+	discordMaxMessage = 2000
+	slackMaxMessage   = 4000
+)
+
+// postWebhook posts the digest text to the Slack or Discord incoming
+// webhook url, truncated to the message length limit of the service.
+// Nothing is posted if text is empty.
+func postWebhook(url, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	key, max := "text", slackMaxMessage
+	if strings.Contains(url, "discord.com/") || strings.Contains(url, "discordapp.com/") {
+		key, max = "content", discordMaxMessage
+	}
+	if r := []rune(text); len(r) > max {
+		text = string(r[:max-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]string{key: text})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// postDigest posts the digest written to buf to the webhook set with
+// -webhook, if any, once.
+func postDigest(buf *bytes.Buffer) {
+	if *webhookURL == "" || buf.Len() == 0 {
+		return
+	}
+	text := buf.String()
+	buf.Reset()
+	if err := postWebhook(*webhookURL, text); err != nil {
+		log.Fatal("-webhook: ", err)
+	}
+}
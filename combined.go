@@ -0,0 +1,110 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/doc"
+	"io"
+	"io/ioutil"
+	pathpkg "path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// combinedAnchorRx matches the anchors and the links to anchors of the
+// documentation of a package, prefixed in combined documents.
+var combinedAnchorRx = regexp.MustCompile(`(name="|id="|href="#|\]\(#)`)
+
+// combinedIDRx matches the characters replaced with hyphens in the IDs
+// of the packages of combined documents.
+var combinedIDRx = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// A combinedPart is the documentation of a package of a combined
+// document.
+type combinedPart struct {
+	id, title, synopsis string
+	md                  string
+}
+
+// ConvertCombined writes the documentation of the packages paths, such as
+// a command and the library packages it's made of, to w as a single
+// document: a Contents section linking to each package, with its
+// synopsis, followed by the documentation of the packages, their headings
+// one level down and their anchors prefixed with the ID of the package.
+// The header file is written once, at the top, and no front matter is
+// written.
+func (c *Converter) ConvertCombined(paths []string, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	contents := c.anchorFunc("pkg-contents") + c.headingFunc("section", 2) + `<a name="pkg-contents">Contents</a>` + "\n\n"
+	headerFile, frontMatter := c.headerFile, c.frontMatterFormat
+	c.headerFile, c.frontMatterFormat = "", ""
+	c.headingOffset++
+	defer func() {
+		c.headerFile, c.frontMatterFormat = headerFile, frontMatter
+		c.headingOffset--
+	}()
+
+	c.outputDir = c.assetDir
+	ids := make(map[string]bool)
+	var parts []combinedPart
+	for _, path := range paths {
+		var buf bytes.Buffer
+		info, err := c.pageInfo(&buf, path)
+		if err != nil {
+			return err
+		}
+		c.regroup(info)
+		if err := c.render(&buf, c.tmpl, info); err != nil {
+			return err
+		}
+		if err := c.copyAssets(info.Dirname, c.assetDir); err != nil {
+			return err
+		}
+		p := combinedPart{title: path}
+		if info.PDoc != nil {
+			p.title = info.PDoc.ImportPath
+			if info.IsMain {
+				p.title = pathpkg.Base(p.title)
+			}
+			p.synopsis = c.synopsisMdFunc(doc.Synopsis(info.PDoc.Doc))
+		}
+		id := strings.Trim(combinedIDRx.ReplaceAllString(strings.ToLower(pathpkg.Base(p.title)), "-"), "-")
+		p.id = id
+		for i := 2; ids[p.id]; i++ {
+			p.id = id + "-" + strconv.Itoa(i)
+		}
+		ids[p.id] = true
+		p.md = combinedAnchorRx.ReplaceAllString(buf.String(), "${1}"+p.id+"-")
+		parts = append(parts, p)
+	}
+
+	if headerFile != "" {
+		header, err := ioutil.ReadFile(headerFile)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+	}
+	var buf strings.Builder
+	buf.WriteString(contents)
+	for _, p := range parts {
+		buf.WriteString("* [" + c.mdFunc(p.title) + "](#" + p.id + ")")
+		if p.synopsis != "" {
+			buf.WriteString(": " + p.synopsis)
+		}
+		buf.WriteString("\n")
+	}
+	for _, p := range parts {
+		buf.WriteString("\n<a name=\"" + p.id + "\"></a>\n" + p.md)
+	}
+	out := buf.String()
+	if c.dialect.plain {
+		out = plainMarkdown(out)
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
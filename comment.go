@@ -4,10 +4,15 @@
 
 // Godoc comment extraction and comment -> Markdown formatting.
 
-package main
+package godoc2md
 
 import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"go/doc/comment"
 	"io"
+	pathpkg "path"
 	"regexp"
 	"strings"
 	"text/template" // for HTMLEscape
@@ -15,66 +20,14 @@ import (
 	"unicode/utf8"
 )
 
-const (
-	// Regexp for Go identifiers
-	identRx = `[a-zA-Z_][a-zA-Z_0-9]*` // TODO(gri) ASCII only for now - fix this
-
-	// Regexp for URLs
-	protocol = `(https?|ftp|file|gopher|mailto|news|nntp|telnet|wais|prospero):`
-	hostPart = `[a-zA-Z0-9_@\-]+`
-	filePart = `[a-zA-Z0-9_?%#~&/\-+=]+`
-	urlRx    = protocol + `//` + // http://
-		hostPart + `([.:]` + hostPart + `)*/?` + // //www.google.com:8080/
-		filePart + `([:.,]` + filePart + `)*`
-)
-
-var matchRx = regexp.MustCompile(`(` + urlRx + `)|(` + identRx + `)`)
-
 var (
 	htmlA    = []byte(`<a href="`)
 	htmlAq   = []byte(`">`)
 	htmlEnda = []byte("</a>")
 
-	mdPre     = []byte("\t")
 	mdNewline = []byte("\n")
-	mdH3      = []byte("### ")
 )
 
-// Emphasize and escape a line of text for HTML. URLs are converted into links.
-func emphasize(w io.Writer, line string) {
-	for {
-		m := matchRx.FindStringSubmatchIndex(line)
-		if m == nil {
-			break
-		}
-		// m >= 6 (two parenthesized sub-regexps in matchRx, 1st one is urlRx)
-
-		// write text before match
-		_, _ = w.Write([]byte(line[0:m[0]]))
-
-		// analyze match
-		match := line[m[0]:m[1]]
-
-		// if URL then write as link
-		if m[2] >= 0 {
-			_, _ = w.Write(htmlA)
-			template.HTMLEscape(w, []byte(match))
-			_, _ = w.Write(htmlAq)
-		}
-
-		// write match
-		_, _ = w.Write([]byte(match))
-
-		if m[2] >= 0 {
-			_, _ = w.Write(htmlEnda)
-		}
-
-		// advance
-		line = line[m[1]:]
-	}
-	_, _ = w.Write([]byte(line))
-}
-
 func indentLen(s string) int {
 	i := 0
 	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
@@ -87,36 +40,6 @@ func isBlank(s string) bool {
 	return len(s) == 0 || (len(s) == 1 && s[0] == '\n')
 }
 
-func commonPrefix(a, b string) string {
-	i := 0
-	for i < len(a) && i < len(b) && a[i] == b[i] {
-		i++
-	}
-	return a[0:i]
-}
-
-func unindent(block []string) {
-	if len(block) == 0 {
-		return
-	}
-
-	// compute maximum common white prefix
-	prefix := block[0][0:indentLen(block[0])]
-	for _, line := range block {
-		if !isBlank(line) {
-			prefix = commonPrefix(prefix, line[0:indentLen(line)])
-		}
-	}
-	n := len(prefix)
-
-	// remove
-	for i, line := range block {
-		if !isBlank(line) {
-			block[i] = line[n:]
-		}
-	}
-}
-
 // heading returns the trimmed line if it passes as a section heading;
 // otherwise it returns the empty string.
 func heading(line string) string {
@@ -157,18 +80,9 @@ func heading(line string) string {
 	return line
 }
 
-type op int
-
-const (
-	opPara op = iota
-	opHead
-	opPre
-)
-
-type block struct {
-	op    op
-	lines []string
-}
+// imageRx matches the "Image: path" convention used to reference
+// an image from a doc comment.
+var imageRx = regexp.MustCompile(`^Image:[ \t]+(\S+)[ \t]*\n?$`)
 
 var nonAlphaNumRx = regexp.MustCompile(`[^a-zA-Z0-9]`)
 
@@ -183,117 +97,329 @@ func anchorID(line string) string {
 // nor to have trailing spaces at the end of lines.
 // The comment markers have already been removed.
 //
-// Each span of unindented non-blank lines is converted into
-// a single paragraph. There is one exception to the rule: a span that
-// consists of a single line, is followed by another paragraph span,
-// begins with a capital letter, and contains no punctuation
-// is formatted as a heading.
+// The comment is parsed with go/doc/comment, so that both the legacy
+// conventions and the Go 1.19 doc comment syntax are understood:
+// paragraphs, headings (legacy ones and "# Heading" lines), code blocks,
+// bullet and numbered lists, links and doc links such as [pkg.Symbol].
 //
-// A span of indented lines is converted into a <pre> block,
-// with the common indent prefix removed.
+// A line of the form "Image: path" is converted into a Markdown image;
+// the path is recorded so that the asset can be copied next to the output.
 //
 // URLs in the comment text are converted into links.
 func ToMD(w io.Writer, text string) {
-	for _, b := range blocks(text) {
-		switch b.op {
-		case opPara:
-			for _, line := range b.lines {
-				emphasize(w, line)
-			}
-			_, _ = w.Write(mdNewline) // trailing newline to emulate </p>
-		case opHead:
-			_, _ = w.Write(mdH3)
-			id := ""
-			for _, line := range b.lines {
-				if id == "" {
-					id = anchorID(line)
-				}
-				_, _ = w.Write([]byte(line))
+	_ = CommentToMarkdown(w, text)
+}
+
+// CommentToMarkdown converts the doc comment text to Markdown, like ToMD,
+// configured with the options applying to doc comments: WithLineBreaks,
+// WithLead, WithHTMLAnchors, WithPackageLinkBase, WithDialect,
+// WithTabWidth and WithCodeLanguage. The other options are ignored.
+// Unlike New, it loads no package, so doc links such as [Symbol] are only
+// resolved when qualified.
+func CommentToMarkdown(w io.Writer, text string, opts ...Option) error {
+	c := &Converter{pkgLinkBase: defaultPkgLinkBase, dialectName: "gfm", tabWidth: 4}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.initComments(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	c.toMD(&buf, text)
+	if c.dialect.plain {
+		_, err := io.WriteString(w, plainMarkdown(buf.String()))
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// initComments checks the options applying to doc comments, including
+// their headings.
+func (c *Converter) initComments() error {
+	switch c.lineBreaks {
+	case "", "spaces", "br":
+	default:
+		return fmt.Errorf("line breaks: unknown style %q", c.lineBreaks)
+	}
+
+	var ok bool
+	if c.dialect, ok = dialects[c.dialectName]; !ok {
+		return fmt.Errorf("dialect: unknown dialect %q", c.dialectName)
+	}
+
+	if _, ok := leadMarkers[c.lead]; !ok && c.lead != "" {
+		return fmt.Errorf("lead: unknown style %q", c.lead)
+	}
+	return c.checkHeadings()
+}
+
+// toMD is ToMD, with the heading anchors, paragraph line breaks and
+// doc links configured for c. The referenced assets are recorded into c.
+func (c *Converter) toMD(w io.Writer, text string) {
+	var p *comment.Parser
+	if c.pdoc != nil {
+		// resolves the doc links to the symbols and imports of the package
+		p = c.pdoc.Parser()
+	} else {
+		p = new(comment.Parser)
+	}
+	for i, b := range p.Parse(text).Content {
+		switch b := b.(type) {
+		case *comment.Paragraph:
+			if c.deprecated && isDeprecation(b.Text) {
+				c.deprecationMD(w, b.Text)
+				continue
 			}
-			_, _ = w.Write(mdNewline)
-		case opPre:
-			_, _ = w.Write(mdNewline)
-			for _, line := range b.lines {
-				_, _ = w.Write(mdPre)
-				emphasize(w, line)
+			c.paragraphMD(w, b.Text, i == 0 && c.lead != "")
+		case *comment.Heading:
+			if c.htmlAnchors {
+				_, _ = io.WriteString(w, c.anchorFunc(anchorID(plainText(b.Text))))
 			}
+			_, _ = io.WriteString(w, c.headingFunc("comment", 3))
+			_, _ = io.WriteString(w, c.textMD(b.Text))
 			_, _ = w.Write(mdNewline)
+		case *comment.Code:
+			c.codeMD(w, b.Text)
+		case *comment.List:
+			c.listMD(w, b)
 		}
 	}
 }
 
-func blocks(text string) []block {
-	var (
-		out  []block
-		para []string
+// codeMD renders a code block of a doc comment as a fenced code block,
+// in the language set with WithCodeLanguage, with a fence longer than the
+// runs of backticks of the code, and its tabs expanded to the tab width,
+// which the renderers would otherwise expand to 8 columns.
+func (c *Converter) codeMD(w io.Writer, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	info := ""
+	if c.codeLang != "" {
+		info = " " + c.codeLang
+	}
+	_, _ = io.WriteString(w, "\n"+fence+info+"\n"+expandTabs(text, c.tabWidth)+fence+"\n\n")
+}
 
-		lastWasBlank   = false
-		lastWasHeading = false
-	)
+// expandTabs replaces the tabs of the lines of text with the spaces up to
+// the next multiple of width columns, unless width isn't positive.
+func expandTabs(text string, width int) string {
+	if width <= 0 || !strings.Contains(text, "\t") {
+		return text
+	}
+	var buf strings.Builder
+	col := 0
+	for _, r := range text {
+		switch r {
+		case '\t':
+			n := width - col%width
+			buf.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			buf.WriteRune(r)
+			col = 0
+		default:
+			buf.WriteRune(r)
+			col++
+		}
+	}
+	return buf.String()
+}
 
+// paragraphMD renders the text of a paragraph, line by line. Image lines
+// split the paragraph. The first sentence of a lead paragraph is styled,
+// see leadMD.
+func (c *Converter) paragraphMD(w io.Writer, text []comment.Text, lead bool) {
+	raw := strings.Split(plainText(text), "\n")
+	var md []string
+	if lead {
+		md = strings.Split(c.leadMD(text), "\n")
+	} else {
+		md = strings.Split(c.textMD(text), "\n")
+	}
+	if len(raw) != len(md) {
+		// a link spans several lines: don't look for images
+		raw = make([]string, len(md))
+	}
+	var para []string
 	close := func() {
+		for i, line := range para {
+			if i < len(para)-1 {
+				line = c.lineBreak(line)
+			}
+			_, _ = io.WriteString(w, line)
+		}
 		if para != nil {
-			out = append(out, block{opPara, para})
+			_, _ = w.Write(mdNewline) // trailing newline to emulate </p>
 			para = nil
 		}
 	}
-
-	lines := strings.SplitAfter(text, "\n")
-	unindent(lines)
-	for i := 0; i < len(lines); {
-		line := lines[i]
-		if isBlank(line) {
-			// close paragraph
+	for i, line := range md {
+		if m := imageRx.FindStringSubmatch(raw[i]); m != nil {
 			close()
-			i++
-			lastWasBlank = true
+			ref := m[1]
+			alt := strings.TrimSuffix(pathpkg.Base(ref), pathpkg.Ext(ref))
+			_, _ = io.WriteString(w, "!["+alt+"]("+c.assetLink(ref)+")")
+			_, _ = w.Write(mdNewline)
+			_, _ = w.Write(mdNewline)
 			continue
 		}
-		if indentLen(line) > 0 {
-			// close paragraph
-			close()
+		if line != "" || i < len(md)-1 {
+			para = append(para, line+"\n")
+		}
+	}
+	close()
+}
+
+// lineBreak ends a paragraph line with a hard line break, when the line
+// breaks of doc comments are preserved rather than reflowed.
+func (c *Converter) lineBreak(line string) string {
+	switch c.lineBreaks {
+	case "spaces":
+		return strings.TrimRight(line, " \t\n") + "  \n"
+	case "br":
+		if c.dialect.jsx {
+			return strings.TrimRight(line, " \t\n") + "<br/>\n"
+		}
+		return strings.TrimRight(line, " \t\n") + "<br>\n"
+	}
+	return line
+}
 
-			// count indented or blank lines
-			j := i + 1
-			for j < len(lines) && (isBlank(lines[j]) || indentLen(lines[j]) > 0) {
-				j++
+// listMD renders a bullet or numbered list.
+func (c *Converter) listMD(w io.Writer, list *comment.List) {
+	for i, item := range list.Items {
+		if i > 0 && list.BlankBetween() {
+			_, _ = w.Write(mdNewline)
+		}
+		marker := "* "
+		if item.Number != "" {
+			marker = item.Number + ". "
+		}
+		indent := strings.Repeat(" ", len(marker))
+		for j, b := range item.Content {
+			p, ok := b.(*comment.Paragraph)
+			if !ok {
+				continue
 			}
-			// but not trailing blank lines
-			for j > i && isBlank(lines[j-1]) {
-				j--
+			if j > 0 {
+				_, _ = w.Write(mdNewline)
 			}
-			pre := lines[i:j]
-			i = j
-
-			unindent(pre)
+			for k, line := range strings.Split(c.textMD(p.Text), "\n") {
+				if k == 0 && j == 0 {
+					line = marker + line
+				} else if line != "" {
+					line = indent + line
+				}
+				_, _ = io.WriteString(w, line)
+				_, _ = w.Write(mdNewline)
+			}
+		}
+	}
+	_, _ = w.Write(mdNewline)
+}
 
-			// put those lines in a pre block
-			out = append(out, block{opPre, pre})
-			lastWasHeading = false
-			continue
+// plainText returns the text of a doc comment span, as written.
+func plainText(text []comment.Text) string {
+	var buf bytes.Buffer
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			buf.WriteString(string(t))
+		case comment.Italic:
+			buf.WriteString(string(t))
+		case *comment.Link:
+			buf.WriteString(plainText(t.Text))
+		case *comment.DocLink:
+			buf.WriteString(plainText(t.Text))
 		}
+	}
+	return buf.String()
+}
 
-		if lastWasBlank && !lastWasHeading && i+2 < len(lines) &&
-			isBlank(lines[i+1]) && !isBlank(lines[i+2]) && indentLen(lines[i+2]) == 0 {
-			// current line is non-blank, surrounded by blank lines
-			// and the next non-blank line is not indented: this
-			// might be a heading.
-			if head := heading(line); head != "" {
-				close()
-				out = append(out, block{opHead, []string{head}})
-				i += 2
-				lastWasHeading = true
+// textMD renders a doc comment span as Markdown. Plain URLs are written
+// as HTML links, like emphasize does; explicit links and doc links
+// become Markdown links. The identifiers mentioned in the text may be
+// quoted or linked, see identsMD.
+func (c *Converter) textMD(text []comment.Text) string {
+	var buf bytes.Buffer
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			// the code spans are written as is, without escapes nor links
+			for i, s := range codeSpans(string(t)) {
+				if i%2 == 1 {
+					buf.WriteString(s)
+				} else if s != "" {
+					buf.WriteString(c.plainMD(s))
+				}
+			}
+		case comment.Italic:
+			buf.WriteString("*" + string(t) + "*")
+		case *comment.Link:
+			if t.Auto {
+				buf.Write(htmlA)
+				template.HTMLEscape(&buf, []byte(t.URL))
+				buf.Write(htmlAq)
+				buf.WriteString(c.textMD(t.Text))
+				buf.Write(htmlEnda)
 				continue
 			}
+			buf.WriteString("[" + c.textMD(t.Text) + "](" + t.URL + ")")
+		case *comment.DocLink:
+			buf.WriteString("[" + c.textMD(t.Text) + "](" + c.docLinkURL(t) + ")")
 		}
-
-		// open paragraph
-		lastWasBlank = false
-		lastWasHeading = false
-		para = append(para, lines[i])
-		i++
 	}
-	close()
+	return buf.String()
+}
 
-	return out
+// docLinkURL returns the URL of the doc link t, like DefaultURL, but for
+// the constants, the variables and the struct fields of the package being
+// converted, which have no anchors of their own: the anchor of their
+// section, or of their type.
+func (c *Converter) docLinkURL(t *comment.DocLink) string {
+	url := t.DefaultURL(c.pkgLinkBase)
+	if t.ImportPath != "" || c.pdoc == nil {
+		return url
+	}
+	valuesURL := func(values []*doc.Value, anchor string) (string, bool) {
+		for _, v := range values {
+			for _, name := range v.Names {
+				if name == t.Name {
+					return "#" + anchor, true
+				}
+			}
+		}
+		return "", false
+	}
+	if u, ok := valuesURL(c.pdoc.Consts, "pkg-constants"); ok && t.Recv == "" {
+		return u
+	}
+	if u, ok := valuesURL(c.pdoc.Vars, "pkg-variables"); ok && t.Recv == "" {
+		return u
+	}
+	for _, typ := range c.pdoc.Types {
+		if t.Recv == "" {
+			u, ok := valuesURL(typ.Consts, typ.Name)
+			if !ok {
+				u, ok = valuesURL(typ.Vars, typ.Name)
+			}
+			if ok {
+				return u
+			}
+			continue
+		}
+		if typ.Name != t.Recv {
+			continue
+		}
+		for _, m := range typ.Methods {
+			if m.Name == t.Name {
+				return url
+			}
+		}
+		return "#" + typ.Name // a field
+	}
+	return url
 }
@@ -0,0 +1,118 @@
+package godoc2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLineBreaks(t *testing.T) {
+	text := "First line\nsecond line.\n\n\n\tcode\n\tmore code\n"
+	testData := []struct {
+		style    string
+		expected string
+	}{
+		{"", "First line\nsecond line.\n\n\n```\ncode\nmore code\n```\n\n"},
+		{"spaces", "First line  \nsecond line.\n\n\n```\ncode\nmore code\n```\n\n"},
+		{"br", "First line<br>\nsecond line.\n\n\n```\ncode\nmore code\n```\n\n"},
+	}
+	for _, tt := range testData {
+		c := Converter{lineBreaks: tt.style}
+		var buf bytes.Buffer
+		c.toMD(&buf, text)
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("toMD(%q): expected %q, got %q", tt.style, tt.expected, got)
+		}
+	}
+}
+
+func TestToMD(t *testing.T) {
+	testData := []struct {
+		text     string
+		expected string
+	}{
+		{"# Heading\n\nText.\n", "### Heading\nText.\n\n"},
+		{"Items:\n  - one\n  - two\n", "Items:\n\n* one\n* two\n\n"},
+		{"Steps:\n 1. first\n    line\n 2. second\n", "Steps:\n\n1. first\n   line\n2. second\n\n"},
+		{"See [io.Reader].\n", "See [io.Reader](https://pkg.go.dev/io#Reader).\n\n"},
+		{"See [the spec].\n\n[the spec]: https://go.dev/ref/spec\n", "See [the spec](https://go.dev/ref/spec).\n\n"},
+		{"Visit https://go.dev.\n", "Visit <a href=\"https://go.dev\">https://go.dev</a>.\n\n"},
+		{"Text.\nImage: arch.png\nMore.\n", "Text.\n\n![arch](arch.png)\n\nMore.\n\n"},
+		{"Run:\n\n\tfor {\n\t\tget(\"https://go.dev\") // a\tb\n\t}\n", "Run:\n\n\n```\nfor {\n    get(\"https://go.dev\") // a  b\n}\n```\n\n"},
+		{"Quote:\n\n\t```\n\tx\n\t```\n", "Quote:\n\n\n````\n```\nx\n```\n````\n\n"},
+	}
+	for _, tt := range testData {
+		var buf bytes.Buffer
+		ToMD(&buf, tt.text)
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("ToMD(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestLead(t *testing.T) {
+	testData := []struct {
+		text     string
+		expected string
+	}{
+		{"Foo does it. It is fast.\n", "**Foo does it.** It is fast.\n\n"},
+		{"Foo does it\nwell. See [io.Reader].\n", "**Foo does it\nwell.** See [io.Reader](https://pkg.go.dev/io#Reader).\n\n"},
+		{"Foo, as in [io.Reader]\n", "**Foo, as in [io.Reader](https://pkg.go.dev/io#Reader)**\n\n"},
+		{"Ask F. Bar. Then more.\n\nSecond. Paragraph.\n", "**Ask F. Bar.** Then more.\n\nSecond. Paragraph.\n\n"},
+	}
+	for _, tt := range testData {
+		c := Converter{lead: "bold", pkgLinkBase: defaultPkgLinkBase}
+		var buf bytes.Buffer
+		c.toMD(&buf, tt.text)
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("toMD(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestCommentToMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CommentToMarkdown(&buf, "Text {x}.\n", WithDialect("mdx"), WithLead("italic")); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := buf.String(), "*Text \\{x\\}.*\n\n"; got != expected {
+		t.Errorf("CommentToMarkdown: expected %q, got %q", expected, got)
+	}
+	buf.Reset()
+	if err := CommentToMarkdown(&buf, "Use `{x}` or {y}:\n\n\tf(x)\n", WithDialect("mdx"), WithCodeLanguage("go")); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := buf.String(), "Use `{x}` or \\{y\\}:\n\n\n``` go\nf(x)\n```\n\n"; got != expected {
+		t.Errorf("CommentToMarkdown: expected %q, got %q", expected, got)
+	}
+	if err := CommentToMarkdown(&buf, "Text.\n", WithDialect("unknown")); err == nil {
+		t.Error("CommentToMarkdown: expected an error")
+	}
+}
+
+func TestDeprecationMD(t *testing.T) {
+	var buf bytes.Buffer
+	text := "Old does it.\n\nDeprecated: use New\ninstead.\n"
+	if err := CommentToMarkdown(&buf, text, WithDeprecated(true)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Old does it.\n\n> [!CAUTION]\n> **Deprecated:** use New\n> instead.\n\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("CommentToMarkdown: expected %q, got %q", expected, got)
+	}
+}
+
+func TestMarkupDoc(t *testing.T) {
+	text := "See [T.M] and [io.Reader], *p.\n\n  x := 1\n"
+	syms := map[string]bool{"T": true, "T.M": true}
+	tests := []struct {
+		format, expected string
+	}{
+		{"asciidoc", "See <<T.M,T.M>> and link:https://pkg.go.dev/io#Reader[io.Reader], {asterisk}p.\n\n----\nx := 1\n----\n\n"},
+		{"rst", "See `T.M <T.M_>`__ and `io.Reader <https://pkg.go.dev/io#Reader>`__, \\*p.\n\n.. code-block:: text\n\n   x := 1\n\n"},
+	}
+	for _, test := range tests {
+		if got := markupDoc(markups[test.format], text, syms, defaultPkgLinkBase); got != test.expected {
+			t.Errorf("markupDoc %s: expected %q, got %q", test.format, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package godoc2md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// concurrencyRx matches the sentences of a doc comment stating whether
+// a type is safe for concurrent use, by multiple goroutines.
+var concurrencyRx = regexp.MustCompile(`(?i)\b(not\s+)?safe\s+for\s+concurrent\s+use\b`)
+
+// concurrencyFunc returns "safe" or "not safe" as stated in the doc
+// comment, or the empty string.
+func concurrencyFunc(doc string) string {
+	m := concurrencyRx.FindStringSubmatch(doc)
+	switch {
+	case m == nil:
+		return ""
+	case m[1] != "":
+		return "not safe"
+	}
+	return "safe"
+}
+
+// concurrencyBadgeFunc returns a badge showing whether the type
+// documented by doc is safe for concurrent use, followed by a blank line,
+// with WithConcurrencyBadges.
+func (c *Converter) concurrencyBadgeFunc(doc string) string {
+	safety := concurrencyFunc(doc)
+	if safety == "" || !c.concurrencyBadges {
+		return ""
+	}
+	color := "brightgreen"
+	if safety != "safe" {
+		color = "orange"
+	}
+	badge := strings.Replace(safety, " ", "_", -1)
+	return fmt.Sprintf("![Concurrency: %s](https://img.shields.io/badge/concurrency-%s-%s.svg)\n\n", safety, badge, color)
+}
@@ -0,0 +1,22 @@
+package godoc2md
+
+import "testing"
+
+func TestConcurrencyBadge(t *testing.T) {
+	testData := []struct {
+		doc      string
+		show     bool
+		expected string
+	}{
+		{"T is safe for concurrent use.\n", false, ""},
+		{"T is safe for concurrent use.\n", true, "![Concurrency: safe](https://img.shields.io/badge/concurrency-safe-brightgreen.svg)\n\n"},
+		{"T is not safe for concurrent use.\n", true, "![Concurrency: not safe](https://img.shields.io/badge/concurrency-not_safe-orange.svg)\n\n"},
+		{"T is a t.\n", true, ""},
+	}
+	for n, tt := range testData {
+		c := &Converter{concurrencyBadges: tt.show}
+		if actual := c.concurrencyBadgeFunc(tt.doc); actual != tt.expected {
+			t.Errorf("%d: concurrencyBadgeFunc(%q) = %q, expected %q", n, tt.doc, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// flagTypes are the types of the values of the flags, by the name of the
+// function of flag or pflag defining them, without its Var and P
+// suffixes.
+var flagTypes = map[string]string{
+	"String": "string", "Bool": "bool", "Int": "int", "Int8": "int8", "Int16": "int16", "Int32": "int32", "Int64": "int64",
+	"Uint": "uint", "Uint8": "uint8", "Uint16": "uint16", "Uint32": "uint32", "Uint64": "uint64",
+	"Float32": "float32", "Float64": "float64", "Duration": "time.Duration", "Count": "int",
+	"StringSlice": "[]string", "StringArray": "[]string", "IntSlice": "[]int", "BoolSlice": "[]bool",
+	"DurationSlice": "[]time.Duration", "StringToString": "map[string]string", "IP": "net.IP",
+}
+
+// configTypes are the types of the values of the keys read by viper and
+// koanf, by the name of the method reading them, without the Get prefix
+// of viper and the Must prefix of koanf.
+var configTypes = map[string]string{
+	"": "any", "Get": "any", "String": "string", "Bool": "bool", "Int": "int", "Int32": "int32", "Int64": "int64",
+	"Uint": "uint", "Uint32": "uint32", "Uint64": "uint64", "Float64": "float64",
+	"Duration": "time.Duration", "Time": "time.Time", "SizeInBytes": "uint",
+	"StringSlice": "[]string", "Strings": "[]string", "IntSlice": "[]int", "Ints": "[]int", "Bools": "[]bool",
+	"StringMap": "map[string]any", "StringMapString": "map[string]string",
+}
+
+// A configKey is a flag or a configuration key read by a package: its
+// name, flags with their dashes, the package reading it, its type, its
+// default value and its description.
+type configKey struct {
+	key, source     string
+	typ, def, descr string
+	pos             token.Pos
+}
+
+// fileConfigKeys returns the flags defined with flag and pflag, including
+// those of cobra commands, and the keys read and defaulted with viper and
+// koanf, by the calls of file, a key for each call. Their names are
+// string literals or the constants consts, and their descriptions are the
+// usages of the flags, or the comments before or at the end of the line
+// of the calls.
+func fileConfigKeys(fset *token.FileSet, file *ast.File, consts map[string]string) []configKey {
+	imports := make(map[string]bool)
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		switch {
+		case path == "flag":
+			imports["flag"] = true
+		case path == "github.com/spf13/pflag" || path == "github.com/spf13/cobra":
+			imports["pflag"] = true
+		case path == "github.com/spf13/viper":
+			imports["viper"] = true
+		case strings.HasPrefix(path, "github.com/knadh/koanf"):
+			imports["koanf"] = true
+		}
+	}
+	str := func(x ast.Expr) (string, bool) {
+		if id, ok := x.(*ast.Ident); ok {
+			s, ok := consts[id.Name]
+			return s, ok
+		}
+		return stringLit(x)
+	}
+	comment := func(pos token.Pos) string {
+		line := fset.Position(pos).Line
+		for _, cg := range file.Comments {
+			if end := fset.Position(cg.End()).Line; end == line-1 || fset.Position(cg.Pos()).Line == line && cg.Pos() > pos {
+				return strings.Join(strings.Fields(cg.Text()), " ")
+			}
+		}
+		return ""
+	}
+
+	var keys []configKey
+	add := func(k configKey) {
+		if k.descr == "" {
+			k.descr = comment(k.pos)
+		}
+		keys = append(keys, k)
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		name, args := sel.Sel.Name, call.Args
+		recv, _ := sel.X.(*ast.Ident)
+
+		// koanf defaults: confmap.Provider(map[string]interface{}{...}, ".")
+		if imports["koanf"] && name == "Provider" && recv != nil && recv.Name == "confmap" {
+			if m, ok := args[0].(*ast.CompositeLit); ok {
+				for _, elt := range m.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := str(kv.Key); ok {
+							add(configKey{key: key, source: "koanf", def: types.ExprString(kv.Value), pos: kv.Pos()})
+						}
+					}
+				}
+			}
+			return true
+		}
+
+		if imports["flag"] || imports["pflag"] {
+			base, offset, short := name, 0, false
+			if strings.HasSuffix(base, "P") && imports["pflag"] {
+				base, short = strings.TrimSuffix(base, "P"), true
+			}
+			if strings.HasSuffix(base, "Var") {
+				base, offset = strings.TrimSuffix(base, "Var"), 1
+			}
+			usage := offset + 2
+			if short {
+				usage++
+			}
+			if base == "" && offset == 1 { // Var(value, name, usage) and VarP
+				usage--
+			}
+			if typ, ok := flagTypes[base]; (ok || base == "" && offset == 1) && len(args) == usage+1 {
+				key, ok := str(args[offset])
+				descr, ok2 := str(args[usage])
+				if ok && ok2 && key != "" {
+					source, dashes := "flag", "-"
+					if imports["pflag"] && (recv == nil || recv.Name != "flag" || !imports["flag"]) {
+						source, dashes = "pflag", "--"
+					}
+					k := configKey{key: dashes + key, source: source, typ: typ, descr: descr, pos: call.Pos()}
+					if base == "" {
+						k.typ = "value"
+					} else {
+						k.def = types.ExprString(args[usage-1])
+					}
+					add(k)
+					return true
+				}
+			}
+		}
+
+		key, ok := str(args[0])
+		if !ok || key == "" {
+			return true
+		}
+		switch {
+		case imports["viper"] && name == "SetDefault" && len(args) == 2:
+			add(configKey{key: key, source: "viper", def: types.ExprString(args[1]), pos: call.Pos()})
+		case imports["viper"] && (name == "BindEnv" || name == "BindPFlag"):
+			add(configKey{key: key, source: "viper", pos: call.Pos()})
+		case imports["viper"] && strings.HasPrefix(name, "Get") && len(args) == 1:
+			if typ, ok := configTypes[strings.TrimPrefix(name, "Get")]; ok {
+				add(configKey{key: key, source: "viper", typ: typ, pos: call.Pos()})
+			}
+		case imports["koanf"] && len(args) == 1:
+			if typ, ok := configTypes[strings.TrimPrefix(name, "Must")]; ok {
+				add(configKey{key: key, source: "koanf", typ: typ, pos: call.Pos()})
+			}
+		}
+		return true
+	})
+	return keys
+}
+
+// configurationMdFunc returns the Configuration section of the package of
+// info, set with WithConfigKeys: the flags and the configuration keys it
+// defines and reads, found in its sources, sorted by source and key,
+// with their types, defaults and descriptions, or the empty string if it
+// has none.
+func (c *Converter) configurationMdFunc(info *godoc.PageInfo) string {
+	if !c.configKeys || info.PDoc == nil {
+		return ""
+	}
+	fset, files := parseFileSet(c.fs, info.Dirname)
+	consts := stringConsts(files)
+	var keys []configKey
+	index := make(map[string]int) // of the keys, by source and key
+	for _, file := range files {
+		for _, k := range fileConfigKeys(fset, file, consts) {
+			i, ok := index[k.source+" "+k.key]
+			if !ok {
+				index[k.source+" "+k.key] = len(keys)
+				keys = append(keys, k)
+				continue
+			}
+			prev := &keys[i]
+			if prev.typ == "" || prev.typ == "any" {
+				prev.typ = k.typ
+			}
+			if prev.def == "" {
+				prev.def = k.def
+			}
+			if prev.descr == "" {
+				prev.descr = k.descr
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sources := map[string]int{"flag": 0, "pflag": 1, "viper": 2, "koanf": 3}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return sources[keys[i].source] < sources[keys[j].source]
+		}
+		return keys[i].key < keys[j].key
+	})
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + strings.Replace(s, "|", "\\|", -1) + "`"
+	}
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-configuration") + c.headingFunc("section", 2) + "<a name=\"pkg-configuration\">Configuration</a>\n\n")
+	buf.WriteString("| Key | Source | Type | Default | Description |\n| --- | --- | --- | --- | --- |\n")
+	for _, k := range keys {
+		buf.WriteString("| " + code(k.key) + " | " + k.source + " | " + code(k.typ) + " | " + code(k.def) + " | " + strings.Replace(c.mdFunc(k.descr), "|", "\\|", -1) + " |\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
@@ -0,0 +1,97 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestFileConfigKeys(t *testing.T) {
+	testData := []struct {
+		src      string
+		expected []string
+	}{
+		{`package p
+
+import (
+	"flag"
+	"time"
+)
+
+const addrFlag = "addr"
+
+var (
+	addr    = flag.String(addrFlag, ":8080", "listen address")
+	timeout time.Duration
+	level   levelValue
+)
+
+func init() {
+	flag.DurationVar(&timeout, "timeout", 5*time.Second, "request timeout")
+	flag.Var(&level, "level", "log level")
+	flag.Int(dynamic, 1, "ignored")
+}
+`, []string{
+			"-addr flag string \":8080\" listen address",
+			"-timeout flag time.Duration 5 * time.Second request timeout",
+			"-level flag value  log level",
+		}},
+		{`package p
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	cmd.Flags().StringP("config", "c", "", "config file")
+	cmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "verbose output")
+	viper.SetDefault("db.host", "localhost")
+	// port of the database
+	port := viper.GetInt("db.port")
+	viper.BindEnv("db.password") // read from DB_PASSWORD
+}
+`, []string{
+			"--config pflag string \"\" config file",
+			"--verbose pflag bool false verbose output",
+			"db.host viper  \"localhost\" ",
+			"db.port viper int  port of the database",
+			"db.password viper   read from DB_PASSWORD",
+		}},
+		{`package p
+
+import (
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+)
+
+func load(k *koanf.Koanf) {
+	k.Load(confmap.Provider(map[string]interface{}{
+		"server.port": 8080,
+	}, "."), nil)
+	_ = k.MustString("server.name")
+	_ = k.Strings("server.hosts")
+}
+`, []string{
+			"server.port koanf  8080 ",
+			"server.name koanf string  ",
+			"server.hosts koanf []string  ",
+		}},
+	}
+	for n, tt := range testData {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p.go", tt.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, k := range fileConfigKeys(fset, file, stringConsts([]*ast.File{file})) {
+			got = append(got, k.key+" "+k.source+" "+k.typ+" "+k.def+" "+k.descr)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d: fileConfigKeys: expected %q, got %q", n, tt.expected, got)
+		}
+	}
+}
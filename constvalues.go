@@ -0,0 +1,181 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// usesIota reports whether the constant declaration decl uses iota.
+func usesIota(decl *ast.GenDecl) bool {
+	found := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// findConstValues returns the values of the constants declared with iota
+// in files, by name. The unexported constants are evaluated too, as the
+// declarations documented by godoc are filtered, which shifts iota. The
+// blocks referring to constants declared later, or to expressions that
+// aren't constant without type checking, are left out.
+func findConstValues(files []*ast.File) map[string]constant.Value {
+	env := make(map[string]constant.Value)
+	values := make(map[string]constant.Value)
+	for _, file := range files {
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				continue
+			}
+			block := evalConstDecl(decl, env)
+			for name, v := range block {
+				env[name] = v
+				if usesIota(decl) {
+					values[name] = v
+				}
+			}
+		}
+	}
+	return values
+}
+
+// evalConstDecl returns the values of the constants of decl, given the
+// values of the constants declared before, or nil if any of them can't
+// be evaluated.
+func evalConstDecl(decl *ast.GenDecl, env map[string]constant.Value) map[string]constant.Value {
+	values := make(map[string]constant.Value)
+	var last []ast.Expr
+	for iota, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			return nil
+		}
+		if len(vs.Values) > 0 {
+			last = vs.Values
+		}
+		for i, name := range vs.Names {
+			if i >= len(last) {
+				return nil
+			}
+			v := evalConst(last[i], int64(iota), values, env)
+			if v == nil {
+				return nil
+			}
+			if name.Name != "_" {
+				values[name.Name] = v
+			}
+		}
+	}
+	return values
+}
+
+// evalConst returns the value of the constant expression x, or nil if it
+// can't be evaluated. Conversions are assumed to preserve the value.
+func evalConst(x ast.Expr, iota int64, block, env map[string]constant.Value) (v constant.Value) {
+	defer func() {
+		// mismatched kinds and divisions by zero panic
+		if recover() != nil {
+			v = nil
+		}
+	}()
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		if v := constant.MakeFromLiteral(x.Value, x.Kind, 0); v.Kind() != constant.Unknown {
+			return v
+		}
+	case *ast.Ident:
+		switch x.Name {
+		case "iota":
+			return constant.MakeInt64(iota)
+		case "true", "false":
+			return constant.MakeBool(x.Name == "true")
+		}
+		if v, ok := block[x.Name]; ok {
+			return v
+		}
+		return env[x.Name]
+	case *ast.ParenExpr:
+		return evalConst(x.X, iota, block, env)
+	case *ast.CallExpr:
+		if len(x.Args) == 1 {
+			return evalConst(x.Args[0], iota, block, env)
+		}
+	case *ast.UnaryExpr:
+		if v := evalConst(x.X, iota, block, env); v != nil {
+			return constant.UnaryOp(x.Op, v, 0)
+		}
+	case *ast.BinaryExpr:
+		a, b := evalConst(x.X, iota, block, env), evalConst(x.Y, iota, block, env)
+		if a == nil || b == nil {
+			return nil
+		}
+		if x.Op == token.SHL || x.Op == token.SHR {
+			s, ok := constant.Uint64Val(b)
+			if !ok {
+				return nil
+			}
+			return constant.Shift(a, x.Op, uint(s))
+		}
+		if a.Kind() != b.Kind() && (!isNumeric(a) || !isNumeric(b)) {
+			// such as a string and an int, which constant doesn't reject
+			return nil
+		}
+		switch x.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(a, x.Op, b))
+		case token.QUO:
+			if a.Kind() == constant.Int && b.Kind() == constant.Int {
+				// integer division
+				return constant.BinaryOp(a, token.QUO_ASSIGN, b)
+			}
+		}
+		return constant.BinaryOp(a, x.Op, b)
+	}
+	return nil
+}
+
+// isNumeric reports whether v is an integer, floating-point or complex
+// constant.
+func isNumeric(v constant.Value) bool {
+	k := v.Kind()
+	return k == constant.Int || k == constant.Float || k == constant.Complex
+}
+
+// constValuesMdFunc returns a table of the values of the constants of
+// decl declared with iota, when enabled, or the empty string. The iota
+// of decl may have been filtered out with the unexported constants.
+func (c *Converter) constValuesMdFunc(info *godoc.PageInfo, decl *ast.GenDecl) string {
+	if !c.constValues || decl == nil || decl.Tok != token.CONST {
+		return ""
+	}
+	if c.constValuesByName == nil {
+		c.constValuesByName = findConstValues(parseFiles(c.fs, info.Dirname))
+	}
+	var buf strings.Builder
+	for _, spec := range decl.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			v, ok := c.constValuesByName[name.Name]
+			if !ok {
+				continue
+			}
+			s := v.ExactString()
+			if v.Kind() == constant.Float {
+				s = v.String()
+			}
+			s = strings.Replace(s, "|", "\\|", -1)
+			buf.WriteString("| `" + name.Name + "` | `" + s + "` |\n")
+		}
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return "\n| Constant | Value |\n| --- | --- |\n" + buf.String() + "\n"
+}
@@ -0,0 +1,60 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestFindConstValues(t *testing.T) {
+	src := `package p
+
+const Base = 10
+
+const (
+	_ Flag = 1 << iota
+	A
+	B
+	C = A | B
+)
+
+const (
+	X = Base + iota*2
+	Y
+	Z = iota / 2
+)
+
+const (
+	S = "s" + iota
+	T
+)
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := findConstValues([]*ast.File{file})
+	testData := []struct {
+		name     string
+		expected string
+	}{
+		{"A", "2"},
+		{"B", "4"},
+		{"C", "6"},
+		{"X", "10"},
+		{"Y", "12"},
+		{"Z", "1"},
+		{"Base", ""},
+		{"S", ""},
+	}
+	for _, tt := range testData {
+		got := ""
+		if v, ok := values[tt.name]; ok {
+			got = v.ExactString()
+		}
+		if got != tt.expected {
+			t.Errorf("findConstValues(%s): expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
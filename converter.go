@@ -0,0 +1,953 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc2md
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/doc"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+const (
+	targetPath     = "/target"
+	cmdPathPrefix  = "cmd/"
+	srcPathPrefix  = "src/"
+	toolsPath      = "golang.org/x/tools/cmd/"
+	builtinPkgPath = "builtin"
+
+	// defaultPkgLinkBase is the base URL of the documentation of the
+	// packages linked from doc comments and declarations.
+	defaultPkgLinkBase = "https://pkg.go.dev"
+)
+
+// A Converter renders the documentation of Go packages as Markdown.
+// It is configured once with New; conversions made with the same
+// Converter are serialized.
+type Converter struct {
+	verbose           bool
+	goroot            string
+	tabWidth          int
+	codeLang          string
+	showTimestamps    bool
+	showPlayground    bool
+	templateText      string
+	templatePartials  map[string]string
+	strictTemplates   bool
+	verifyOutput      bool
+	dialectName       string
+	unexported        bool
+	layout            string
+	showExamples      bool
+	typeDiagram       string
+	implementsRefs    bool
+	grpcServices      bool
+	routes            bool
+	observability     bool
+	configKeys        bool
+	localLinks        string
+	schemas           bool
+	dataModel         bool
+	benchmarks        bool
+	benchstatTable    bool
+	tests             bool
+	exampleLevel      int
+	exampleTitle      string
+	exampleTabs       string
+	declLinks         bool
+	localDeclLinks    bool
+	htmlAnchors       bool
+	lineBreaks        string
+	backticks         bool
+	lead              string
+	pkgLinks          bool
+	pkgLinkBase       string
+	groupOpts         bool
+	foldAccess        bool
+	permalinks        bool
+	pkgsiteLinks      bool
+	toc               bool
+	hazards           bool
+	deprecated        bool
+	deprecatedSection bool
+	buildConstraints  bool
+	headingOffset     int
+	headingLevels     map[string]int
+	textFilter        string
+	headerFile        string
+	frontMatterFormat string
+	frontMatterFields map[string]string
+	packageMetas      map[string]PackageMeta
+	assetDir          string
+	outPathFormat     string
+	badges            []string
+	metadata          bool
+	concurrencyBadges bool
+	report            io.Writer
+	reportFormat      string
+	cacheFile         string
+	jobs              int
+	whatsNew          bool
+	previous          []byte
+	cacheSettings     string
+	watchFiles        []string
+	check             bool
+	filters           []string
+	symbolsFile       string
+	redirectsFile     string
+	symbolsOutput     string
+
+	// The hash format for Github is the default `#L%d`; but other source control platforms do not
+	// use the same format. For example Bitbucket Enterprise uses `#%d`. This option provides the
+	// user the option to switch the format as needed and still remain backwards compatible.
+	srcLinkHashFormat string
+	srcLinkFormat     string
+	sourceHosts       []SourceHost
+	excludeRx         []*regexp.Regexp
+	wikiRoot          string
+	outputs           []string
+	excludeFiles      []string
+	excludeSymbols    string
+	excludeSymbolsRx  *regexp.Regexp
+	vanity            bool
+	vanityCache       string
+	vanityPaths       map[string]string // vanity import path prefix -> repository
+	stdlibLinks       string
+	rawLinks          bool
+	embedAssets       bool
+	selections        bool
+	lineRanges        bool
+	constValues       bool
+	constGroups       bool
+	ref               string
+
+	notesRx           string
+	noteTitles        map[string]string
+	noteStyleByMarker map[string]string
+
+	opts             []Option // of New, for the workers of ConvertTree
+	mu               sync.Mutex
+	fs               vfs.NameSpace
+	pres             *godoc.Presentation
+	dialect          dialect
+	tmpl             *template.Template
+	typeTmpl         *template.Template
+	exampleTitleTmpl *template.Template
+	srcLinkTmpl      *template.Template
+	filterRx         *regexp.Regexp
+
+	// pkgDirs maps the import paths resolved by expandPackages to their
+	// directory on disk.
+	pkgDirs map[string]string
+	// symbols are the symbols of the packages converted so far, see
+	// WithSymbols.
+	symbols []Symbol
+
+	// state of the conversion in progress
+	pdoc          *doc.Package
+	pdocDir       string
+	meta          *PackageMeta // see packageMeta
+	idents        map[string]bool
+	hazardsByFunc map[string][]string
+	// constValuesByName holds the values of the constants declared with
+	// iota of the package being converted, computed on first use.
+	constValuesByName map[string]constant.Value
+	// srcFiles holds the files of the package being converted, read
+	// on first use.
+	srcFiles      map[string]*srcFile
+	assetRefs     []string
+	optionFuncs   map[string][]*doc.Func
+	accessorFuncs map[string][]*doc.Func
+	// targetDir is the directory on disk bound at targetPath, see paths.
+	targetDir string
+	// outputDir is the directory of the file being written, the current
+	// directory if empty, that local links are relative to.
+	outputDir string
+	// implementations maps the types of the package being converted to
+	// the interfaces of the package they implement, and the interfaces
+	// to the types implementing them, computed on first use.
+	implementations map[string][]string
+	splitTypes      []*doc.Type // types written to their own page, see ConvertSplit
+	splitIndex      string      // file name of the index page linking them
+	// sections maps the functions and types of the package being
+	// converted to their section directive, and sectionNames lists
+	// the sections in order of appearance, see applyDirectives.
+	sections     map[string]string
+	sectionNames []string
+	// treeOutputs maps the import paths converted by ConvertTree to
+	// their output file.
+	treeOutputs map[string]string
+}
+
+// New returns a Converter configured with opts. Without options, the
+// documentation is rendered the same way as by the godoc2md command run
+// without flags.
+func New(opts ...Option) (*Converter, error) {
+	c := &Converter{
+		goroot:            runtime.GOROOT(),
+		tabWidth:          4,
+		exampleLevel:      5,
+		layout:            "flat",
+		dialectName:       "gfm",
+		exampleTitle:      "Example [{{.Name}}{{.Suffix}}]({{.Link}}):",
+		declLinks:         true,
+		outPathFormat:     "{{.Dir}}/README.md",
+		srcLinkHashFormat: "#L%d",
+		ref:               "master",
+		notesRx:           "BUG",
+		pkgLinkBase:       defaultPkgLinkBase,
+		fs:                vfs.NameSpace{},
+		pkgDirs:           map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.opts = opts
+	if c.stdlibLinks != "" {
+		h, ok := stdlibHosts[c.stdlibLinks]
+		if !ok {
+			return nil, fmt.Errorf("stdlib links: unknown site %q", c.stdlibLinks)
+		}
+		if h.Pattern != nil {
+			c.sourceHosts = append(c.sourceHosts, h)
+		}
+	}
+	c.sourceHosts = append(c.sourceHosts, defaultSourceHosts...)
+
+	// use file system of underlying OS
+	c.fs.Bind("/", vfs.OS(c.goroot), "/", vfs.BindReplace)
+
+	// Bind $GOPATH trees into Go root.
+	for _, p := range filepath.SplitList(build.Default.GOPATH) {
+		c.fs.Bind("/src/pkg", vfs.OS(p), "/src", vfs.BindAfter)
+	}
+
+	corpus := godoc.NewCorpus(c.fs)
+	corpus.Verbose = c.verbose
+
+	c.pres = godoc.NewPresentation(corpus)
+	c.pres.TabWidth = c.tabWidth
+	c.pres.ShowTimestamps = c.showTimestamps
+	c.pres.ShowPlayground = c.showPlayground
+	c.pres.DeclLinks = c.declLinks
+	c.pres.URLForSrcPos = c.srcPosLinkFunc
+	c.pres.URLForSrc = c.urlFromPackage
+	if c.notesRx != "" {
+		rx, err := regexp.Compile(c.notesRx)
+		if err != nil {
+			return nil, fmt.Errorf("notes: %v", err)
+		}
+		c.pres.NotesRx = rx
+	}
+	if c.excludeSymbols != "" {
+		rx, err := regexp.Compile(c.excludeSymbols)
+		if err != nil {
+			return nil, fmt.Errorf("exclude symbols: %v", err)
+		}
+		c.excludeSymbolsRx = rx
+	}
+
+	var err error
+	if c.exampleTitleTmpl, err = template.New("extitle").Parse(c.exampleTitle); err != nil {
+		return nil, fmt.Errorf("example title: %v", err)
+	}
+
+	if strings.Contains(c.srcLinkFormat, "{{") {
+		if c.srcLinkTmpl, err = template.New("srclink").Parse(c.srcLinkFormat); err != nil {
+			return nil, fmt.Errorf("src link format: %v", err)
+		}
+	}
+
+	switch c.exampleTabs {
+	case "", "docusaurus", "mkdocs":
+	default:
+		return nil, fmt.Errorf("tabs: unknown renderer %q", c.exampleTabs)
+	}
+	switch c.localLinks {
+	case "", "relative", "file":
+	default:
+		return nil, fmt.Errorf("local links: unknown mode %q", c.localLinks)
+	}
+	switch c.typeDiagram {
+	case "", "class", "graph":
+	default:
+		return nil, fmt.Errorf("type diagram: unknown kind %q", c.typeDiagram)
+	}
+
+	if err := c.initComments(); err != nil {
+		return nil, err
+	}
+	if c.dialect.plain {
+		// the tabs are made of HTML or nested code blocks, and nothing
+		// renders the diagrams
+		c.exampleTabs, c.typeDiagram = "", ""
+	}
+
+	if _, ok := reportFormats[c.reportFormat]; !ok {
+		return nil, fmt.Errorf("report: unknown format %q", c.reportFormat)
+	}
+
+	if _, ok := frontMatterDelims[c.frontMatterFormat]; !ok && c.frontMatterFormat != "" {
+		return nil, fmt.Errorf("front matter: unknown format %q", c.frontMatterFormat)
+	}
+
+	for marker, style := range c.noteStyleByMarker {
+		if !noteStyles[style] {
+			return nil, fmt.Errorf("note style: unknown style %q for %s", style, marker)
+		}
+	}
+
+	if len(c.filters) > 0 {
+		if c.filterRx, err = makeRx(c.filters); err != nil {
+			return nil, fmt.Errorf("illegal regular expression from %v: %v", c.filters, err)
+		}
+	}
+
+	text := c.templateText
+	if text == "" {
+		if text, err = layoutTemplate(c.layout); err != nil {
+			return nil, err
+		}
+	}
+	if c.tmpl, err = c.readTemplate("package.txt", text); err != nil {
+		return nil, err
+	}
+	if c.typeTmpl, err = c.readTemplate("type.txt", typePageTemplate); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Rebuild returns a new Converter configured with opts, keeping the
+// package directories resolved by c and the outputs of its tree, as for
+// a change of template while watching the packages.
+func (c *Converter) Rebuild(opts ...Option) (*Converter, error) {
+	n, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path, dir := range c.pkgDirs {
+		n.pkgDirs[path] = dir
+	}
+	if c.treeOutputs != nil {
+		n.treeOutputs = make(map[string]string, len(c.treeOutputs))
+		for path, name := range c.treeOutputs {
+			n.treeOutputs[path] = name
+		}
+	}
+	return n, nil
+}
+
+// Convert writes the Markdown documentation of the package importPath to w.
+// The import path may also be a directory, such as "." or "./foo".
+func (c *Converter) Convert(importPath string, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writeOutput(w, importPath, c.assetDir, c.symbolsOutput)
+}
+
+// RenderSnippet executes the template snippet text, which may call the
+// functions of the templates, such as {{comment_md .}}, with input as
+// dot, and writes the result to w, to debug the use of template
+// functions without converting a package. The functions expecting the
+// page of a package as their first argument get none.
+func (c *Converter) RenderSnippet(w io.Writer, text, input string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tmpl, err := c.readTemplate("snippet", text)
+	if err != nil {
+		return err
+	}
+	return c.execute(w, tmpl, input)
+}
+
+// Packages resolves a package pattern, such as "." or "./...", into the
+// import paths of the matching packages, to be passed to Convert.
+func (c *Converter) Packages(pattern string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expandPackages(pattern)
+}
+
+func (c *Converter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"example_md":          c.exampleMdFunc,
+		"example_md_at":       c.exampleMdAtFunc,
+		"other_examples_md":   c.otherExamplesMdFunc,
+		"test_funcs_md":       c.testFuncsMdFunc,
+		"type_diagram_md":     c.typeDiagramMdFunc,
+		"implementations_md":  c.implementationsMdFunc,
+		"schema_md":           c.schemaMdFunc,
+		"data_model_md":       c.dataModelMdFunc,
+		"routes_md":           c.routesMdFunc,
+		"observability_md":    c.observabilityMdFunc,
+		"configuration_md":    c.configurationMdFunc,
+		"grpc_services_md":    c.grpcServicesMdFunc,
+		"has_example":         hasExampleFunc,
+		"position":            positionFunc,
+		"end_position":        endPositionFunc,
+		"file_of":             fileOfFunc,
+		"line_of":             lineOfFunc,
+		"end_line_of":         endLineOfFunc,
+		"example_link":        exampleLinkFunc,
+		"example_id":          c.exampleIDFunc,
+		"show_examples":       func() bool { return c.showExamples },
+		"example_tabs_header": c.exampleTabsHeaderFunc,
+		"comment_md":          c.commentMdFunc,
+		"synopsis_md":         c.synopsisMdFunc,
+		"base":                pathpkg.Base,
+		"md":                  c.mdFunc,
+		"pre":                 preFunc,
+		"decl_md":             c.declMdFunc,
+		"kebab":               c.kebabFunc,
+		"anchor":              c.anchorFunc,
+		"bitscape":            bitscapeFunc, //Escape [] for bitbucket confusion
+		"trim_prefix":         strings.TrimPrefix,
+		"trim":                strings.TrimSpace,
+		"indent":              indentFunc,
+		"replace":             replaceFunc,
+		"regex_match":         regexMatchFunc,
+		"regex_replace":       regexReplaceFunc,
+		"default":             defaultFunc,
+		"join":                joinFunc,
+		"clean_link":          cleanLink,
+		"note_title":          c.noteTitleFunc,
+		"notes_md":            c.notesMdFunc,
+		"permalinks_md":       c.permalinksMdFunc,
+		"options_for":         c.optionsForFunc,
+		"accessors_for":       c.accessorsForFunc,
+		"accessor_row":        c.accessorRowFunc,
+		"has_security":        hasSecurityFunc,
+		"security_md":         c.securityMdFunc,
+		"overview_doc":        overviewDocFunc,
+		"overview_md":         c.overviewMdFunc,
+		"stability":           stabilityFunc,
+		"stability_badge":     stabilityBadgeFunc,
+		"package_title":       c.packageTitleFunc,
+		"package_description": c.packageDescriptionFunc,
+		"package_tags":        c.packageTagsFunc,
+		"concurrency_badge":   c.concurrencyBadgeFunc,
+		"toc_md":              c.tocMdFunc,
+		"subdirs":             c.subdirsFunc,
+		"whats_new_md":        c.whatsNewMdFunc,
+		"badges_md":           c.badgesMdFunc,
+		"file_link":           c.fileLinkFunc,
+		"const_values_md":     c.constValuesMdFunc,
+		"const_groups":        func() bool { return c.constGroups },
+		"snippet":             c.snippetFunc,
+		"import_stmt":         importStmtFunc,
+		"types_index_md":      c.typesIndexMdFunc,
+		"split_index":         func() string { return c.splitIndex },
+		"hazard_md":           c.hazardMdFunc,
+		"build_md":            c.buildMdFunc,
+		"h":                   c.headingFunc,
+		"deprecated_md":       c.deprecatedMdFunc,
+		"has_deprecated":      func(info *godoc.PageInfo) bool { return len(c.deprecatedSymbols(info)) > 0 },
+		"section_funcs":       c.sectionFuncs,
+		"pkgsite_link_md":     c.pkgsiteLinkMdFunc,
+		"section_types":       c.sectionTypes,
+		"decl_sections":       func() []string { return c.sectionNames },
+		"section_id":          sectionIDFunc,
+		strictValueFunc:       strictValue,
+	}
+}
+
+func cleanLink(src string) string {
+	src = strings.ToLower(src)
+	return strings.Replace(src, "_", "", -1)
+}
+
+func (c *Converter) commentMdFunc(comment string) string {
+	var buf bytes.Buffer
+	c.toMD(&buf, stripStability(comment))
+	return buf.String()
+}
+
+// synopsisMdFunc returns the first sentence of a doc comment, as the
+// Markdown of a table cell.
+func (c *Converter) synopsisMdFunc(comment string) string {
+	p := c.pdoc
+	if p == nil {
+		p = new(doc.Package)
+	}
+	text := p.Synopsis(stripStability(comment))
+	return strings.Replace(c.mdFunc(text), "|", "\\|", -1)
+}
+
+// versionSuffixRx matches the major version suffixes of import paths,
+// such as /v2 or, for gopkg.in, .v3, which aren't part of package names.
+var versionSuffixRx = regexp.MustCompile(`(/v[0-9]+|\.v[0-9]+)$`)
+
+// importStmtFunc returns the import statement of pdoc, with the package
+// name as alias when it differs from the last element of the import
+// path, as in import grpc "google.golang.org/grpc". Directories given
+// instead of import paths get no alias.
+func importStmtFunc(pdoc *doc.Package) string {
+	base := pathpkg.Base(versionSuffixRx.ReplaceAllString(pdoc.ImportPath, ""))
+	if base == pdoc.Name || pdoc.Name == "" || isLocalPattern(pdoc.ImportPath) {
+		return `import "` + pdoc.ImportPath + `"`
+	}
+	return `import ` + pdoc.Name + ` "` + pdoc.ImportPath + `"`
+}
+
+func preFunc(text string) string {
+	return "``` go\n" + text + "\n```"
+}
+
+// Original Source https://github.com/golang/tools/blob/master/godoc/godoc.go#L562
+func srcLinkFunc(s string) string {
+	s = pathpkg.Clean("/" + s)
+	if !strings.HasPrefix(s, "/src/") {
+		s = "/src" + s
+	}
+	return s
+}
+
+// Removed code line that always subtracted 10 from the value of `line`.
+// Made format for the source link hash configurable to support source control platforms other than Github.
+// Original Source https://github.com/golang/tools/blob/master/godoc/godoc.go#L540
+func (c *Converter) srcPosLinkFunc(s string, line, low, high int) string {
+	if c.srcLinkTmpl != nil {
+		return c.srcLinkTemplate(s, line, low, high)
+	}
+	if c.srcLinkFormat != "" {
+		return fmt.Sprintf(c.srcLinkFormat, s, line, low, high)
+	}
+
+	hashFormat := c.srcLinkHashFormat
+	// a format with two verbs anchors the range of lines of the
+	// declaration, from line to endLine
+	endLine := 0
+	if strings.Count(hashFormat, "%d") == 2 {
+		endLine = c.lineAt(s, high, line)
+	}
+	selection := ""
+	if c.pdoc != nil {
+		if h, _, ok := sourceHost(c.sourceHosts, c.hostedPath(c.pdoc.ImportPath), c.ref); ok {
+			end := c.lineAt(s, high, line)
+			switch {
+			case c.selections && h.Selection != "" && low < high:
+				// the selection, of the first and the last character,
+				// replaces the line anchors
+				startLine, startCol := c.offsetPosition(s, low)
+				lastLine, lastCol := c.offsetPosition(s, high-1)
+				if startLine == 0 {
+					break
+				}
+				selection = fmt.Sprintf(h.Selection, startLine, startCol, lastLine, lastCol)
+				low, high = 0, 0
+			case c.lineRanges && h.Lines != "" && end > line:
+				// the declaration spans several lines
+				hashFormat, endLine, low, high = h.Lines, end, 0, 0
+			case h.Line != "":
+				// the host has its own line anchors, and no selection ranges
+				hashFormat, endLine, low, high = h.Line, 0, 0, 0
+			}
+		}
+	}
+
+	if c.localLinks != "" {
+		// files opened locally have no selection ranges
+		low, high = 0, 0
+	}
+	if strings.HasPrefix(s, targetPath+"/") {
+		// The package directory is bound at targetPath, and the link
+		// returned by urlFromPackage already points at that directory.
+		s = strings.TrimPrefix(s, targetPath)
+	} else {
+		s = srcLinkFunc(s)
+	}
+	var buf bytes.Buffer
+	template.HTMLEscape(&buf, []byte(s))
+	// selection ranges are of form "s=low:high"
+	if low < high {
+		fmt.Fprintf(&buf, "?s=%d:%d", low, high) // no need for URL escaping
+		if line < 1 {
+			line = 1
+		}
+	}
+	// line id's in html-printed source are of the
+	// form "L%d" (on Github) where %d stands for the line number
+	if selection != "" {
+		buf.WriteString(selection)
+	} else if line > 0 {
+		if endLine > 0 {
+			fmt.Fprintf(&buf, hashFormat, line, endLine)
+		} else {
+			fmt.Fprintf(&buf, hashFormat, line) // no need for URL escaping
+		}
+	}
+	return buf.String()
+}
+
+// sourceLink returns the full source link to the range [pos, end)
+// of a file of the package described by info.
+func (c *Converter) sourceLink(info *godoc.PageInfo, pos, end token.Pos) string {
+	var line, low, high int
+	var filename string
+	if pos.IsValid() {
+		p := info.FSet.Position(pos)
+		filename, line, low = p.Filename, p.Line, p.Offset
+	}
+	if end.IsValid() {
+		high = info.FSet.Position(end).Offset
+	}
+	return c.urlFromPackage(info.PDoc.ImportPath) + c.srcPosLinkFunc(filename, line, low, high)
+}
+
+func (c *Converter) readTemplate(name, data string) (*template.Template, error) {
+	// be explicit with errors (for app engine use)
+	t, err := template.New(name).Funcs(c.pres.FuncMap()).Funcs(c.funcs()).Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("readTemplate: %v", err)
+	}
+	names := make([]string, 0, len(c.templatePartials))
+	for partial := range c.templatePartials {
+		names = append(names, partial)
+	}
+	sort.Strings(names)
+	for _, partial := range names {
+		if _, err := t.New(partial).Parse(c.templatePartials[partial]); err != nil {
+			return nil, fmt.Errorf("readTemplate: %v", err)
+		}
+	}
+	if c.strictTemplates {
+		t.Option("missingkey=error")
+		strictTemplate(t)
+	}
+	return t, nil
+}
+
+// execute executes tmpl with data and writes the result to w, rewritten
+// for the plain dialect, see plainMarkdown. With strict templates, it
+// fails, at the action of the template, on the missing map keys and the
+// missing values, such as the fields of nil interfaces, see
+// strictTemplate, without writing anything.
+func (c *Converter) execute(w io.Writer, tmpl *template.Template, data interface{}) error {
+	if !c.strictTemplates && !c.verifyOutput && !c.dialect.plain {
+		return tmpl.Execute(w, data)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		var noValue *noValueError
+		if errors.As(err, &noValue) {
+			return noValue
+		}
+		return err
+	}
+	if c.dialect.plain {
+		buf = *bytes.NewBufferString(plainMarkdown(buf.String()))
+	}
+	if c.verifyOutput {
+		slug := c.kebabFunc
+		if c.dialect.anchors {
+			slug = nil // headings without IDs
+		}
+		if findings := verifyMarkdown(buf.Bytes(), slug); len(findings) > 0 {
+			return fmt.Errorf("template: %s: the output is broken:\n\t%s", tmpl.Name(), strings.Join(findings, "\n\t"))
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// anchorFunc returns an explicit HTML anchor for id, to be placed on
+// the line before a heading, when HTML anchors are enabled.
+func (c *Converter) anchorFunc(id string) string {
+	if !c.htmlAnchors && !c.dialect.anchors {
+		return ""
+	}
+	return `<a id="` + template.HTMLEscapeString(id) + `"></a>` + "\n"
+}
+
+func bitscapeFunc(text string) string {
+	s := strings.Replace(text, "[", "\\[", -1)
+	s = strings.Replace(s, "]", "\\]", -1)
+	return s
+}
+
+// Rewriting a source file path to its http equivalent and making sure you can
+// add a file a file path after without having to worry about the element that
+// comes between the root of the repository and the repo path
+func (c *Converter) urlFromPackage(src string) string {
+	if c.localLinks != "" && c.pdoc != nil && src == c.pdoc.ImportPath && c.targetDir != "" {
+		return c.localURL(c.targetDir)
+	}
+	if _, url, ok := sourceHost(c.sourceHosts, c.hostedPath(src), c.ref); ok {
+		return url
+	}
+	return fmt.Sprintf("https://golang.org/src/%s", src)
+}
+
+// writeOutput returns godoc results to w.
+// Note that it may add a /target path to fs.
+// The assets referenced by the documentation are copied into assetDir,
+// unless it is empty, and its symbols are recorded as written to name.
+func (c *Converter) writeOutput(w io.Writer, path, assetDir, name string) error {
+	c.outputDir = assetDir
+	if name != "" {
+		c.outputDir = filepath.Dir(name)
+	}
+	info, err := c.pageInfo(w, path)
+	if err != nil {
+		return err
+	}
+	c.regroup(info)
+	if err := c.render(w, c.tmpl, info); err != nil {
+		return err
+	}
+	c.recordSymbols(info, name, c.layout)
+	return c.copyAssets(info.Dirname, assetDir)
+}
+
+// render writes the front matter, the header file and the documentation
+// of info executed with tmpl to w.
+func (c *Converter) render(w io.Writer, tmpl *template.Template, info *godoc.PageInfo) error {
+	if _, err := io.WriteString(w, c.frontMatter(info)); err != nil {
+		return err
+	}
+	if c.headerFile != "" {
+		header, err := ioutil.ReadFile(c.headerFile)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+	}
+	if c.toc && c.dialect.tocMacro != "" {
+		if _, err := io.WriteString(w, c.dialect.tocMacro+"\n\n"); err != nil {
+			return err
+		}
+	}
+	return c.execute(w, tmpl, info)
+}
+
+// regroup moves the functional options and the accessors of the types
+// of info into their own groups, when enabled.
+func (c *Converter) regroup(info *godoc.PageInfo) {
+	if c.groupOpts {
+		c.groupOptions(info)
+	}
+	if c.foldAccess {
+		c.foldAccessors(info)
+	}
+}
+
+// pageInfo returns the documentation of the package or command path,
+// filtered, and resets the per-package state of c. Hints about commands
+// shadowed by packages are written to w.
+func (c *Converter) pageInfo(w io.Writer, path string) (*godoc.PageInfo, error) {
+	c.pdoc, c.idents, c.hazardsByFunc, c.constValuesByName, c.assetRefs = nil, nil, nil, nil, nil
+	c.srcFiles, c.implementations, c.pdocDir, c.meta = nil, nil, "", nil
+	c.sections, c.sectionNames = nil, nil
+	arg := path
+	srcMode := false
+	cmdMode := strings.HasPrefix(path, cmdPathPrefix)
+	if strings.HasPrefix(path, srcPathPrefix) {
+		path = strings.TrimPrefix(path, srcPathPrefix)
+		srcMode = true
+	}
+	var abspath, relpath string
+	if cmdMode {
+		path = strings.TrimPrefix(path, cmdPathPrefix)
+	} else {
+		abspath, relpath = c.paths(path)
+	}
+
+	var mode godoc.PageInfoMode
+	if relpath == builtinPkgPath {
+		// the fake built-in package contains unexported identifiers
+		mode = godoc.NoFiltering | godoc.NoTypeAssoc
+	}
+	if srcMode {
+		// only filter exports if we don't have explicit filter arguments
+		if c.filterRx != nil {
+			mode |= godoc.NoFiltering
+		}
+		mode |= godoc.ShowSource
+	}
+	if c.unexported {
+		mode |= godoc.NoFiltering
+	}
+
+	// First, try as package unless forced as command.
+	var info *godoc.PageInfo
+	if !cmdMode {
+		info = c.pres.GetPkgPageInfo(abspath, relpath, mode)
+	}
+
+	// Second, try as command (if the path is not absolute).
+	var cinfo *godoc.PageInfo
+	if !filepath.IsAbs(path) {
+		// First try go.tools/cmd.
+		abspath = pathpkg.Join(c.pres.PkgFSRoot(), toolsPath+path)
+		cinfo = c.pres.GetCmdPageInfo(abspath, relpath, mode)
+		if cinfo.IsEmpty() {
+			// Then try $GOROOT/src/cmd.
+			abspath = pathpkg.Join(c.pres.CmdFSRoot(), cmdPathPrefix, path)
+			cinfo = c.pres.GetCmdPageInfo(abspath, relpath, mode)
+		}
+	}
+
+	// determine what to use
+	if info == nil || info.IsEmpty() {
+		if cinfo != nil && !cinfo.IsEmpty() {
+			// only cinfo exists - switch to cinfo
+			info = cinfo
+		}
+	} else if cinfo != nil && !cinfo.IsEmpty() {
+		// both info and cinfo exist - use cinfo if info
+		// contains only subdirectory information
+		if info.PAst == nil && info.PDoc == nil {
+			info = cinfo
+		} else if relpath != targetPath {
+			// The above check handles the case where an operating system path
+			// is provided (see documentation for paths below).  In that case,
+			// relpath is set to "/target" (in anticipation of accessing packages there),
+			// and is therefore not expected to match a command.
+			fmt.Fprintf(w, "use 'godoc %s%s' for documentation on the %s command \n\n", cmdPathPrefix, relpath, relpath)
+		}
+	}
+
+	if info == nil {
+		return nil, fmt.Errorf("%s: no such directory or package", arg)
+	}
+	if info.Err != nil {
+		return nil, info.Err
+	}
+
+	if info.PDoc != nil && info.PDoc.ImportPath == targetPath {
+		// Replace virtual /target with actual argument from command line.
+		info.PDoc.ImportPath = arg
+	}
+
+	// If we have filters, use them to select the documented nodes.
+	if c.filterRx != nil {
+		info.IsFiltered = true
+		filterInfo(c.filterRx, info)
+	}
+	c.addSkippedExamples(info)
+	c.excludeDecls(info)
+	c.applyDirectives(info)
+
+	c.pdoc, c.pdocDir = info.PDoc, info.Dirname
+	return info, nil
+}
+
+// paths determines the paths to use.
+//
+// If we are passed an operating system path like . or ./foo or /foo/bar or c:\mysrc,
+// we need to map that path somewhere in the fs name space so that routines
+// like getPageInfo will see it.  We use the arbitrarily-chosen virtual path "/target"
+// for this.  That is, if we get passed a directory like the above, we map that
+// directory so that getPageInfo sees it as /target.
+// Returns the absolute and relative paths.
+func (c *Converter) paths(path string) (abspath, relpath string) {
+	c.targetDir = ""
+	if filepath.IsAbs(path) {
+		c.fs.Bind(targetPath, vfs.OS(path), "/", vfs.BindReplace)
+		c.targetDir = path
+		return targetPath, targetPath
+	}
+	if build.IsLocalImport(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			log.Printf("error while getting working directory: %v", err)
+		}
+		path = filepath.Join(cwd, path)
+		c.fs.Bind(targetPath, vfs.OS(path), "/", vfs.BindReplace)
+		c.targetDir = path
+		return targetPath, targetPath
+	}
+	if dir, ok := c.pkgDirs[path]; ok {
+		// resolved with the go command, see expandPackages
+		c.fs.Bind(targetPath, vfs.OS(dir), "/", vfs.BindReplace)
+		c.targetDir = dir
+		return targetPath, path
+	}
+	bp, err := build.Import(path, "", build.FindOnly)
+	if err != nil {
+		log.Printf("error while importing build package: %v", err)
+	}
+	if bp.Dir != "" && bp.ImportPath != "" {
+		c.fs.Bind(targetPath, vfs.OS(bp.Dir), "/", vfs.BindReplace)
+		c.targetDir = bp.Dir
+		return targetPath, bp.ImportPath
+	}
+	return pathpkg.Join(c.pres.PkgFSRoot(), path), path
+}
+
+// filterInfo updates info to include only the nodes that match rx.
+func filterInfo(rx *regexp.Regexp, info *godoc.PageInfo) {
+	filter := func(s string) bool { return rx.MatchString(s) }
+	switch {
+	case info.PAst != nil:
+		newPAst := map[string]*ast.File{}
+		for name, a := range info.PAst {
+			cmap := ast.NewCommentMap(info.FSet, a, a.Comments)
+			a.Comments = []*ast.CommentGroup{} // remove all comments.
+			ast.FilterFile(a, filter)
+			if len(a.Decls) > 0 {
+				newPAst[name] = a
+			}
+			for _, d := range a.Decls {
+				// add back the comments associated with d only
+				comments := cmap.Filter(d).Comments()
+				a.Comments = append(a.Comments, comments...)
+			}
+		}
+		info.PAst = newPAst // add only matching files.
+	case info.PDoc != nil:
+		info.PDoc.Filter(filter)
+	}
+}
+
+// Does s look like a regular expression?
+func isRegexp(s string) bool {
+	return strings.ContainsAny(s, ".(|)*+?^$[]")
+}
+
+// Make a regular expression of the form
+// names[0]|names[1]|...names[len(names)-1].
+// Returns an error if the regular expression is illegal.
+func makeRx(names []string) (*regexp.Regexp, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no expression provided")
+	}
+	s := ""
+	for i, name := range names {
+		if i > 0 {
+			s += "|"
+		}
+		if isRegexp(name) {
+			s += name
+		} else {
+			s += "^" + name + "$" // must match exactly
+		}
+	}
+	return regexp.Compile(s)
+}
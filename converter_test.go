@@ -0,0 +1,340 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/godoc"
+)
+
+func TestUrlFromPackage(t *testing.T) {
+	testData := []struct {
+		pkg      string
+		expected string
+	}{
+		{"github.com/davecheney/godoc2md", "https://github.com/davecheney/godoc2md/tree/master"},
+		{"github.com/davecheney/godoc2md/examples", "https://github.com/davecheney/godoc2md/tree/master/examples"},
+		{"github.com/davecheney/godoc2md/examples/martini", "https://github.com/davecheney/godoc2md/tree/master/examples/martini"},
+		{"bitbucket.org/atlassianlabs/bitbucket-golang-base", "https://bitbucket.org/atlassianlabs/bitbucket-golang-base/src/master"},
+		{"bitbucket.org/atlassianlabs/bitbucket-golang-base/util", "https://bitbucket.org/atlassianlabs/bitbucket-golang-base/src/master/util"},
+		{"time", "https://golang.org/src/time"},
+		{"go/build", "https://golang.org/src/go/build"},
+		{"go/build/build.go", "https://golang.org/src/go/build/build.go"},
+		{"encoding/json", "https://golang.org/src/encoding/json"},
+		{"golang.org/x/tools/godoc", "https://github.com/golang/tools/tree/master/godoc"},
+		{"example.com/myuser/myrepo", "https://example.com/myuser/myrepo/src"},
+		{"gitlab.com/group/project/pkg", "https://gitlab.com/group/project/-/blob/master/pkg"},
+		{"codeberg.org/user/repo", "https://codeberg.org/user/repo/src/branch/master"},
+		{"git.sr.ht/~user/repo/pkg", "https://git.sr.ht/~user/repo/tree/master/item/pkg"},
+		{"dev.azure.com/org/project/_git/repo.git/pkg", "https://dev.azure.com/org/project/_git/repo?version=GBmaster&path=/pkg"},
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, tt := range testData {
+		got := c.urlFromPackage(tt.pkg)
+		if got != tt.expected {
+			t.Errorf("urlFromPackage(%d): expected %s, got %s", n, tt.expected, got)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	testData := []struct {
+		name string
+		opt  Option
+	}{
+		{"example title", WithExampleTitle("{{")},
+		{"tabs", WithExampleTabs("unknown")},
+		{"line breaks", WithLineBreaks("unknown")},
+		{"lead", WithLead("unknown")},
+		{"note style", WithNoteStyles(map[string]string{"BUG": "unknown"})},
+		{"notes", WithNotes("(")},
+		{"filters", WithFilters("(")},
+		{"template", WithTemplate("{{")},
+		{"layout", WithLayout("unknown")},
+		{"dialect", WithDialect("unknown")},
+		{"front matter", WithFrontMatter("unknown", nil)},
+		{"report", WithReportFormat("unknown")},
+		{"stdlib links", WithStdlibLinks("unknown")},
+		{"src link format", WithSrcLinkFormat("{{")},
+		{"heading offset", WithHeadingOffset(6)},
+		{"heading role", WithHeadingLevels(map[string]int{"unknown": 2})},
+		{"heading level", WithHeadingLevels(map[string]int{"symbol": 7})},
+	}
+	for _, tt := range testData {
+		if _, err := New(tt.opt); err == nil {
+			t.Errorf("New(%s): expected an error", tt.name)
+		}
+	}
+}
+
+func TestStdlibLinks(t *testing.T) {
+	testData := []struct {
+		site     string
+		expected string
+	}{
+		{"golang.org", "https://golang.org/src/go/build"},
+		{"go.dev", "https://go.dev/src/go/build"},
+		{"cs", "https://cs.opensource.google/go/go/+/master:src/go/build"},
+	}
+	for _, tt := range testData {
+		c, err := New(WithStdlibLinks(tt.site))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.urlFromPackage("go/build"); got != tt.expected {
+			t.Errorf("urlFromPackage(%s): expected %s, got %s", tt.site, tt.expected, got)
+		}
+		if got := c.urlFromPackage("github.com/davecheney/godoc2md"); got != "https://github.com/davecheney/godoc2md/tree/master" {
+			t.Errorf("urlFromPackage(%s): got %s for a package out of the standard library", tt.site, got)
+		}
+	}
+}
+
+func TestFileLink(t *testing.T) {
+	testData := []struct {
+		pkg      string
+		expected string
+	}{
+		{"github.com/davecheney/godoc2md", "https://raw.githubusercontent.com/davecheney/godoc2md/master/doc.go"},
+		{"golang.org/x/tools/godoc", "https://raw.githubusercontent.com/golang/tools/master/godoc/doc.go"},
+		{"gitlab.com/group/project/pkg", "https://gitlab.com/group/project/-/raw/master/pkg/doc.go"},
+		{"example.com/myuser/myrepo", "https://example.com/myuser/myrepo/src/doc.go"},
+	}
+	c, err := New(WithRawLinks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range testData {
+		if got := c.fileLinkFunc(tt.pkg, "doc.go"); got != tt.expected {
+			t.Errorf("fileLinkFunc(%s): expected %s, got %s", tt.pkg, tt.expected, got)
+		}
+	}
+}
+
+func TestImportStmt(t *testing.T) {
+	testData := []struct {
+		name, path string
+		expected   string
+	}{
+		{"godoc2md", "github.com/davecheney/godoc2md", `import "github.com/davecheney/godoc2md"`},
+		{"grpc", "google.golang.org/grpc", `import "google.golang.org/grpc"`},
+		{"yaml", "gopkg.in/yaml.v3", `import "gopkg.in/yaml.v3"`},
+		{"chi", "github.com/go-chi/chi/v5", `import "github.com/go-chi/chi/v5"`},
+		{"cli", "github.com/example/go-cli", `import cli "github.com/example/go-cli"`},
+		{"samp", "/tmp/hz", `import "/tmp/hz"`},
+	}
+	for _, tt := range testData {
+		if got := importStmtFunc(&doc.Package{Name: tt.name, ImportPath: tt.path}); got != tt.expected {
+			t.Errorf("importStmtFunc(%s): expected %s, got %s", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestOtherExamples(t *testing.T) {
+	info := &godoc.PageInfo{
+		PDoc: &doc.Package{
+			Funcs: []*doc.Func{{Name: "Helper"}},
+			Types: []*doc.Type{{Name: "Client", Methods: []*doc.Func{{Name: "Do"}}}},
+		},
+		Examples: []*doc.Example{
+			{Name: ""}, {Name: "_second"}, {Name: "Helper"}, {Name: "Client"},
+			{Name: "Client_Do"}, {Name: "Client_Do_retry"}, {Name: "Client_Missing"}, {Name: "Other_second"},
+		},
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, eg := range c.otherExamples(info) {
+		got = append(got, eg.Name)
+	}
+	if expected := []string{"Client_Missing", "Other_second"}; strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("otherExamples: expected %v, got %v", expected, got)
+	}
+}
+
+func TestKnownVanityRoot(t *testing.T) {
+	testData := []struct {
+		src        string
+		root, repo string
+	}{
+		{"go.uber.org/zap/zapcore", "go.uber.org/zap", "github.com/uber-go/zap"},
+		{"k8s.io/client-go/rest", "k8s.io/client-go", "github.com/kubernetes/client-go"},
+		{"google.golang.org/grpc/codes", "google.golang.org/grpc", "github.com/grpc/grpc-go"},
+		{"google.golang.org/grpcx", "", ""},
+		{"example.com/foo", "", ""},
+	}
+	for _, tt := range testData {
+		root, repo, _ := knownVanityRoot(tt.src)
+		if root != tt.root || repo != tt.repo {
+			t.Errorf("knownVanityRoot(%s): expected %s %s, got %s %s", tt.src, tt.root, tt.repo, root, repo)
+		}
+	}
+}
+
+func TestExcludeRx(t *testing.T) {
+	testData := []struct {
+		pattern, path string
+		expected      bool
+	}{
+		{"internal/...", "example.com/mod/internal", true},
+		{"internal/...", "example.com/mod/internal/x", true},
+		{"internal/...", "example.com/mod/internalx", false},
+		{"example.com/mod/cmd/...", "example.com/mod/cmd/tool", true},
+		{"example.com/mod/cmd", "example.com/mod/cmd/tool", false},
+		{"x...", "example.com/mod/xyz", true},
+	}
+	for _, tt := range testData {
+		if got := excludeRx(tt.pattern).MatchString(tt.path); got != tt.expected {
+			t.Errorf("excludeRx(%s) on %s: expected %v, got %v", tt.pattern, tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestIsTestFunc(t *testing.T) {
+	testData := []struct {
+		decl, kind string
+		expected   bool
+	}{
+		{"func TestFoo(t *testing.T) {}", "Test", true},
+		{"func Test(t *testing.T) {}", "Test", true},
+		{"func Testfoo(t *testing.T) {}", "Test", false},
+		{"func TestMain(m *testing.M) {}", "Test", false},
+		{"func TestFoo(b *testing.B) {}", "Test", false},
+		{"func BenchmarkFoo(b *testing.B) {}", "Benchmark", true},
+		{"func (s) BenchmarkFoo(b *testing.B) {}", "Benchmark", false},
+		{"func BenchmarkFoo(b *testing.B, n int) {}", "Benchmark", false},
+	}
+	for _, tt := range testData {
+		file, err := parser.ParseFile(token.NewFileSet(), "x_test.go", "package x\n"+tt.decl, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isTestFunc(file.Decls[0].(*ast.FuncDecl), tt.kind); got != tt.expected {
+			t.Errorf("isTestFunc(%s, %s): expected %v, got %v", tt.decl, tt.kind, tt.expected, got)
+		}
+	}
+}
+
+func TestSlugs(t *testing.T) {
+	testData := []struct {
+		heading                   string
+		github, gitlab, bitbucket string
+	}{
+		{"Package files", "package-files", "package-files", "markdown-header-package-files"},
+		{"func (c *Client) Do", "func-c-client-do", "func-c-client-do", "markdown-header-func-c-client-do"},
+		{"type Client.Do - 2", "type-clientdo---2", "type-clientdo-2", "markdown-header-type-client-do-2"},
+		{"Über \\*uns\\*", "über-uns", "über-uns", "markdown-header-über-uns"},
+		{"<a name=\"pkg-index\">Index</a>", "index", "index", "markdown-header-index"},
+	}
+	for _, tt := range testData {
+		if got := githubSlug(tt.heading); got != tt.github {
+			t.Errorf("githubSlug(%q): expected %q, got %q", tt.heading, tt.github, got)
+		}
+		if got := gitlabSlug(tt.heading); got != tt.gitlab {
+			t.Errorf("gitlabSlug(%q): expected %q, got %q", tt.heading, tt.gitlab, got)
+		}
+		if got := bitbucketSlug(tt.heading); got != tt.bitbucket {
+			t.Errorf("bitbucketSlug(%q): expected %q, got %q", tt.heading, tt.bitbucket, got)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	testData := []struct {
+		text, expected string
+	}{
+		{"a_b and *c*", "a\\_b and \\*c\\*"},
+		{"`a_b` and c_d", "`a_b` and c\\_d"},
+		{"``a ` b_c`` d_e", "``a ` b_c`` d\\_e"},
+		{"unclosed ` a_b", "unclosed ` a\\_b"},
+	}
+	for _, tt := range testData {
+		if got := escapeText(tt.text, "*_"); got != tt.expected {
+			t.Errorf("escapeText(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	testData := []struct {
+		name, expected string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"CreatedAt", "created_at"},
+		{"Category", "category"},
+	}
+	for _, tt := range testData {
+		if got := snakeCase(tt.name); got != tt.expected {
+			t.Errorf("snakeCase(%s): expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	testData := []struct {
+		snippet, expected string
+	}{
+		{`{{. | trim | indent 2}}`, "  a\n\n  b"},
+		{`{{. | trim | replace "a" "x"}}`, "x\n\nb"},
+		{`{{regex_replace "\\s+" " " .}}`, " a b "},
+		{`{{if regex_match "^\\s*a" .}}yes{{end}}`, "yes"},
+		{`{{"" | default "none"}} {{. | trim | default "none"}}`, "none a\n\nb"},
+		{`[{{template "item.tmpl" trim .}}]`, "[* a\n\nb]"},
+	}
+	c, err := New(WithTemplatePartials(map[string]string{"item.tmpl": `* {{.}}`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range testData {
+		var buf strings.Builder
+		if err := c.RenderSnippet(&buf, tt.snippet, " a\n\nb "); err != nil {
+			t.Errorf("RenderSnippet(%q): %v", tt.snippet, err)
+		} else if got := buf.String(); got != tt.expected {
+			t.Errorf("RenderSnippet(%q): expected %q, got %q", tt.snippet, tt.expected, got)
+		}
+	}
+	if got, err := joinFunc(", ", []int{1, 2}); err != nil || got != "1, 2" {
+		t.Errorf("joinFunc: expected %q, got %q, %v", "1, 2", got, err)
+	}
+}
+
+func TestFileMeta(t *testing.T) {
+	src := "// Copyright.\n\n//godoc2md:title HTTP client\n//godoc2md:description A client,\n//godoc2md:description with retries.\n" +
+		"//godoc2md:tags http, client,\n\n// Package p is a client.\npackage p\n\n//godoc2md:title not this one\nvar V int\n"
+	expected := PackageMeta{Title: "HTTP client", Description: "A client, with retries.", Tags: []string{"http", "client"}}
+	if got := fileMeta("p.go", []byte(src)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("fileMeta: expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.pkgDirs["example.com/p"] = "/src/p"
+	c.treeOutputs = map[string]string{"example.com/p": "/src/p/README.md"}
+	n, err := c.Rebuild(WithTemplate("{{.PDoc.Name}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.templateText != "{{.PDoc.Name}}" || n.pkgDirs["example.com/p"] != "/src/p" || n.treeOutputs["example.com/p"] != "/src/p/README.md" {
+		t.Errorf("Rebuild: template %q, directories %v and outputs %v, expected those of c", n.templateText, n.pkgDirs, n.treeOutputs)
+	}
+	n.pkgDirs["example.com/q"] = "/src/q"
+	if _, ok := c.pkgDirs["example.com/q"]; ok {
+		t.Error("Rebuild: expected the directories of c to be copied")
+	}
+}
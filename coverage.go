@@ -0,0 +1,109 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/ast"
+	"io"
+	"io/ioutil"
+)
+
+// An ExampleCoverage lists the exported functions, types and methods of
+// a package, as Name or Type.Method, by whether they have an example.
+type ExampleCoverage struct {
+	Covered []string
+	Missing []string
+}
+
+// Percent returns the percentage of the symbols that have an example,
+// 100 if there are none.
+func (ec *ExampleCoverage) Percent() float64 {
+	total := len(ec.Covered) + len(ec.Missing)
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(len(ec.Covered)) / float64(total)
+}
+
+// Report writes the report of ec for the package name to w, listing the
+// covered symbols with "+" and the missing ones with "-".
+func (ec *ExampleCoverage) Report(w io.Writer, name string) error {
+	total := len(ec.Covered) + len(ec.Missing)
+	if _, err := fmt.Fprintf(w, "%s: %d/%d symbols have examples (%.0f%%)\n", name, len(ec.Covered), total, ec.Percent()); err != nil {
+		return err
+	}
+	for _, s := range ec.Covered {
+		if _, err := fmt.Fprintf(w, "\t+ %s\n", s); err != nil {
+			return err
+		}
+	}
+	for _, s := range ec.Missing {
+		if _, err := fmt.Fprintf(w, "\t- %s\n", s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReportDigest is like Report, but writes a compact digest of ec for the
+// package name, to be posted to Slack or Discord: the coverage, followed
+// by the first symbols missing an example, in Markdown.
+func (ec *ExampleCoverage) ReportDigest(w io.Writer, name string) error {
+	total := len(ec.Covered) + len(ec.Missing)
+	digest := fmt.Sprintf("*%s*: %d/%d symbols have examples (%.0f%%)", name, len(ec.Covered), total, ec.Percent())
+	if len(ec.Missing) > 0 {
+		missing := make([]string, len(ec.Missing))
+		for i, s := range ec.Missing {
+			missing[i] = "`" + s + "`"
+		}
+		digest += ", missing " + digestList(missing, ", ")
+	}
+	_, err := fmt.Fprintln(w, digest)
+	return err
+}
+
+// ExampleCoverage reports which exported symbols of the package
+// importPath have at least one example.
+func (c *Converter) ExampleCoverage(importPath string) (*ExampleCoverage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := c.pageInfo(ioutil.Discard, importPath)
+	if err != nil {
+		return nil, err
+	}
+	ec := new(ExampleCoverage)
+	if info.PDoc == nil {
+		return ec, nil
+	}
+	hasExample := make(map[string]bool, len(info.Examples))
+	for _, eg := range info.Examples {
+		hasExample[stripExampleSuffix(eg.Name)] = true
+	}
+	add := func(name, exampleName string) {
+		if !ast.IsExported(name) {
+			return
+		}
+		if hasExample[exampleName] {
+			ec.Covered = append(ec.Covered, name)
+		} else {
+			ec.Missing = append(ec.Missing, name)
+		}
+	}
+	for _, f := range info.PDoc.Funcs {
+		add(f.Name, f.Name)
+	}
+	for _, t := range info.PDoc.Types {
+		if !ast.IsExported(t.Name) {
+			continue
+		}
+		add(t.Name, t.Name)
+		for _, f := range t.Funcs {
+			add(f.Name, f.Name)
+		}
+		for _, m := range t.Methods {
+			if ast.IsExported(m.Name) {
+				add(t.Name+"."+m.Name, t.Name+"_"+m.Name)
+			}
+		}
+	}
+	return ec, nil
+}
@@ -0,0 +1,251 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A dataColumn is a column of the table of a persistence model, see
+// dataModelMdFunc.
+type dataColumn struct {
+	name, field, typ string
+	constraints      []string
+}
+
+// A dataTable is the table of a persistence model, see dataModelMdFunc.
+type dataTable struct {
+	typ, name string
+	columns   []dataColumn
+}
+
+// snakeCase returns the name of the Go identifier name in snake case, as
+// gorm names columns: UserID is user_id.
+func snakeCase(name string) string {
+	var buf strings.Builder
+	r := []rune(name)
+	for i := range r {
+		if unicode.IsUpper(r[i]) && i > 0 && (unicode.IsLower(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1]) && unicode.IsUpper(r[i-1])) {
+			buf.WriteByte('_')
+		}
+		buf.WriteRune(unicode.ToLower(r[i]))
+	}
+	return buf.String()
+}
+
+// pluralize returns the plural of the English noun s, as gorm and bun
+// name tables.
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && !strings.HasSuffix(s, "ay") && !strings.HasSuffix(s, "ey") && !strings.HasSuffix(s, "oy"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s") || strings.HasSuffix(s, "x") || strings.HasSuffix(s, "ch") || strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// gormConstraints are the constraints of the gorm tag settings, by their
+// lower case key.
+var gormConstraints = map[string]string{
+	"primarykey":    "primary key",
+	"primary_key":   "primary key",
+	"not null":      "not null",
+	"notnull":       "not null",
+	"unique":        "unique",
+	"uniqueindex":   "unique index",
+	"index":         "index",
+	"autoincrement": "auto increment",
+	"default":       "default",
+	"size":          "size",
+	"type":          "type",
+	"check":         "check",
+	"foreignkey":    "foreign key",
+	"references":    "references",
+}
+
+// bunConstraints are the constraints of the bun tag options.
+var bunConstraints = map[string]string{
+	"pk":            "primary key",
+	"notnull":       "not null",
+	"unique":        "unique",
+	"autoincrement": "auto increment",
+	"nullzero":      "null if zero",
+	"default":       "default",
+	"type":          "type",
+}
+
+// dataColumnOf returns the column of the field name of type typ tagged with
+// tag, and whether the field is mapped to a column.
+func dataColumnOf(name, typ string, tag reflect.StructTag) (dataColumn, bool) {
+	col := dataColumn{name: snakeCase(name), field: name, typ: typ}
+	setting := func(s string, constraints map[string]string) {
+		key, value := s, ""
+		if i := strings.Index(s, ":"); i >= 0 {
+			key, value = s[:i], s[i+1:]
+		}
+		if key == "column" {
+			col.name = value
+			return
+		}
+		if c, ok := constraints[strings.ToLower(strings.TrimSpace(key))]; ok {
+			if value != "" {
+				c += " " + value
+			}
+			col.constraints = append(col.constraints, c)
+		}
+	}
+	switch {
+	case tag.Get("gorm") == "-" || tag.Get("db") == "-" || tag.Get("bun") == "-":
+		return col, false
+	case tag.Get("gorm") != "":
+		for _, s := range strings.Split(tag.Get("gorm"), ";") {
+			setting(s, gormConstraints)
+		}
+	case tag.Get("bun") != "":
+		opts := strings.Split(tag.Get("bun"), ",")
+		if opts[0] != "" && !strings.Contains(opts[0], ":") {
+			col.name = opts[0]
+		} else if strings.HasPrefix(opts[0], "rel:") {
+			// a relation, not a column
+			return col, false
+		}
+		for _, s := range opts[1:] {
+			setting(s, bunConstraints)
+		}
+	case tag.Get("db") != "":
+		col.name = strings.Split(tag.Get("db"), ",")[0]
+	}
+	return col, true
+}
+
+// dataTableOf returns the table mapped by the struct st of the type
+// tname, named by tableNames, and whether st is a persistence model: a
+// struct with gorm, db or bun tags, or embedding gorm.Model or
+// bun.BaseModel.
+func dataTableOf(tname string, st *ast.StructType, tableNames map[string]string) (dataTable, bool) {
+	table := dataTable{typ: tname, name: snakeCase(pluralize(tname))}
+	if name, ok := tableNames[tname]; ok {
+		table.name = name
+	}
+	model := false
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			if s, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, token.NewFileSet(), f.Type)
+		typ := buf.String()
+		for _, key := range []string{"gorm", "db", "bun"} {
+			if _, ok := tag.Lookup(key); ok {
+				model = true
+			}
+		}
+		if len(f.Names) == 0 {
+			switch typ {
+			case "gorm.Model":
+				model = true
+				table.columns = append(table.columns,
+					dataColumn{name: "id", field: "ID", typ: "uint", constraints: []string{"primary key"}},
+					dataColumn{name: "created_at", field: "CreatedAt", typ: "time.Time"},
+					dataColumn{name: "updated_at", field: "UpdatedAt", typ: "time.Time"},
+					dataColumn{name: "deleted_at", field: "DeletedAt", typ: "gorm.DeletedAt", constraints: []string{"index"}})
+			case "bun.BaseModel":
+				model = true
+				for _, opt := range strings.Split(tag.Get("bun"), ",") {
+					if strings.HasPrefix(opt, "table:") {
+						table.name = strings.TrimPrefix(opt, "table:")
+					}
+				}
+			}
+			continue
+		}
+		for _, n := range f.Names {
+			if !ast.IsExported(n.Name) {
+				continue
+			}
+			if col, ok := dataColumnOf(n.Name, typ, tag); ok {
+				table.columns = append(table.columns, col)
+			}
+		}
+	}
+	return table, model
+}
+
+// tableNames returns the table names returned by the TableName methods of
+// the types of the package of info, as a string literal.
+func (c *Converter) tableNames(info *godoc.PageInfo) map[string]string {
+	names := make(map[string]string)
+	for _, file := range parseFiles(c.fs, info.Dirname) {
+		for _, decl := range file.Decls {
+			f, ok := decl.(*ast.FuncDecl)
+			if !ok || f.Recv == nil || f.Name.Name != "TableName" || f.Body == nil || len(f.Body.List) != 1 {
+				continue
+			}
+			ret, ok := f.Body.List[0].(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				continue
+			}
+			lit, ok := ret.Results[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				names[embeddedName(f.Recv.List[0].Type)] = s
+			}
+		}
+	}
+	return names
+}
+
+// dataModelMdFunc returns the Data model section of the package of info,
+// set with WithDataModel: the tables mapped by its gorm, sqlx and bun
+// models, with their columns and constraints, or the empty string if the
+// package has no models.
+func (c *Converter) dataModelMdFunc(info *godoc.PageInfo) string {
+	if !c.dataModel || info.PDoc == nil {
+		return ""
+	}
+	var tableNames map[string]string
+	var tables []dataTable
+	for _, t := range info.PDoc.Types {
+		ts := typeSpec(t)
+		if ts == nil {
+			continue
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		if tableNames == nil {
+			tableNames = c.tableNames(info)
+		}
+		if table, ok := dataTableOf(t.Name, st, tableNames); ok {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-data-model") + c.headingFunc("section", 2) + "<a name=\"pkg-data-model\">Data model</a>\n\n")
+	for _, table := range tables {
+		buf.WriteString(c.headingFunc("subsection", 4) + "[" + c.mdFunc(table.typ) + "](#" + table.typ + "): `" + table.name + "`\n\n")
+		buf.WriteString("| Column | Field | Type | Constraints |\n| --- | --- | --- | --- |\n")
+		for _, col := range table.columns {
+			buf.WriteString("| `" + col.name + "` | " + c.mdFunc(col.field) + " | `" + col.typ + "` | " + strings.Replace(strings.Join(col.constraints, ", "), "|", "\\|", -1) + " |\n")
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
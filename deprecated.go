@@ -0,0 +1,102 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/doc"
+	"go/doc/comment"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// deprecatedPrefix starts the paragraphs of doc comments telling that a
+// symbol is deprecated, by Go convention.
+const deprecatedPrefix = "Deprecated: "
+
+// isDeprecation reports whether the paragraph text is a deprecation
+// notice.
+func isDeprecation(text []comment.Text) bool {
+	p, ok := text[0].(comment.Plain)
+	return ok && strings.HasPrefix(string(p), deprecatedPrefix)
+}
+
+// deprecationMD writes the deprecation notice text as a CAUTION callout,
+// with Deprecated in bold.
+func (c *Converter) deprecationMD(w io.Writer, text []comment.Text) {
+	text = append([]comment.Text{comment.Plain(strings.TrimPrefix(string(text[0].(comment.Plain)), deprecatedPrefix))}, text[1:]...)
+	var buf bytes.Buffer
+	c.paragraphMD(&buf, text, false)
+	_, _ = io.WriteString(w, "> [!CAUTION]\n> **Deprecated:** ")
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			line = strings.TrimRight("> "+line, " ")
+		}
+		_, _ = io.WriteString(w, line+"\n")
+	}
+	_, _ = w.Write(mdNewline)
+}
+
+// deprecation returns the deprecation notice of the doc comment on one
+// line, or the empty string.
+func deprecation(doc string) string {
+	for _, para := range strings.Split(doc, "\n\n") {
+		para = strings.TrimSpace(para)
+		if strings.HasPrefix(para, deprecatedPrefix) {
+			return strings.Join(strings.Fields(strings.TrimPrefix(para, deprecatedPrefix)), " ")
+		}
+	}
+	return ""
+}
+
+// A deprecatedSymbol is an entry of the Deprecated APIs section.
+type deprecatedSymbol struct {
+	Symbol
+	Link   string
+	Notice string
+}
+
+// deprecatedSymbols returns the deprecated symbols of the package of
+// info, with their deprecation notice, when the Deprecated APIs
+// section is enabled.
+func (c *Converter) deprecatedSymbols(info *godoc.PageInfo) []deprecatedSymbol {
+	if !c.deprecatedSection || info.PDoc == nil {
+		return nil
+	}
+	var symbols []deprecatedSymbol
+	c.eachSymbol(info.PDoc, func(s Symbol, t *doc.Type, doc string) {
+		notice := deprecation(doc)
+		if notice == "" {
+			return
+		}
+		link := "#" + s.Anchor
+		switch {
+		case c.layout == "summary":
+			link = "#pkg-summary"
+		case t != nil && c.isSplit(t):
+			link = typeFile(t) + link
+		}
+		symbols = append(symbols, deprecatedSymbol{Symbol: s, Link: link, Notice: notice})
+	})
+	return symbols
+}
+
+// deprecatedMdFunc returns the Deprecated APIs section of the package of
+// info, listing its deprecated symbols, or the empty string if there are
+// none.
+func (c *Converter) deprecatedMdFunc(info *godoc.PageInfo) string {
+	symbols := c.deprecatedSymbols(info)
+	if len(symbols) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-deprecated"))
+	buf.WriteString(c.headingFunc("section", 2) + "<a name=\"pkg-deprecated\">Deprecated APIs</a>\n\n| Symbol | Kind | Notice |\n| --- | --- | --- |\n")
+	for _, s := range symbols {
+		notice := strings.Replace(c.mdFunc(s.Notice), "|", "\\|", -1)
+		buf.WriteString("| [" + s.Name + "](" + s.Link + ") | " + s.Kind + " | " + notice + " |\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
@@ -0,0 +1,243 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// typeSpec returns the spec declaring the type t.
+func typeSpec(t *doc.Type) *ast.TypeSpec {
+	if t.Decl == nil {
+		return nil
+	}
+	for _, spec := range t.Decl.Specs {
+		if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == t.Name {
+			return ts
+		}
+	}
+	return nil
+}
+
+// embeddedName returns the name of the type of the package embedded by
+// the field type x, T or *T, or the empty string if x is a type of
+// another package.
+func embeddedName(x ast.Expr) string {
+	if star, ok := x.(*ast.StarExpr); ok {
+		x = star.X
+	}
+	switch x := x.(type) {
+	case *ast.IndexExpr:
+		return embeddedName(x.X)
+	case *ast.IndexListExpr:
+		return embeddedName(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
+
+// signature returns the parameter and result types of the function type
+// ft, without their names, to compare methods.
+func signature(ft *ast.FuncType) string {
+	var buf bytes.Buffer
+	fset := token.NewFileSet()
+	fields := func(fl *ast.FieldList) {
+		buf.WriteString("(")
+		if fl != nil {
+			for _, f := range fl.List {
+				for n := 0; n < len(f.Names) || n == 0; n++ {
+					_ = printer.Fprint(&buf, fset, f.Type)
+					buf.WriteString(",")
+				}
+			}
+		}
+		buf.WriteString(")")
+	}
+	fields(ft.Params)
+	fields(ft.Results)
+	return buf.String()
+}
+
+// A typeGraph is the relationships between the types of a package drawn
+// by the type diagram.
+type typeGraph struct {
+	types      []*doc.Type
+	interfaces map[string]bool
+	// methods maps the types to the signatures of their methods, by name,
+	// including the promoted ones; complete is false for the types whose
+	// method set is partly unknown, embedding types of other packages
+	methods  map[string]map[string]string
+	complete map[string]bool
+	embeds   map[string][]string
+}
+
+// newTypeGraph returns the type graph of the types of info, the methods
+// folded as accessors included.
+func (c *Converter) newTypeGraph(info *godoc.PageInfo) *typeGraph {
+	g := &typeGraph{
+		types:      info.PDoc.Types,
+		interfaces: make(map[string]bool),
+		methods:    make(map[string]map[string]string),
+		complete:   make(map[string]bool),
+		embeds:     make(map[string][]string),
+	}
+	specs := make(map[string]*ast.TypeSpec)
+	for _, t := range g.types {
+		if ts := typeSpec(t); ts != nil {
+			specs[t.Name] = ts
+		}
+	}
+	var methodSet func(name string, seen map[string]bool) (map[string]string, bool)
+	methodSet = func(name string, seen map[string]bool) (map[string]string, bool) {
+		if m, ok := g.methods[name]; ok {
+			return m, g.complete[name]
+		}
+		ts, ok := specs[name]
+		if !ok || seen[name] {
+			return nil, false
+		}
+		seen[name] = true
+		set, complete := make(map[string]string), true
+		embed := func(x ast.Expr) {
+			e := embeddedName(x)
+			if _, local := specs[e]; !local {
+				complete = false
+				return
+			}
+			g.embeds[name] = append(g.embeds[name], e)
+			m, ok := methodSet(e, seen)
+			complete = complete && ok
+			for n, sig := range m {
+				if _, ok := set[n]; !ok {
+					set[n] = sig
+				}
+			}
+		}
+		switch x := ts.Type.(type) {
+		case *ast.InterfaceType:
+			g.interfaces[name] = true
+			for _, f := range x.Methods.List {
+				switch ft := f.Type.(type) {
+				case *ast.FuncType:
+					for _, n := range f.Names {
+						set[n.Name] = signature(ft)
+					}
+				case *ast.Ident, *ast.SelectorExpr:
+					embed(ft)
+				default:
+					// a type constraint
+					complete = false
+				}
+			}
+		case *ast.StructType:
+			for _, f := range x.Fields.List {
+				if len(f.Names) == 0 {
+					embed(f.Type)
+				}
+			}
+		}
+		for _, t := range g.types {
+			if t.Name != name {
+				continue
+			}
+			for _, m := range append(t.Methods, c.accessorFuncs[t.Name]...) {
+				set[m.Name] = signature(m.Decl.Type)
+			}
+		}
+		g.methods[name], g.complete[name] = set, complete
+		return set, complete
+	}
+	for _, t := range g.types {
+		methodSet(t.Name, make(map[string]bool))
+	}
+	return g
+}
+
+// implements returns the interfaces of the package the type name
+// implements, by the names and signatures of their methods.
+func (g *typeGraph) implements(name string) []string {
+	var ifaces []string
+	if g.interfaces[name] {
+		return nil
+	}
+	for _, t := range g.types {
+		iface := t.Name
+		if !g.interfaces[iface] || !g.complete[iface] || len(g.methods[iface]) == 0 {
+			continue
+		}
+		ok := true
+		for n, sig := range g.methods[iface] {
+			if g.methods[name][n] != sig {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	sort.Strings(ifaces)
+	return ifaces
+}
+
+// typeDiagramMdFunc returns the Type diagram section of the package of
+// info, set with WithTypeDiagram: a Mermaid diagram of its types, the
+// interfaces of the package they implement and the types they embed, or
+// the empty string if the package has no types.
+func (c *Converter) typeDiagramMdFunc(info *godoc.PageInfo) string {
+	if c.typeDiagram == "" || info.PDoc == nil || len(info.PDoc.Types) == 0 {
+		return ""
+	}
+	g := c.newTypeGraph(info)
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-type-diagram") + c.headingFunc("subsection", 4) + "<a name=\"pkg-type-diagram\">Type diagram</a>\n\n``` mermaid\n")
+	switch c.typeDiagram {
+	case "class":
+		buf.WriteString("classDiagram\n")
+		for _, t := range g.types {
+			if g.interfaces[t.Name] {
+				buf.WriteString("    class " + t.Name + " {\n        <<interface>>\n    }\n")
+			} else {
+				buf.WriteString("    class " + t.Name + "\n")
+			}
+		}
+		for _, t := range g.types {
+			for _, e := range g.embeds[t.Name] {
+				if g.interfaces[t.Name] {
+					buf.WriteString("    " + e + " <|-- " + t.Name + "\n")
+				} else {
+					buf.WriteString("    " + t.Name + " *-- " + e + " : embeds\n")
+				}
+			}
+			for _, iface := range g.implements(t.Name) {
+				buf.WriteString("    " + iface + " <|.. " + t.Name + "\n")
+			}
+		}
+	case "graph":
+		buf.WriteString("graph LR\n")
+		for _, t := range g.types {
+			if g.interfaces[t.Name] {
+				buf.WriteString("    " + t.Name + "{{" + t.Name + "}}\n")
+			} else {
+				buf.WriteString("    " + t.Name + "[" + t.Name + "]\n")
+			}
+		}
+		for _, t := range g.types {
+			for _, e := range g.embeds[t.Name] {
+				buf.WriteString("    " + t.Name + " -- embeds --> " + e + "\n")
+			}
+			for _, iface := range g.implements(t.Name) {
+				buf.WriteString("    " + t.Name + " -. implements .-> " + iface + "\n")
+			}
+		}
+	}
+	buf.WriteString("```\n\n")
+	return buf.String()
+}
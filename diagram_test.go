@@ -0,0 +1,103 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/godoc"
+)
+
+func TestTypeDiagram(t *testing.T) {
+	src := `package p
+
+import "io"
+
+// Reader reads.
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+// ReadCloser reads and closes.
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+// File is a file.
+type File struct{ name string }
+
+func (f *File) Read(p []byte) (int, error) { return 0, nil }
+
+func (f *File) Close() error { return nil }
+
+// Buffered embeds a File.
+type Buffered struct {
+	*File
+	io.Writer
+}
+
+// Other reads something else.
+type Other struct{}
+
+func (Other) Read(p string) (int, error) { return 0, nil }
+`
+	testData := []struct {
+		diagram  string
+		expected string
+	}{
+		{"class", `classDiagram
+    class Buffered
+    class File
+    class Other
+    class ReadCloser {
+        <<interface>>
+    }
+    class Reader {
+        <<interface>>
+    }
+    Buffered *-- File : embeds
+    ReadCloser <|.. Buffered
+    Reader <|.. Buffered
+    ReadCloser <|.. File
+    Reader <|.. File
+    Reader <|-- ReadCloser
+`},
+		{"graph", `graph LR
+    Buffered[Buffered]
+    File[File]
+    Other[Other]
+    ReadCloser{{ReadCloser}}
+    Reader{{Reader}}
+    Buffered -- embeds --> File
+    Buffered -. implements .-> ReadCloser
+    Buffered -. implements .-> Reader
+    File -. implements .-> ReadCloser
+    File -. implements .-> Reader
+    ReadCloser -- embeds --> Reader
+`},
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdoc, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range testData {
+		c, err := New(WithTypeDiagram(tt.diagram))
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := c.typeDiagramMdFunc(&godoc.PageInfo{FSet: fset, PDoc: pdoc})
+		got := md[strings.Index(md, "``` mermaid\n")+len("``` mermaid\n") : strings.LastIndex(md, "```")]
+		if got != tt.expected {
+			t.Errorf("typeDiagramMdFunc(%s): expected %q, got %q", tt.diagram, tt.expected, got)
+		}
+	}
+}
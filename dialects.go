@@ -0,0 +1,212 @@
+package godoc2md
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A dialect is a flavor of Markdown the output is written for.
+type dialect struct {
+	// escaped are the characters escaped with a backslash in identifiers
+	// and declarations rendered as text, see the md template function.
+	escaped string
+
+	// prose are the characters escaped with a backslash in the text of
+	// doc comments.
+	prose string
+
+	// anchors reports whether the renderer lacks heading IDs, so that
+	// explicit HTML anchors are emitted before headings.
+	anchors bool
+
+	// jsx reports whether the HTML of the output must be valid JSX: void
+	// elements are closed and style attributes are dropped.
+	jsx bool
+
+	// plain reports whether the renderer supports neither tables, nor
+	// HTML, nor fenced code blocks, so that the output is rewritten
+	// with plainMarkdown.
+	plain bool
+
+	// tocMacro is the macro of the renderer inserting a table of
+	// contents, written at the top of the output instead of the table of
+	// contents of WithTOC.
+	tocMacro string
+
+	// attachments is the directory of the wiki, from its root, holding
+	// the assets of the pages, see attachmentName, or the empty string if
+	// the assets are next to the output.
+	attachments string
+
+	// slug returns the ID that the renderer generates for a heading,
+	// githubSlug if nil.
+	slug func(heading string) string
+}
+
+// dialects are the supported dialects, by name.
+var dialects = map[string]dialect{
+	"gfm":        {escaped: "*_"},
+	"commonmark": {escaped: "\\`*_[]<>", anchors: true},
+	"mdx":        {escaped: "*_{}<>", prose: "{}<>", jsx: true},
+	"bitbucket":  {escaped: "*_[]", slug: bitbucketSlug},
+	"gitlab":     {escaped: "*_", slug: gitlabSlug},
+	"azure":      {escaped: "*_"},
+	"plain":      {escaped: "*_", plain: true},
+	"azure-wiki": {escaped: "*_[]", anchors: true, tocMacro: "[[_TOC_]]", attachments: "/.attachments/", slug: azureWikiSlug},
+}
+
+// slugMarkupRx matches the HTML tags and the backslashes of Markdown
+// escapes of a heading, which aren't part of its text.
+var slugMarkupRx = regexp.MustCompile(`<[^>]*>|\\[\pP\pS]`)
+
+// slugText returns the text of the Markdown heading, as the renderers
+// see it.
+func slugText(heading string) string {
+	return strings.TrimSpace(slugMarkupRx.ReplaceAllStringFunc(heading, func(m string) string {
+		if m[0] == '\\' {
+			return m[1:]
+		}
+		return ""
+	}))
+}
+
+// githubSlug returns the ID that GitHub generates for the heading text:
+// lowercased, with hyphens for spaces, the letters, digits, underscores
+// and hyphens kept, including the non-ASCII ones, and the other
+// punctuation and symbols, such as dots and asterisks, dropped.
+func githubSlug(text string) string {
+	var buf strings.Builder
+	for _, r := range strings.ToLower(slugText(text)) {
+		switch {
+		case r == ' ':
+			buf.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// gitlabSlug returns the ID that GitLab generates for the heading text:
+// like githubSlug, but with runs of hyphens collapsed into one.
+func gitlabSlug(text string) string {
+	var buf strings.Builder
+	for _, r := range githubSlug(text) {
+		if r == '-' && strings.HasSuffix(buf.String(), "-") {
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// bitbucketSlug returns the ID that Bitbucket generates for the heading
+// text: lowercased, with a hyphen for each run of other characters than
+// letters and digits, prefixed with "markdown-header-".
+func bitbucketSlug(text string) string {
+	slug := strings.FieldsFunc(strings.ToLower(slugText(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return "markdown-header-" + strings.Join(slug, "-")
+}
+
+// azureWikiSlug returns the ID that Azure DevOps wikis generate for the
+// heading text: lowercased, with hyphens for spaces, and without the
+// other punctuation.
+func azureWikiSlug(text string) string {
+	var buf strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			buf.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// escape escapes the characters chars of text with a backslash.
+func escape(text, chars string) string {
+	if !strings.ContainsAny(text, chars) {
+		return text
+	}
+	var buf strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(chars, r) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// codeSpans splits text into the code spans quoted with backticks, at
+// the odd indexes, and the text around them, at the even ones, as
+// CommonMark does: a span is closed by a run of as many backticks as its
+// opening one, and the runs left unclosed are text.
+func codeSpans(text string) []string {
+	var parts []string
+	last := 0
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(text) && text[i+n] == '`' {
+			n++
+		}
+		end := -1
+		for j := i + n; j < len(text); {
+			if text[j] != '`' {
+				j++
+				continue
+			}
+			k := 1
+			for j+k < len(text) && text[j+k] == '`' {
+				k++
+			}
+			if k == n {
+				end = j + k
+				break
+			}
+			j += k
+		}
+		if end < 0 {
+			i += n
+			continue
+		}
+		parts = append(parts, text[last:i], text[i:end])
+		i, last = end, end
+	}
+	return append(parts, text[last:])
+}
+
+// escapeText is escape, leaving the code spans of text alone.
+func escapeText(text, chars string) string {
+	if !strings.ContainsAny(text, chars) || !strings.Contains(text, "`") {
+		return escape(text, chars)
+	}
+	parts := codeSpans(text)
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = escape(parts[i], chars)
+	}
+	return strings.Join(parts, "")
+}
+
+// mdFunc escapes text for the dialect of c, but in its code spans.
+func (c *Converter) mdFunc(text string) string {
+	return escapeText(text, c.dialect.escaped)
+}
+
+// kebabFunc returns the ID that the renderer of the dialect of c
+// generates for the heading text, to link to headings without explicit
+// anchors.
+func (c *Converter) kebabFunc(text string) string {
+	if c.dialect.slug == nil {
+		return githubSlug(text)
+	}
+	return c.dialect.slug(text)
+}
@@ -0,0 +1,176 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// declDirectives are the godoc2md directives of the comments before a
+// declaration, such as
+//
+//	//godoc2md:skip
+//	//godoc2md:section Advanced
+//	//godoc2md:order 10
+//
+// skip leaves the declaration out of the documentation, section moves a
+// function or a type to a section of its own, after the types, and order
+// puts the declaration before the other ones of its list, by ascending
+// order.
+type declDirectives struct {
+	skip    bool
+	section string
+	order   int
+	ordered bool
+}
+
+// directives returns the godoc2md directives of the comment lines right
+// before decl in its source.
+func (c *Converter) directives(info *godoc.PageInfo, decl ast.Node) declDirectives {
+	var d declDirectives
+	if decl == nil || !decl.Pos().IsValid() {
+		return d
+	}
+	pos := info.FSet.Position(decl.Pos())
+	f := c.srcFile(pos.Filename)
+	if f == nil || pos.Line > len(f.starts) {
+		return d
+	}
+	for line := pos.Line - 1; line >= 1; line-- {
+		end := len(f.data)
+		if line < len(f.starts) {
+			end = f.starts[line]
+		}
+		text := strings.TrimSpace(string(f.data[f.starts[line-1]:end]))
+		if !strings.HasPrefix(text, "//") {
+			break
+		}
+		if !strings.HasPrefix(text, directivePrefix) {
+			continue
+		}
+		key, value := strings.TrimPrefix(text, directivePrefix), ""
+		if i := strings.IndexAny(key, " \t"); i >= 0 {
+			key, value = key[:i], strings.TrimSpace(key[i:])
+		}
+		switch key {
+		case "skip":
+			d.skip = true
+		case "section":
+			d.section = value
+		case "order":
+			if n, err := strconv.Atoi(value); err == nil {
+				d.order, d.ordered = n, true
+			}
+		}
+	}
+	return d
+}
+
+// applyDirectives sorts the declarations of info by their order
+// directives, the ordered ones first, and records the sections of its
+// functions and types. Those skipped are removed by excludeDecls.
+func (c *Converter) applyDirectives(info *godoc.PageInfo) {
+	if info.PDoc == nil {
+		return
+	}
+	cache := make(map[ast.Node]declDirectives)
+	directives := func(decl ast.Node) declDirectives {
+		d, ok := cache[decl]
+		if !ok {
+			d = c.directives(info, decl)
+			cache[decl] = d
+		}
+		return d
+	}
+	before := func(x, y ast.Node) bool {
+		a, b := directives(x), directives(y)
+		return a.ordered && (!b.ordered || a.order < b.order)
+	}
+	values := func(values []*doc.Value) {
+		sort.SliceStable(values, func(i, j int) bool { return before(values[i].Decl, values[j].Decl) })
+	}
+	funcs := func(funcs []*doc.Func) {
+		sort.SliceStable(funcs, func(i, j int) bool { return before(funcs[i].Decl, funcs[j].Decl) })
+	}
+
+	pdoc := info.PDoc
+	values(pdoc.Consts)
+	values(pdoc.Vars)
+	funcs(pdoc.Funcs)
+	sort.SliceStable(pdoc.Types, func(i, j int) bool { return before(pdoc.Types[i].Decl, pdoc.Types[j].Decl) })
+	for _, t := range pdoc.Types {
+		values(t.Consts)
+		values(t.Vars)
+		funcs(t.Funcs)
+		funcs(t.Methods)
+	}
+
+	section := func(name string, decl ast.Node) {
+		s := directives(decl).section
+		if s == "" {
+			return
+		}
+		if c.sections == nil {
+			c.sections = make(map[string]string)
+		}
+		known := false
+		for _, name := range c.sectionNames {
+			known = known || name == s
+		}
+		if !known {
+			c.sectionNames = append(c.sectionNames, s)
+		}
+		c.sections[name] = s
+	}
+	for _, f := range pdoc.Funcs {
+		section(f.Name, f.Decl)
+	}
+	for _, t := range pdoc.Types {
+		section(t.Name, t.Decl)
+	}
+}
+
+// sectionFuncs returns the functions of info in section, those in none
+// if it's empty.
+func (c *Converter) sectionFuncs(info *godoc.PageInfo, section string) []*doc.Func {
+	if info.PDoc == nil {
+		return nil
+	}
+	if len(c.sections) == 0 && section == "" {
+		return info.PDoc.Funcs
+	}
+	var funcs []*doc.Func
+	for _, f := range info.PDoc.Funcs {
+		if c.sections[f.Name] == section {
+			funcs = append(funcs, f)
+		}
+	}
+	return funcs
+}
+
+// sectionTypes returns the types of info in section, those in none if
+// it's empty.
+func (c *Converter) sectionTypes(info *godoc.PageInfo, section string) []*doc.Type {
+	if info.PDoc == nil {
+		return nil
+	}
+	if len(c.sections) == 0 && section == "" {
+		return info.PDoc.Types
+	}
+	var types []*doc.Type
+	for _, t := range info.PDoc.Types {
+		if c.sections[t.Name] == section {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// sectionIDFunc returns the anchor of the heading of section.
+func sectionIDFunc(section string) string {
+	return "pkg-section-" + githubSlug(section)
+}
@@ -0,0 +1,97 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/godoc/vfs/mapfs"
+)
+
+func TestApplyDirectives(t *testing.T) {
+	src := `package p
+
+// A is not ordered.
+func A() {}
+
+// B comes first.
+//
+//godoc2md:order 1
+func B() {}
+
+//godoc2md:order 2
+func C() {}
+
+// D is internal.
+//godoc2md:skip
+func D() {}
+
+// E is advanced.
+//godoc2md:section Advanced
+func E() {}
+
+//godoc2md:section Advanced
+//godoc2md:order -1
+type T struct{}
+
+type U struct{}
+
+// NewU is ordered.
+//godoc2md:order 3
+func NewU() U { return U{} }
+
+// Z is ordered, but too early.
+//godoc2md:order 0
+
+func Z() {}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "/p/p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdoc, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.fs = vfs.NewNameSpace()
+	c.fs.Bind("/", mapfs.New(map[string]string{"p/p.go": src}), "/", vfs.BindReplace)
+	info := &godoc.PageInfo{FSet: fset, PDoc: pdoc}
+	c.applyDirectives(info)
+
+	names := func(funcs []*doc.Func) []string {
+		var names []string
+		for _, f := range funcs {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+	testData := []struct {
+		what     string
+		got      interface{}
+		expected interface{}
+	}{
+		{"funcs", names(pdoc.Funcs), []string{"B", "C", "A", "D", "E", "Z"}},
+		{"types", []string{pdoc.Types[0].Name, pdoc.Types[1].Name}, []string{"T", "U"}},
+		{"funcs of no section", names(c.sectionFuncs(info, "")), []string{"B", "C", "A", "D", "Z"}},
+		{"funcs of Advanced", names(c.sectionFuncs(info, "Advanced")), []string{"E"}},
+		{"types of Advanced", len(c.sectionTypes(info, "Advanced")), 1},
+		{"sections", c.sectionNames, []string{"Advanced"}},
+		{"skip D", c.directives(info, pdoc.Funcs[3].Decl).skip, true},
+		{"skip A", c.directives(info, pdoc.Funcs[2].Decl).skip, false},
+	}
+	for _, tt := range testData {
+		if !reflect.DeepEqual(tt.got, tt.expected) {
+			t.Errorf("applyDirectives: %s: expected %v, got %v", tt.what, tt.expected, tt.got)
+		}
+	}
+}
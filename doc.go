@@ -0,0 +1,16 @@
+// Package godoc2md converts godoc formatted package documentation into
+// Markdown format.
+//
+// A Converter is created with New and configured with options:
+//
+//	c, err := godoc2md.New(godoc2md.WithExamples(true))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := c.Convert("github.com/davecheney/godoc2md", os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The godoc2md command, in cmd/godoc2md, is a thin wrapper around this
+// package.
+package godoc2md
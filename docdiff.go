@@ -0,0 +1,168 @@
+package godoc2md
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A DocChange is a change of the API or of the documentation of a symbol
+// between two documentation models, see DiffDocs. Its JSON encoding is
+// stable, for the bots posting review comments or drafting release notes.
+type DocChange struct {
+	// Package is the import path of the package of the symbol.
+	Package string `json:"package"`
+	// Symbol is the name of the symbol, such as Client.Do for a method,
+	// or the empty string for the package itself.
+	Symbol string `json:"symbol"`
+	// Kind is "package", "const", "var", "func", "type" or "method".
+	Kind string `json:"kind"`
+	// Change is "added", "removed" or "changed".
+	Change string `json:"change"`
+	// Before and After are the declaration and the doc comment of the
+	// symbol in the previous and the new model, nil when it's missing.
+	Before *SymbolDoc `json:"before"`
+	After  *SymbolDoc `json:"after"`
+}
+
+// A SymbolDoc is the declaration and the doc comment of a symbol, the
+// declaration of its group for the constants and variables.
+type SymbolDoc struct {
+	Decl string `json:"decl"`
+	Doc  string `json:"doc"`
+}
+
+// A docSymbol is a symbol of a documentation model, with its kind.
+type docSymbol struct {
+	name, kind string
+	doc        SymbolDoc
+}
+
+// docSymbols returns the symbols of the documentation model p in order:
+// the package, its constants, variables, functions and types, each type
+// followed by its constants, variables, constructors and methods.
+func docSymbols(p *PackageDoc) []docSymbol {
+	symbols := []docSymbol{{"", "package", SymbolDoc{"package " + p.Name, p.Doc}}}
+	values := func(kind string, values []ValueDoc) {
+		for _, v := range values {
+			for _, name := range v.Names {
+				symbols = append(symbols, docSymbol{name, kind, SymbolDoc{v.Decl, v.Doc}})
+			}
+		}
+	}
+	funcs := func(kind, prefix string, funcs []FuncDoc) {
+		for _, f := range funcs {
+			symbols = append(symbols, docSymbol{prefix + f.Name, kind, SymbolDoc{f.Decl, f.Doc}})
+		}
+	}
+	values("const", p.Consts)
+	values("var", p.Vars)
+	funcs("func", "", p.Funcs)
+	for _, t := range p.Types {
+		symbols = append(symbols, docSymbol{t.Name, "type", SymbolDoc{t.Decl, t.Doc}})
+		values("const", t.Consts)
+		values("var", t.Vars)
+		funcs("func", "", t.Funcs)
+		funcs("method", t.Name+".", t.Methods)
+	}
+	return symbols
+}
+
+// DiffDocs returns the changes of the declarations and doc comments of
+// the symbols between the documentation models old and new, matched by
+// import path: for each package of new, the changed and added symbols in
+// its order, then the removed ones in the order of old, followed by the
+// packages of old missing from new. A package added or removed is a
+// single change of its package symbol. The positions are ignored, as
+// they move whenever the files are edited.
+func DiffDocs(old, new []*PackageDoc) []DocChange {
+	oldPkgs := make(map[string]*PackageDoc)
+	for _, p := range old {
+		oldPkgs[p.ImportPath] = p
+	}
+	newPkgs := make(map[string]bool)
+	changes := []DocChange{}
+	for _, p := range new {
+		newPkgs[p.ImportPath] = true
+		prev, ok := oldPkgs[p.ImportPath]
+		if !ok {
+			after := docSymbols(p)[0].doc
+			changes = append(changes, DocChange{Package: p.ImportPath, Kind: "package", Change: "added", After: &after})
+			continue
+		}
+		before := make(map[string]docSymbol)
+		for _, s := range docSymbols(prev) {
+			before[s.name] = s
+		}
+		after := make(map[string]bool)
+		for _, s := range docSymbols(p) {
+			s := s
+			after[s.name] = true
+			b, ok := before[s.name]
+			switch {
+			case !ok:
+				changes = append(changes, DocChange{Package: p.ImportPath, Symbol: s.name, Kind: s.kind, Change: "added", After: &s.doc})
+			case b.doc != s.doc || b.kind != s.kind:
+				changes = append(changes, DocChange{Package: p.ImportPath, Symbol: s.name, Kind: s.kind, Change: "changed", Before: &b.doc, After: &s.doc})
+			}
+		}
+		for _, s := range docSymbols(prev) {
+			s := s
+			if !after[s.name] {
+				changes = append(changes, DocChange{Package: p.ImportPath, Symbol: s.name, Kind: s.kind, Change: "removed", Before: &s.doc})
+			}
+		}
+	}
+	for _, p := range old {
+		if !newPkgs[p.ImportPath] {
+			before := docSymbols(p)[0].doc
+			changes = append(changes, DocChange{Package: p.ImportPath, Kind: "package", Change: "removed", Before: &before})
+		}
+	}
+	return changes
+}
+
+// ReadPackageDocs reads the documentation models of data, a previous
+// output of ConvertJSON: a JSON object per package.
+func ReadPackageDocs(data []byte) ([]*PackageDoc, error) {
+	var docs []*PackageDoc
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var p PackageDoc
+		if err := dec.Decode(&p); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &p)
+	}
+}
+
+// WriteDocChanges writes the changes to w in the format "json", an
+// indented JSON array of DocChange, or "text", the changed symbols of
+// each package marked as by ReportChanges.
+func WriteDocChanges(w io.Writer, changes []DocChange, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	case "", "text":
+		var buf strings.Builder
+		for i, ch := range changes {
+			if i == 0 || ch.Package != changes[i-1].Package {
+				fmt.Fprintf(&buf, "%s:\n", ch.Package)
+			}
+			line := ch.Kind + " " + ch.Symbol
+			if ch.Symbol == "" {
+				line = ch.Kind
+			}
+			fmt.Fprintf(&buf, "\t%s %s\n", changeMarks[ch.Change], line)
+		}
+		_, err := io.WriteString(w, buf.String())
+		return err
+	}
+	return fmt.Errorf("unknown diff format %q, expected json or text", format)
+}
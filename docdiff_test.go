@@ -0,0 +1,99 @@
+package godoc2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiffDocs(t *testing.T) {
+	old := []*PackageDoc{
+		{Name: "p", ImportPath: "example.com/p", Doc: "Package p.\n",
+			Consts: []ValueDoc{{Names: []string{"A"}, Decl: "const A = 1", Doc: "A is a.\n"}},
+			Funcs:  []FuncDoc{{Name: "F", Decl: "func F()", Doc: "F fs.\n", Pos: Position{File: "p.go", Line: 8}}},
+			Types:  []TypeDoc{{Name: "T", Decl: "type T struct{}", Methods: []FuncDoc{{Name: "M", Recv: "T", Decl: "func (T) M()"}}}}},
+		{Name: "q", ImportPath: "example.com/q"},
+	}
+	new := []*PackageDoc{
+		{Name: "p", ImportPath: "example.com/p", Doc: "Package p.\n",
+			Consts: []ValueDoc{{Names: []string{"A"}, Decl: "const A = 2", Doc: "A is a.\n"}},
+			Funcs:  []FuncDoc{{Name: "F", Decl: "func F()", Doc: "F fs.\n", Pos: Position{File: "p.go", Line: 9}}},
+			Types:  []TypeDoc{{Name: "T", Decl: "type T struct{}", Methods: []FuncDoc{{Name: "N", Recv: "T", Decl: "func (T) N()", Doc: "N ns.\n"}}}}},
+		{Name: "r", ImportPath: "example.com/r", Doc: "Package r.\n"},
+	}
+	expected := `[
+  {
+    "package": "example.com/p",
+    "symbol": "A",
+    "kind": "const",
+    "change": "changed",
+    "before": {
+      "decl": "const A = 1",
+      "doc": "A is a.\n"
+    },
+    "after": {
+      "decl": "const A = 2",
+      "doc": "A is a.\n"
+    }
+  },
+  {
+    "package": "example.com/p",
+    "symbol": "T.N",
+    "kind": "method",
+    "change": "added",
+    "before": null,
+    "after": {
+      "decl": "func (T) N()",
+      "doc": "N ns.\n"
+    }
+  },
+  {
+    "package": "example.com/p",
+    "symbol": "T.M",
+    "kind": "method",
+    "change": "removed",
+    "before": {
+      "decl": "func (T) M()",
+      "doc": ""
+    },
+    "after": null
+  },
+  {
+    "package": "example.com/r",
+    "symbol": "",
+    "kind": "package",
+    "change": "added",
+    "before": null,
+    "after": {
+      "decl": "package r",
+      "doc": "Package r.\n"
+    }
+  },
+  {
+    "package": "example.com/q",
+    "symbol": "",
+    "kind": "package",
+    "change": "removed",
+    "before": {
+      "decl": "package q",
+      "doc": ""
+    },
+    "after": null
+  }
+]
+`
+	var buf bytes.Buffer
+	if err := WriteDocChanges(&buf, DiffDocs(old, new), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != expected {
+		t.Errorf("DiffDocs: expected\n%s\ngot\n%s", expected, got)
+	}
+
+	buf.Reset()
+	if err := WriteDocChanges(&buf, DiffDocs(old, old), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("DiffDocs of the same models: expected [], got %s", got)
+	}
+}
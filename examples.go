@@ -1,85 +1,394 @@
-package main
+package godoc2md
 
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
 	"go/printer"
+	"log"
+	pathpkg "path"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
 )
 
+// exampleLinkFunc returns the anchor of the example of funcName, after
+// "example-", the one generated for its heading: package for the examples
+// of the package.
 func exampleLinkFunc(funcName string) string {
 	i := strings.LastIndex(funcName, "_")
 	if 0 <= i && i < len(funcName)-1 && !startsWithUppercase(funcName[i+1:]) {
 		name := strings.ToLower(funcName[:i])
+		if name == "" {
+			name = "package"
+		}
 		suffix := strings.ToLower(funcName[i+1:])
 		return fmt.Sprintf("%s-%s", name, suffix)
 	}
+	if funcName == "" {
+		return "package"
+	}
 	return strings.ToLower(funcName)
 }
 
+// exampleIDFunc returns the anchor of the heading of the example eg: the
+// explicit "example-" anchor when the dialect renders them, the slug of the
+// heading text otherwise, as the examples index and the TOC link to it.
+func (c *Converter) exampleIDFunc(info *godoc.PageInfo, eg *doc.Example) string {
+	if c.htmlAnchors || c.dialect.anchors {
+		return "example-" + exampleLinkFunc(eg.Name)
+	}
+	heading := c.exampleTitleMd(info, eg)
+	if c.exampleTabs != "" {
+		name := stripExampleSuffix(eg.Name)
+		n := 0
+		for _, other := range info.Examples {
+			if stripExampleSuffix(other.Name) == name {
+				n++
+			}
+		}
+		if n > 1 {
+			// the tab group has a single heading, see exampleTabsMd
+			name, _ = splitExampleName(eg.Name)
+			heading = "Examples " + name + ":"
+		}
+	}
+	return c.kebabFunc(slugText(mdLinkRx.ReplaceAllString(heading, "$1")))
+}
+
+// exampleTitle holds the fields available to the example title template.
+type exampleTitle struct {
+	Name      string // name of the example, without suffix, e.g. "Client_Name"
+	Suffix    string // formatted suffix, e.g. " (Second)"
+	RawSuffix string // suffix as written, e.g. "second"
+	Link      string // source link to the example
+}
+
 // Based on example_textFunc from
 // https://github.com/golang/tools/blob/master/godoc/godoc.go
-func exampleMdFunc(info *godoc.PageInfo, funcName string) string {
-	if !*showExamples {
+func (c *Converter) exampleMdFunc(info *godoc.PageInfo, funcName string) string {
+	return c.exampleMdAtFunc(info, funcName, c.exampleLevel)
+}
+
+// hasExampleFunc reports whether the package has examples for funcName,
+// so that templates can render an Examples subsection conditionally.
+func hasExampleFunc(info *godoc.PageInfo, funcName string) bool {
+	for _, eg := range info.Examples {
+		if stripExampleSuffix(eg.Name) == funcName {
+			return true
+		}
+	}
+	return false
+}
+
+// exampleMdAtFunc is like exampleMdFunc, with example headings at the
+// given level.
+func (c *Converter) exampleMdAtFunc(info *godoc.PageInfo, funcName string, level int) string {
+	if !c.showExamples {
+		return ""
+	}
+
+	var examples []*doc.Example
+	for _, eg := range info.Examples {
+		if stripExampleSuffix(eg.Name) == funcName {
+			examples = append(examples, eg)
+		}
+	}
+	return c.examplesMd(info, examples, level)
+}
+
+// examplesMd renders the examples with headings at the given level, as
+// tabs when enabled and there are several.
+func (c *Converter) examplesMd(info *godoc.PageInfo, examples []*doc.Example, level int) string {
+	if len(examples) == 0 {
 		return ""
 	}
+	if c.exampleTabs != "" && len(examples) > 1 {
+		return c.exampleTabsMd(info, examples, level)
+	}
 
 	var buf bytes.Buffer
-	first := true
+	for i, eg := range examples {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(c.anchorFunc("example-" + exampleLinkFunc(eg.Name)))
+		buf.WriteString(headingPrefix(level + c.headingOffset))
+		buf.WriteString(c.exampleTitleMd(info, eg))
+		buf.WriteString("\n")
+		buf.WriteString(c.exampleBodyMd(info, eg))
+	}
+	return buf.String()
+}
+
+// addSkippedExamples adds to info the examples of the test files of its
+// package that godoc skips because they are about no exported symbol,
+// such as ExampleClient_Missing or Examplehelper, to be rendered in the
+// Other examples section.
+func (c *Converter) addSkippedExamples(info *godoc.PageInfo) {
+	if !c.showExamples || info.PDoc == nil || info.FSet == nil || info.IsFiltered {
+		return
+	}
+	files := c.testFiles(info)
+	known := make(map[string]bool, len(info.Examples))
 	for _, eg := range info.Examples {
-		name := stripExampleSuffix(eg.Name)
-		if name != funcName {
+		known[eg.Name] = true
+	}
+	for _, eg := range doc.Examples(files...) {
+		if !known[eg.Name] {
+			info.Examples = append(info.Examples, eg)
+		}
+	}
+}
+
+// testFiles parses the test files of the package of info, of the package
+// itself or of its _test package, into info.FSet.
+func (c *Converter) testFiles(info *godoc.PageInfo) []*ast.File {
+	infos, err := c.fs.ReadDir(info.Dirname)
+	if err != nil {
+		return nil
+	}
+	var files []*ast.File
+	for _, fi := range infos {
+		name := pathpkg.Join(info.Dirname, fi.Name())
+		if fi.IsDir() || !strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := vfs.ReadFile(c.fs, name)
+		if err != nil {
 			continue
 		}
+		file, err := parser.ParseFile(info.FSet, name, src, parser.ParseComments)
+		if err != nil || (file.Name.Name != info.PDoc.Name && file.Name.Name != info.PDoc.Name+"_test") {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
 
-		if !first {
-			buf.WriteString("\n")
+// otherExamples returns the examples of the package of info that the
+// templates render under no symbol: those added by addSkippedExamples,
+// and those of the symbols left out of the output.
+func (c *Converter) otherExamples(info *godoc.PageInfo) []*doc.Example {
+	if info.PDoc == nil {
+		return nil
+	}
+	attached := map[string]bool{"": true}
+	for _, f := range info.PDoc.Funcs {
+		attached[f.Name] = true
+	}
+	for _, t := range info.PDoc.Types {
+		attached[t.Name] = true
+		for _, f := range append(t.Funcs, c.optionFuncs[t.Name]...) {
+			attached[f.Name] = true
+		}
+		for _, m := range t.Methods {
+			attached[t.Name+"_"+m.Name] = true
+		}
+	}
+	var examples []*doc.Example
+	for _, eg := range info.Examples {
+		if !attached[stripExampleSuffix(eg.Name)] {
+			examples = append(examples, eg)
+		}
+	}
+	return examples
+}
+
+// otherExamplesMdFunc returns the Other examples section of the package
+// of info, rendering the examples attached to no symbol, see
+// otherExamples, or the empty string if there are none.
+func (c *Converter) otherExamplesMdFunc(info *godoc.PageInfo) string {
+	if !c.showExamples {
+		return ""
+	}
+	examples := c.otherExamples(info)
+	if len(examples) == 0 {
+		return ""
+	}
+	return c.anchorFunc("pkg-other-examples") + c.headingFunc("section", 2) +
+		"<a name=\"pkg-other-examples\">Other examples</a>\n\n" +
+		c.examplesMd(info, examples, c.exampleLevel) + "\n"
+}
+
+// headingPrefix returns the Markdown prefix of a heading of the given level.
+func headingPrefix(level int) string {
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level) + " "
+}
+
+// exampleTitleMd renders the title of an example with the example title template.
+func (c *Converter) exampleTitleMd(info *godoc.PageInfo, eg *doc.Example) string {
+	name, suffix := splitExampleName(eg.Name)
+	if name == "" {
+		// package example, named as in the index
+		name = "Package"
+	}
+	title := exampleTitle{
+		Name:   name,
+		Suffix: suffix,
+		Link:   c.exampleSourceLink(info, eg),
+	}
+	if suffix != "" {
+		title.RawSuffix = eg.Name[strings.LastIndex(eg.Name, "_")+1:]
+	}
+	var buf bytes.Buffer
+	if err := c.exampleTitleTmpl.Execute(&buf, title); err != nil {
+		log.Printf("example title: %v", err)
+	}
+	return buf.String()
+}
+
+// exampleTabsMd renders the examples of a single symbol as a tab group,
+// with one tab per example suffix.
+func (c *Converter) exampleTabsMd(info *godoc.PageInfo, examples []*doc.Example, level int) string {
+	var buf bytes.Buffer
+	name, _ := splitExampleName(examples[0].Name)
+	for _, eg := range examples {
+		buf.WriteString(c.anchorFunc("example-" + exampleLinkFunc(eg.Name)))
+	}
+	fmt.Fprintf(&buf, "%sExamples %s:\n", headingPrefix(level+c.headingOffset), name)
+	switch c.exampleTabs {
+	case "docusaurus":
+		buf.WriteString("<Tabs>\n")
+		for _, eg := range examples {
+			label := exampleTabLabel(eg.Name)
+			fmt.Fprintf(&buf, "<TabItem value=%q label=%q>\n\n", strings.ToLower(label), label)
+			fmt.Fprintf(&buf, "[source](%s)\n\n", c.exampleSourceLink(info, eg))
+			buf.WriteString(c.exampleBodyMd(info, eg))
+			buf.WriteString("</TabItem>\n")
 		}
-		first = false
-
-		// print code
-		cnode := &printer.CommentedNode{Node: eg.Code, Comments: eg.Comments}
-		config := &printer.Config{Mode: printer.UseSpaces, Tabwidth: pres.TabWidth}
-		var buf1 bytes.Buffer
-		config.Fprint(&buf1, info.FSet, cnode)
-		code := buf1.String()
-		output := strings.Trim(eg.Output, "\n")
-		output = replaceLeadingIndentation(output, strings.Repeat(" ", pres.TabWidth), "")
-
-		// Additional formatting if this is a function body. Unfortunately, we
-		// can't print statements individually because we would lose comments
-		// on later statements.
-		if n := len(code); n >= 2 && code[0] == '{' && code[n-1] == '}' {
-			// remove surrounding braces
-			code = code[1 : n-1]
-			// unindent
-			code = replaceLeadingIndentation(code, strings.Repeat(" ", pres.TabWidth), "")
-		}
-		code = strings.Trim(code, "\n")
-		name, suffix := splitExampleName(eg.Name)
-		title := fmt.Sprintf("##### Example %s%s:\n", name, suffix)
-		buf.WriteString(title)
-		if len(eg.Doc) > 0 {
-			buf.WriteString(eg.Doc)
+		buf.WriteString("</Tabs>\n\n")
+	case "mkdocs":
+		for _, eg := range examples {
+			fmt.Fprintf(&buf, "=== %q\n\n", exampleTabLabel(eg.Name))
+			body := fmt.Sprintf("[source](%s)\n\n", c.exampleSourceLink(info, eg))
+			body += strings.TrimRight(c.exampleBodyMd(info, eg), "\n")
+			for _, line := range strings.Split(body, "\n") {
+				if line != "" {
+					buf.WriteString("    ")
+				}
+				buf.WriteString(line)
+				buf.WriteString("\n")
+			}
 			buf.WriteString("\n")
 		}
-		buf.WriteString("``` go\n")
-		buf.WriteString(code)
-		buf.WriteString("\n```\n\n")
-		if len(output) > 0 {
+	}
+	return buf.String()
+}
+
+// exampleTabLabel returns the tab label of an example: its title-cased
+// suffix, or "Default" for the unsuffixed example.
+func exampleTabLabel(exampleName string) string {
+	_, suffix := splitExampleName(exampleName)
+	if suffix == "" {
+		return "Default"
+	}
+	return strings.Trim(suffix, " ()")
+}
+
+// exampleTabsHeaderFunc returns the preamble required once per document
+// by the selected tab renderer, if any.
+func (c *Converter) exampleTabsHeaderFunc() string {
+	if !c.showExamples || c.exampleTabs != "docusaurus" {
+		return ""
+	}
+	return "import Tabs from '@theme/Tabs';\nimport TabItem from '@theme/TabItem';\n"
+}
+
+// exampleSourceLink returns the link to the example function in its _test.go file.
+func (c *Converter) exampleSourceLink(info *godoc.PageInfo, eg *doc.Example) string {
+	return c.sourceLink(info, eg.Code.Pos(), eg.Code.End())
+}
+
+// exampleOutputRx matches the output comment of an example, as godoc does.
+var exampleOutputRx = regexp.MustCompile(`(?i)//[[:space:]]*(unordered )?output:`)
+
+// exampleBodyMd renders the documentation, code and output of an example.
+// The output comment is dropped from the code, as the output is rendered
+// in a block of its own. Whole-file examples, which declare their own
+// imports and types, are rendered as the complete file.
+func (c *Converter) exampleBodyMd(info *godoc.PageInfo, eg *doc.Example) string {
+	var buf bytes.Buffer
+	code, output := c.exampleCode(info, eg)
+	if len(eg.Doc) > 0 {
+		buf.WriteString(eg.Doc)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("``` go\n")
+	buf.WriteString(code)
+	buf.WriteString("\n```\n\n")
+	if len(output) > 0 {
+		if eg.Unordered {
+			buf.WriteString("Unordered output:\n")
+		} else {
 			buf.WriteString("Output:\n")
-			buf.WriteString("\n```\n")
-			buf.WriteString(output)
-			buf.WriteString("\n```\n\n")
 		}
+		buf.WriteString("\n```\n")
+		buf.WriteString(output)
+		buf.WriteString("\n```\n\n")
 	}
 	return buf.String()
 }
 
+// exampleCode returns the code and the output of an example, without the
+// output comment.
+func (c *Converter) exampleCode(info *godoc.PageInfo, eg *doc.Example) (code, output string) {
+	// print code
+	cnode := &printer.CommentedNode{Node: eg.Code, Comments: eg.Comments}
+	config := &printer.Config{Mode: printer.UseSpaces, Tabwidth: c.pres.TabWidth}
+	var buf1 bytes.Buffer
+	config.Fprint(&buf1, info.FSet, cnode)
+	code = buf1.String()
+	output = strings.Trim(eg.Output, "\n")
+	output = replaceLeadingIndentation(output, strings.Repeat(" ", c.pres.TabWidth), "")
+
+	// Additional formatting if this is a function body. Unfortunately, we
+	// can't print statements individually because we would lose comments
+	// on later statements.
+	if n := len(code); n >= 2 && code[0] == '{' && code[n-1] == '}' {
+		// remove surrounding braces
+		code = code[1 : n-1]
+		// unindent
+		code = replaceLeadingIndentation(code, strings.Repeat(" ", c.pres.TabWidth), "")
+		// remove output comment
+		if loc := exampleOutputRx.FindStringIndex(code); loc != nil && output != "" {
+			code = strings.TrimSpace(code[:loc[0]])
+		}
+	} else if _, ok := eg.Code.(*ast.File); ok && output != "" {
+		code = stripOutputComment(code)
+	}
+	return strings.Trim(code, "\n"), output
+}
+
+// stripOutputComment removes the lines of the output comment from the
+// code of a whole file example.
+func stripOutputComment(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") || !exampleOutputRx.MatchString(line) {
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[j]), "//") {
+			j++
+		}
+		return strings.Join(append(lines[:i], lines[j:]...), "\n")
+	}
+	return code
+}
+
 // Copy/pasted from https://github.com/golang/tools/blob/master/godoc/godoc.go
 func splitExampleName(s string) (name, suffix string) {
 	i := strings.LastIndex(s, "_")
@@ -0,0 +1,77 @@
+package godoc2md
+
+import (
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/godoc"
+)
+
+func TestExampleCode(t *testing.T) {
+	testData := []struct {
+		src    string
+		code   string
+		output string
+	}{
+		{"package p_test\n\nimport \"fmt\"\n\nfunc ExampleF() {\n\t// Prints.\n\tfmt.Println(\"hi\")\n\t// Output: hi\n}\n",
+			"// Prints.\nfmt.Println(\"hi\")", "hi"},
+		{"package p_test\n\nimport \"fmt\"\n\ntype T struct{}\n\n// String is said.\nfunc (T) String() string { return \"hi\" }\n\nfunc ExampleT() {\n\tfmt.Println(T{})\n\t// Output:\n\t// hi\n}\n",
+			"package p_test\n\nimport \"fmt\"\n\ntype T struct{}\n\n// String is said.\nfunc (T) String() string { return \"hi\" }\n\nfunc ExampleT() {\n    fmt.Println(T{})\n}", "hi"},
+		{"package p_test\n\nimport \"fmt\"\n\ntype T struct{}\n\nfunc ExampleT() {\n\tfmt.Println(T{})\n\t// Unordered output: {}\n}\n",
+			"package p_test\n\nimport \"fmt\"\n\ntype T struct{}\n\nfunc ExampleT() {\n    fmt.Println(T{})\n}", "{}"},
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, tt := range testData {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p_test.go", tt.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		egs := doc.Examples(file)
+		if len(egs) != 1 {
+			t.Fatalf("%d: %d examples, expected 1", n, len(egs))
+		}
+		code, output := c.exampleCode(&godoc.PageInfo{FSet: fset}, egs[0])
+		if code != tt.code || output != tt.output {
+			t.Errorf("%d: exampleCode = %q, %q, expected %q, %q", n, code, output, tt.code, tt.output)
+		}
+	}
+}
+
+func TestExampleIndexLinks(t *testing.T) {
+	src := "package p_test\n\nfunc Example() {}\n\nfunc ExampleNew() {}\n\nfunc ExampleNew_second() {}\n\nfunc ExampleT_Do() {}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p_test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &godoc.PageInfo{FSet: fset, PDoc: &doc.Package{Name: "p", ImportPath: "example.com/p"}, Examples: doc.Examples(file)}
+	for dialect := range dialects {
+		for _, tabs := range []string{"", "mkdocs"} {
+			c, err := New(WithDialect(dialect), WithExamples(true), WithExampleTabs(tabs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var md strings.Builder
+			for _, eg := range info.Examples {
+				md.WriteString("* [" + eg.Name + "](#" + c.exampleIDFunc(info, eg) + ")\n")
+			}
+			for _, name := range []string{"", "New", "T_Do"} {
+				md.WriteString("\n" + c.exampleMdAtFunc(info, name, 4))
+			}
+			slug := c.kebabFunc
+			if c.dialect.anchors {
+				slug = nil
+			}
+			if findings := verifyMarkdown([]byte(md.String()), slug); len(findings) > 0 {
+				t.Errorf("%s, tabs %q: %s", dialect, tabs, strings.Join(findings, "; "))
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	pathpkg "path"
+
+	"golang.org/x/tools/godoc"
+)
+
+// excludedFile reports whether the Go file name matches one of the
+// patterns set with WithExcludeFiles.
+func (c *Converter) excludedFile(name string) bool {
+	for _, pattern := range c.excludeFiles {
+		if ok, _ := pathpkg.Match(pattern, pathpkg.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeDecls removes from info the declarations of the files matching
+// the patterns set with WithExcludeFiles, such as "*_gen.go", and the
+// symbols whose names match the regular expression set with
+// WithExcludeSymbols, the methods by their name or by T.M, along with
+// their examples, and the declarations with a skip directive, see
+// declDirectives.
+func (c *Converter) excludeDecls(info *godoc.PageInfo) {
+	if info.PDoc == nil {
+		return
+	}
+	excluded := func(decl ast.Node, names ...string) bool {
+		if decl != nil && (c.excludedFile(info.FSet.Position(decl.Pos()).Filename) || c.directives(info, decl).skip) {
+			return true
+		}
+		if c.excludeSymbolsRx == nil || len(names) == 0 {
+			return false
+		}
+		for _, name := range names {
+			if !c.excludeSymbolsRx.MatchString(name) {
+				return false
+			}
+		}
+		return true
+	}
+	removed := make(map[string]bool) // example names of the removed symbols
+	values := func(values []*doc.Value) []*doc.Value {
+		var kept []*doc.Value
+		for _, v := range values {
+			if !excluded(v.Decl, v.Names...) {
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	}
+	funcs := func(funcs []*doc.Func, tname string) []*doc.Func {
+		var kept []*doc.Func
+		for _, f := range funcs {
+			if tname == "" && !excluded(f.Decl, f.Name) ||
+				tname != "" && !excluded(f.Decl, f.Name) && !excluded(nil, tname+"."+f.Name) {
+				kept = append(kept, f)
+			} else if tname == "" {
+				removed[f.Name] = true
+			} else {
+				removed[tname+"_"+f.Name] = true
+			}
+		}
+		return kept
+	}
+
+	pdoc := info.PDoc
+	pdoc.Consts, pdoc.Vars = values(pdoc.Consts), values(pdoc.Vars)
+	pdoc.Funcs = funcs(pdoc.Funcs, "")
+	var types []*doc.Type
+	for _, t := range pdoc.Types {
+		if excluded(t.Decl, t.Name) {
+			removed[t.Name] = true
+			for _, f := range t.Funcs {
+				removed[f.Name] = true
+			}
+			for _, m := range t.Methods {
+				removed[t.Name+"_"+m.Name] = true
+			}
+			continue
+		}
+		t.Consts, t.Vars = values(t.Consts), values(t.Vars)
+		t.Funcs, t.Methods = funcs(t.Funcs, ""), funcs(t.Methods, t.Name)
+		types = append(types, t)
+	}
+	pdoc.Types = types
+
+	var filenames []string
+	for _, name := range pdoc.Filenames {
+		if !c.excludedFile(name) {
+			filenames = append(filenames, name)
+		}
+	}
+	pdoc.Filenames = filenames
+
+	var examples []*doc.Example
+	for _, eg := range info.Examples {
+		if !removed[stripExampleSuffix(eg.Name)] {
+			examples = append(examples, eg)
+		}
+	}
+	info.Examples = examples
+}
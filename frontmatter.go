@@ -0,0 +1,94 @@
+package godoc2md
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// frontMatterDelims are the delimiters of the front matter formats.
+var frontMatterDelims = map[string]string{
+	"yaml": "---",
+	"toml": "+++",
+}
+
+// frontMatterKeys are the leading front matter fields, in order. The
+// other fields follow in alphabetical order.
+var frontMatterKeys = []string{"title", "slug", "weight", "description"}
+
+// frontMatter returns the front matter of the documentation of info,
+// followed by a blank line, or the empty string if disabled. The title,
+// the description and the tags are those of the PackageMeta of the
+// package, and the slug defaults to the package name; the fields set
+// with WithFrontMatter override them.
+func (c *Converter) frontMatter(info *godoc.PageInfo) string {
+	delim, ok := frontMatterDelims[c.frontMatterFormat]
+	if !ok || info.PDoc == nil {
+		return ""
+	}
+	name := info.PDoc.Name
+	if info.IsMain {
+		name = path.Base(info.PDoc.ImportPath)
+	}
+	meta := c.packageMeta(info)
+	fields := map[string]string{
+		"title":       meta.Title,
+		"slug":        name,
+		"description": meta.Description,
+	}
+	var tags []string // the list of the tags, unless overridden
+	if len(meta.Tags) > 0 {
+		for _, tag := range meta.Tags {
+			tags = append(tags, strconv.Quote(tag))
+		}
+		fields["tags"] = "[" + strings.Join(tags, ", ") + "]"
+	}
+	for k, v := range c.frontMatterFields {
+		fields[k] = v
+		if k == "tags" {
+			tags = nil
+		}
+	}
+
+	keys := append([]string(nil), frontMatterKeys...)
+	var others []string
+	for k := range fields {
+		if !isFrontMatterKey(k) {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	sep := ": "
+	if c.frontMatterFormat == "toml" {
+		sep = " = "
+	}
+	var buf strings.Builder
+	buf.WriteString(delim + "\n")
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok || v == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(v); err != nil && (k != "tags" || tags == nil) {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&buf, "%s%s%s\n", k, sep, v)
+	}
+	buf.WriteString(delim + "\n\n")
+	return buf.String()
+}
+
+func isFrontMatterKey(k string) bool {
+	for _, key := range frontMatterKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
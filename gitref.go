@@ -0,0 +1,61 @@
+package godoc2md
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitHead returns the current branch of the git repository holding dir,
+// or its current commit if commit is set, to be linked to with WithRef.
+func GitHead(dir string, commit bool) (string, error) {
+	args := []string{"-C", dir, "rev-parse"}
+	if !commit {
+		args = append(args, "--abbrev-ref")
+	}
+	out, err := exec.Command("git", append(args, "HEAD")...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("git rev-parse: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git rev-parse: %v", err)
+	}
+	ref := strings.TrimSpace(string(out))
+	if ref == "HEAD" {
+		return "", fmt.Errorf("%s: detached HEAD, no current branch", dir)
+	}
+	return ref, nil
+}
+
+// GitDescribe returns the version of the git repository holding dir:
+// its most recent tag, followed by the number of commits since and the
+// current commit if HEAD isn't tagged, such as v1.2.0-3-g1a2b3c4.
+func GitDescribe(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "describe", "--tags").Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("git describe: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git describe: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitShow returns the content of the file name at the revision ref of
+// the git repository holding it, such as a previous output to be set
+// with WithPrevious.
+func GitShow(ref, name string) ([]byte, error) {
+	dir, file := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	out, err := exec.Command("git", "-C", dir, "show", ref+":./"+filepath.ToSlash(file)).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("git show: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("git show: %v", err)
+	}
+	return out, nil
+}
@@ -1,3 +1,14 @@
 module github.com/davecheney/godoc2md
 
-require golang.org/x/tools v0.0.0-20181011021141-0e57ebad1d6b
+go 1.23.0
+
+require (
+	github.com/yuin/goldmark v1.4.13
+	golang.org/x/tools v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.27.0
+	golang.org/x/sync v0.16.0 // indirect
+)
@@ -0,0 +1,173 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A grpcMethod is an RPC of a gRPC service, its request and response
+// types, and whether it streams them: "unary", "server", "client" or
+// "bidirectional".
+type grpcMethod struct {
+	name              string
+	request, response ast.Expr
+	streaming         string
+}
+
+// fieldTypes returns the types of the fields of fl, one per name.
+func fieldTypes(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, f := range fl.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, f.Type)
+		}
+	}
+	return types
+}
+
+// grpcStream returns the streaming of the stream argument of type x of a
+// method of a service interface, its request and response types: x is
+// grpc.ServerStreamingServer[Resp], grpc.ClientStreamingServer[Req, Resp]
+// or grpc.BidiStreamingServer[Req, Resp], or an interface with the
+// methods Send or SendAndClose, and Recv, as generated by the earlier
+// versions of protoc-gen-go-grpc.
+func grpcStream(x ast.Expr, specs map[string]*ast.TypeSpec) (streaming string, request, response ast.Expr) {
+	if id, ok := x.(*ast.Ident); ok && specs[id.Name] != nil {
+		x = specs[id.Name].Type
+	}
+	var args []ast.Expr
+	switch ix := x.(type) {
+	case *ast.IndexExpr:
+		x, args = ix.X, []ast.Expr{ix.Index}
+	case *ast.IndexListExpr:
+		x, args = ix.X, ix.Indices
+	case *ast.InterfaceType:
+		var send, sendAndClose bool
+		for _, m := range ix.Methods.List {
+			ft, ok := m.Type.(*ast.FuncType)
+			if !ok || len(m.Names) == 0 {
+				continue
+			}
+			switch m.Names[0].Name {
+			case "Send", "SendAndClose":
+				if params := fieldTypes(ft.Params); len(params) == 1 {
+					response = params[0]
+					send, sendAndClose = m.Names[0].Name == "Send", m.Names[0].Name == "SendAndClose"
+				}
+			case "Recv":
+				if results := fieldTypes(ft.Results); len(results) == 2 {
+					request = results[0]
+				}
+			}
+		}
+		switch {
+		case sendAndClose:
+			return "client", request, response
+		case send && request != nil:
+			return "bidirectional", request, response
+		case send:
+			return "server", nil, response
+		}
+		return "", nil, nil
+	}
+	sel, ok := x.(*ast.SelectorExpr)
+	if !ok {
+		return "", nil, nil
+	}
+	switch {
+	case sel.Sel.Name == "ServerStreamingServer" && len(args) == 1:
+		return "server", nil, args[0]
+	case sel.Sel.Name == "ClientStreamingServer" && len(args) == 2:
+		return "client", args[0], args[1]
+	case sel.Sel.Name == "BidiStreamingServer" && len(args) == 2:
+		return "bidirectional", args[0], args[1]
+	}
+	return "", nil, nil
+}
+
+// grpcMethods returns the RPCs of the service interface it, generated by
+// protoc-gen-go-grpc, the methods of other signatures left out.
+func grpcMethods(it *ast.InterfaceType, specs map[string]*ast.TypeSpec) []grpcMethod {
+	var methods []grpcMethod
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 || !ast.IsExported(m.Names[0].Name) {
+			continue
+		}
+		params, results := fieldTypes(ft.Params), fieldTypes(ft.Results)
+		method := grpcMethod{name: m.Names[0].Name}
+		switch {
+		case len(params) == 2 && len(results) == 2:
+			method.streaming, method.request, method.response = "unary", params[1], results[0]
+		case (len(params) == 1 || len(params) == 2) && len(results) == 1:
+			method.streaming, method.request, method.response = grpcStream(params[len(params)-1], specs)
+			if len(params) == 2 {
+				method.request = params[0]
+			}
+		}
+		if method.streaming != "" && method.request != nil && method.response != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// grpcServicesMdFunc returns the summary of the gRPC services of the
+// package of info, set with WithGRPCServices: a table of their RPCs, with
+// their request and response types and their streaming, linked to their
+// declarations. The services are the interfaces XServer generated by
+// protoc-gen-go-grpc, with their UnimplementedXServer type.
+func (c *Converter) grpcServicesMdFunc(info *godoc.PageInfo) string {
+	if !c.grpcServices || info.PDoc == nil {
+		return ""
+	}
+	specs := make(map[string]*ast.TypeSpec)
+	for _, t := range info.PDoc.Types {
+		if ts := typeSpec(t); ts != nil {
+			specs[t.Name] = ts
+		}
+	}
+	typeLink := func(x ast.Expr) string {
+		if star, ok := x.(*ast.StarExpr); ok {
+			x = star.X
+		}
+		if id, ok := x.(*ast.Ident); ok && specs[id.Name] != nil {
+			return "[" + c.mdFunc(id.Name) + "](#" + id.Name + ")"
+		}
+		return c.mdFunc(types.ExprString(x))
+	}
+	var rows strings.Builder
+	for _, t := range info.PDoc.Types {
+		service := strings.TrimSuffix(t.Name, "Server")
+		if service == t.Name || specs[t.Name] == nil || specs["Unimplemented"+t.Name] == nil {
+			continue
+		}
+		it, ok := specs[t.Name].Type.(*ast.InterfaceType)
+		if !ok {
+			continue
+		}
+		title := c.mdFunc(service) + " ([server](#" + t.Name + "))"
+		if specs[service+"Client"] != nil {
+			title = c.mdFunc(service) + " ([client](#" + service + "Client), [server](#" + t.Name + "))"
+		}
+		for _, m := range grpcMethods(it, specs) {
+			rows.WriteString("| " + title + " | [" + c.mdFunc(m.name) + "](#" + t.Name + ") | " + typeLink(m.request) + " | " + typeLink(m.response) + " | " + m.streaming + " |\n")
+			title = ""
+		}
+	}
+	if rows.Len() == 0 {
+		return ""
+	}
+	return c.anchorFunc("pkg-grpc-services") + c.headingFunc("subsection", 4) + "<a name=\"pkg-grpc-services\">gRPC services</a>\n\n" +
+		"| Service | Method | Request | Response | Streaming |\n| --- | --- | --- | --- | --- |\n" + rows.String() + "\n"
+}
@@ -0,0 +1,155 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	pathpkg "path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// hazardousReflect lists the members of package reflect that defeat the
+// type system, flagged along with the uses of package unsafe.
+var hazardousReflect = map[string]bool{
+	"NewAt":         true,
+	"SliceHeader":   true,
+	"StringHeader":  true,
+	"UnsafeAddr":    true,
+	"UnsafePointer": true,
+}
+
+// findHazards returns the hazardous features used by the implementation
+// of each function and method of the package directory dir of fs, keyed
+// by Name or Recv.Name: package unsafe, the unsafe parts of package
+// reflect, and //go:linkname directives.
+func findHazards(fs vfs.NameSpace, dir string) map[string][]string {
+	hazards := make(map[string][]string)
+	for _, file := range parseFiles(fs, dir) {
+		fileHazards(file, hazards)
+	}
+	return hazards
+}
+
+// parseFiles parses the Go files of the package directory dir of fs,
+// with their comments and function bodies, which the documentation of
+// the package lacks. The test files and the files that don't parse are
+// skipped.
+func parseFiles(fs vfs.NameSpace, dir string) []*ast.File {
+	_, files := parseFileSet(fs, dir)
+	return files
+}
+
+// parseFileSet is like parseFiles, but also returns the file set of the
+// files, whose names are relative to dir.
+func parseFileSet(fs vfs.NameSpace, dir string) (*token.FileSet, []*ast.File) {
+	fset := token.NewFileSet()
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return fset, nil
+	}
+	var files []*ast.File
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := vfs.ReadFile(fs, pathpkg.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+	return fset, files
+}
+
+// fileHazards adds the hazards of the functions declared in file.
+func fileHazards(file *ast.File, hazards map[string][]string) {
+	imported := make(map[string]string) // local name -> import path
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := pathpkg.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imported[name] = path
+	}
+	linknamed := make(map[string]bool)
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if f := strings.Fields(c.Text); len(f) >= 2 && f[0] == "//go:linkname" {
+				linknamed[f[1]] = true
+			}
+		}
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		found := make(map[string]bool)
+		if fn.Recv == nil && linknamed[fn.Name.Name] {
+			found["//go:linkname"] = true
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if id, ok := sel.X.(*ast.Ident); ok {
+				switch imported[id.Name] {
+				case "unsafe":
+					found["package unsafe"] = true
+					return true
+				case "reflect":
+					if hazardousReflect[sel.Sel.Name] {
+						found["reflect."+sel.Sel.Name] = true
+					}
+					return true
+				}
+			}
+			if (sel.Sel.Name == "UnsafeAddr" || sel.Sel.Name == "UnsafePointer") && imported["reflect"] != "" {
+				found["reflect.Value."+sel.Sel.Name] = true
+			}
+			return true
+		})
+		if len(found) == 0 {
+			continue
+		}
+		var list []string
+		for h := range found {
+			list = append(list, h)
+		}
+		sort.Strings(list)
+		name, _ := typeParams(fn)
+		hazards[name] = list
+	}
+}
+
+// hazardMdFunc returns a warning admonition listing the hazardous
+// features used by the implementation of the function declared by decl,
+// when hazards are flagged.
+func (c *Converter) hazardMdFunc(info *godoc.PageInfo, decl ast.Node) string {
+	if !c.hazards {
+		return ""
+	}
+	if c.hazardsByFunc == nil {
+		c.hazardsByFunc = findHazards(c.fs, info.Dirname)
+	}
+	name, _ := typeParams(decl)
+	list := c.hazardsByFunc[name]
+	if len(list) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("> [!WARNING]\n> The implementation uses %s, and may break with new Go releases.\n\n", strings.Join(list, ", "))
+}
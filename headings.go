@@ -0,0 +1,44 @@
+package godoc2md
+
+import "fmt"
+
+// headingRoles are the kinds of headings of the built-in templates, with
+// their level in the flat layout: the title of the package, its sections
+// such as the overview and the index, the headings of functions and
+// types, of the methods and constructors of types, the subsections such
+// as the list of examples, and the headings of doc comments.
+var headingRoles = map[string]int{
+	"title":      1,
+	"section":    2,
+	"symbol":     2,
+	"member":     3,
+	"subsection": 4,
+	"comment":    3,
+}
+
+// checkHeadings checks the heading offset and levels set with
+// WithHeadingOffset and WithHeadingLevels.
+func (c *Converter) checkHeadings() error {
+	if c.headingOffset < -5 || c.headingOffset > 5 {
+		return fmt.Errorf("heading offset: %d is out of range, want -5 to 5", c.headingOffset)
+	}
+	for role, level := range c.headingLevels {
+		if _, ok := headingRoles[role]; !ok {
+			return fmt.Errorf("heading levels: unknown role %q, want title, section, symbol, member, subsection or comment", role)
+		}
+		if level < 1 || level > 6 {
+			return fmt.Errorf("heading levels: level %d of %s is out of range, want 1 to 6", level, role)
+		}
+	}
+	return nil
+}
+
+// headingFunc returns the prefix of a heading of role whose level is
+// level in the built-in template, moved by the level set for role with
+// WithHeadingLevels, if any, and by the heading offset.
+func (c *Converter) headingFunc(role string, level int) string {
+	if l, ok := c.headingLevels[role]; ok {
+		level += l - headingRoles[role]
+	}
+	return headingPrefix(level + c.headingOffset)
+}
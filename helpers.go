@@ -0,0 +1,81 @@
+package godoc2md
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// The string helpers of the templates take the string they operate on
+// last, like those of sprig, for it to be piped, as in
+// {{.Doc | trim | indent 4}}.
+
+// indentFunc indents each non-empty line of s with n spaces.
+func indentFunc(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replaceFunc replaces the occurrences of old in s with new.
+func replaceFunc(old, new, s string) string {
+	return strings.Replace(s, old, new, -1)
+}
+
+// regexMatchFunc reports whether s matches the regular expression expr.
+func regexMatchFunc(expr, s string) (bool, error) {
+	return regexp.MatchString(expr, s)
+}
+
+// regexReplaceFunc replaces the matches of the regular expression expr in
+// s with repl, which may refer to the submatches as $1 or ${name}.
+func regexReplaceFunc(expr, repl, s string) (string, error) {
+	rx, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+	return rx.ReplaceAllString(s, repl), nil
+}
+
+// defaultFunc returns value, or def if value is empty: nil, the zero
+// value of its type, or an empty slice or map.
+func defaultFunc(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || value[0] == nil {
+		return def
+	}
+	v := reflect.ValueOf(value[0])
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	default:
+		if v.IsZero() {
+			return def
+		}
+	}
+	return value[0]
+}
+
+// joinFunc joins the elements of the slice list, printed with fmt.Sprint
+// unless they are strings, with sep.
+func joinFunc(sep string, list interface{}) (string, error) {
+	if ss, ok := list.([]string); ok {
+		return strings.Join(ss, sep), nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %T isn't a slice", list)
+	}
+	ss := make([]string, v.Len())
+	for i := range ss {
+		ss[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(ss, sep), nil
+}
@@ -0,0 +1,125 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/doc"
+	"go/doc/comment"
+	"regexp"
+	"strings"
+)
+
+// identRefRx matches the identifiers and selector expressions, such as
+// Type.Method, mentioned in prose.
+var identRefRx = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?`)
+
+// exportedIdents returns the exported identifiers declared by pkg: its
+// constants, variables, functions and types, and the methods of its
+// types as "Type.Method".
+func exportedIdents(pkg *doc.Package) map[string]bool {
+	idents := make(map[string]bool)
+	values := func(vs []*doc.Value) {
+		for _, v := range vs {
+			for _, name := range v.Names {
+				idents[name] = true
+			}
+		}
+	}
+	funcs := func(fs []*doc.Func) {
+		for _, f := range fs {
+			idents[f.Name] = true
+		}
+	}
+	values(pkg.Consts)
+	values(pkg.Vars)
+	funcs(pkg.Funcs)
+	for _, t := range pkg.Types {
+		idents[t.Name] = true
+		values(t.Consts)
+		values(t.Vars)
+		funcs(t.Funcs)
+		for _, m := range t.Methods {
+			idents[t.Name+"."+m.Name] = true
+		}
+	}
+	for name := range idents {
+		if !startsWithUppercase(name) {
+			delete(idents, name)
+		}
+	}
+	return idents
+}
+
+// isIdentByte reports whether b may be part of an identifier, or of
+// code quoted with backticks.
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '`' || b >= 0x80 || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// identsMD renders the identifiers mentioned in the plain text of a doc
+// comment: the identifiers qualified with the name of another package,
+// such as io.Reader, are linked to their documentation when package
+// links are enabled, and the exported identifiers of the package are
+// wrapped in backticks when enabled. Words already quoted are left alone.
+func (c *Converter) identsMD(text string) string {
+	if !c.backticks && !c.pkgLinks || c.pdoc == nil {
+		return text
+	}
+	if c.idents == nil {
+		c.idents = exportedIdents(c.pdoc)
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range identRefRx.FindAllStringIndex(text, -1) {
+		start, end := m[0], m[1]
+		if start > 0 && isIdentByte(text[start-1]) || end < len(text) && isIdentByte(text[end]) {
+			continue
+		}
+		ident := text[start:end]
+		var md string
+		if c.backticks && c.idents[ident] {
+			md = "`" + ident + "`"
+		}
+		if i := strings.Index(ident, "."); i > 0 && c.pkgLinks {
+			if url, ok := c.qualifiedURL(ident[:i], ident[i+1:]); ok {
+				if md == "" {
+					md = ident
+					if c.backticks {
+						md = "`" + ident + "`"
+					}
+				}
+				md = "[" + md + "](" + url + ")"
+			}
+		}
+		if md == "" {
+			continue
+		}
+		buf.WriteString(text[last:start])
+		buf.WriteString(md)
+		last = end
+	}
+	buf.WriteString(text[last:])
+	return buf.String()
+}
+
+// qualifiedURL returns the documentation URL of the exported identifier
+// name of the package imported as pkg, if pkg designates another package:
+// an import of the package being documented or a standard library
+// package, resolved as for a [pkg.Name] doc link.
+func (c *Converter) qualifiedURL(pkg, name string) (string, bool) {
+	if c.pdoc == nil || !startsWithUppercase(name) {
+		return "", false
+	}
+	d := c.pdoc.Parser().Parse("[" + pkg + "." + name + "]")
+	if len(d.Content) != 1 {
+		return "", false
+	}
+	p, ok := d.Content[0].(*comment.Paragraph)
+	if !ok || len(p.Text) != 1 {
+		return "", false
+	}
+	link, ok := p.Text[0].(*comment.DocLink)
+	if !ok || link.ImportPath == "" {
+		return "", false
+	}
+	return link.DefaultURL(c.pkgLinkBase), true
+}
@@ -0,0 +1,131 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// identsSrc is the package mentioned by the doc comments of the tests of
+// identsMD.
+const identsSrc = `package p
+
+import "io"
+
+// Client reads from an [io.Reader].
+type Client struct{ r io.Reader }
+
+// Do does it.
+func (c *Client) Do() {}
+
+// New returns a Client.
+func New() *Client { return nil }
+
+func unexported() {}
+`
+
+// identsPackage returns the documentation of identsSrc.
+func identsPackage(t *testing.T) *doc.Package {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", identsSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdoc, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pdoc
+}
+
+func TestBackticks(t *testing.T) {
+	testData := []struct {
+		text     string
+		expected string
+	}{
+		{"New returns a Client.\n", "`New` returns a `Client`.\n\n"},
+		{"Call Client.Do, not Client.Undo.\n", "Call `Client.Do`, not Client.Undo.\n\n"},
+		{"Already `Client` and `New()`.\n", "Already `Client` and `New()`.\n\n"},
+		{"Clients, NewClient and unexported stay.\n", "Clients, NewClient and unexported stay.\n\n"},
+		{"A New\n\n\tNew()\n", "A `New`\n\n\n```\nNew()\n```\n\n"},
+	}
+	pdoc := identsPackage(t)
+	for _, tt := range testData {
+		c := Converter{backticks: true, pkgLinkBase: defaultPkgLinkBase, pdoc: pdoc}
+		var buf bytes.Buffer
+		c.toMD(&buf, tt.text)
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("toMD(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestIdentsMD(t *testing.T) {
+	testData := []struct {
+		text      string
+		backticks bool
+		pkgLinks  bool
+		expected  string
+	}{
+		{"Reads an io.Reader into a Client.", false, false, "Reads an io.Reader into a Client."},
+		{"Reads an io.Reader into a Client.", false, true, "Reads an [io.Reader](https://pkg.go.dev/io#Reader) into a Client."},
+		{"Reads an io.Reader into a Client.", true, false, "Reads an io.Reader into a `Client`."},
+		{"Reads an io.Reader into a Client.", true, true, "Reads an [`io.Reader`](https://pkg.go.dev/io#Reader) into a `Client`."},
+		{"Not io.reader, os.File or `io.Writer`.", true, true, "Not io.reader, [`os.File`](https://pkg.go.dev/os#File) or `io.Writer`."},
+		{"Client.Do is no package.", false, true, "Client.Do is no package."},
+	}
+	pdoc := identsPackage(t)
+	for _, tt := range testData {
+		c := Converter{backticks: tt.backticks, pkgLinks: tt.pkgLinks, pkgLinkBase: defaultPkgLinkBase, pdoc: pdoc}
+		if got := c.identsMD(tt.text); got != tt.expected {
+			t.Errorf("identsMD(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
+
+func TestQualifiedURL(t *testing.T) {
+	testData := []struct {
+		pkg, name string
+		expected  string
+		ok        bool
+	}{
+		{"io", "Reader", "https://pkg.go.dev/io#Reader", true},
+		{"net/http", "Client", "https://pkg.go.dev/net/http#Client", true},
+		{"io", "reader", "", false},
+		{"Client", "Do", "", false},
+		{"nosuchpkg", "Thing", "", false},
+	}
+	c := Converter{pkgLinkBase: defaultPkgLinkBase, pdoc: identsPackage(t)}
+	for _, tt := range testData {
+		if got, ok := c.qualifiedURL(tt.pkg, tt.name); got != tt.expected || ok != tt.ok {
+			t.Errorf("qualifiedURL(%q, %q): expected %q, %t, got %q, %t", tt.pkg, tt.name, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestLinkDeclPkgLinks(t *testing.T) {
+	testData := []struct {
+		text     string
+		name     string
+		params   []string
+		expected string
+		linked   bool
+	}{
+		{"func New() *Client", "New", nil, "<pre>func New() *Client</pre>", false},
+		{"func Read(r io.Reader, ch <-chan []byte) error", "Read", nil,
+			"<pre>func Read(r <a href=\"https://pkg.go.dev/io#Reader\">io.Reader</a>, ch &lt;-chan []byte) error</pre>", true},
+		{"func Copy[W io.Writer](w W, r io.Reader)", "Copy", []string{"W"},
+			"<pre>func Copy[<a id=\"Copy.W\">W</a> <a href=\"https://pkg.go.dev/io#Writer\">io.Writer</a>](w <a href=\"#Copy.W\">W</a>, r <a href=\"https://pkg.go.dev/io#Reader\">io.Reader</a>)</pre>", true},
+		{"func (c *Client) Do(v Client.Field)", "Client.Do", nil, "<pre>func (c *Client) Do(v Client.Field)</pre>", false},
+	}
+	pdoc := identsPackage(t)
+	for _, tt := range testData {
+		c := Converter{pkgLinks: true, pkgLinkBase: defaultPkgLinkBase, pdoc: pdoc}
+		if got, linked := c.linkDecl(tt.text, tt.name, tt.params); got != tt.expected || linked != tt.linked {
+			t.Errorf("linkDecl(%q): expected %q, %t, got %q, %t", tt.text, tt.expected, tt.linked, got, linked)
+		}
+	}
+}
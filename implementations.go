@@ -0,0 +1,157 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	pathpkg "path"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// A dirImporter imports the packages imported by the files of the
+// directory dir, on disk, whatever the directory the type checker passes.
+type dirImporter struct {
+	imp types.ImporterFrom
+	dir string
+}
+
+func (d dirImporter) Import(path string) (*types.Package, error) {
+	return d.imp.ImportFrom(path, d.dir, 0)
+}
+
+func (d dirImporter) ImportFrom(path, _ string, mode types.ImportMode) (*types.Package, error) {
+	return d.imp.ImportFrom(path, d.dir, mode)
+}
+
+// checkPackage type-checks the package of info from the sources of its
+// files matching the default build context, the packages it imports
+// type-checked from their sources. The type errors are ignored, for the
+// types to be checked as far as possible.
+func (c *Converter) checkPackage(info *godoc.PageInfo) *types.Package {
+	ctxt := build.Default
+	ctxt.JoinPath = pathpkg.Join
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) { return c.fs.Open(name) }
+	fset := token.NewFileSet()
+	infos, err := c.fs.ReadDir(info.Dirname)
+	if err != nil {
+		return nil
+	}
+	var files []*ast.File
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(info.Dirname, name); err != nil || !ok {
+			continue
+		}
+		src, err := vfs.ReadFile(c.fs, pathpkg.Join(info.Dirname, name))
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil || file.Name.Name != info.PDoc.Name {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	dir := c.targetDir
+	if !strings.HasPrefix(info.Dirname, targetPath) {
+		bp, _ := build.Import(info.PDoc.ImportPath, "", build.FindOnly)
+		dir = bp.Dir
+	}
+	config := &types.Config{
+		Importer:    dirImporter{importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom), dir},
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	pkg, _ := config.Check(info.PDoc.ImportPath, fset, files, nil)
+	return pkg
+}
+
+// findImplementations returns the types of the package of info mapped to
+// the interfaces of the package they implement, and the interfaces mapped
+// to the types implementing them, named *T for the types whose pointers
+// only implement them. The generic types and the interfaces without
+// methods are left out.
+func (c *Converter) findImplementations(info *godoc.PageInfo) map[string][]string {
+	impls := make(map[string][]string)
+	pkg := c.checkPackage(info)
+	if pkg == nil {
+		return impls
+	}
+	var named []*types.Named
+	for _, t := range info.PDoc.Types {
+		tn, ok := pkg.Scope().Lookup(t.Name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if n, ok := tn.Type().(*types.Named); ok && n.TypeParams().Len() == 0 {
+			named = append(named, n)
+		}
+	}
+	for _, in := range named {
+		iface, ok := in.Underlying().(*types.Interface)
+		if !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+			continue
+		}
+		for _, n := range named {
+			if types.IsInterface(n) {
+				continue
+			}
+			name := n.Obj().Name()
+			switch {
+			case types.Implements(n, iface):
+			case types.Implements(types.NewPointer(n), iface):
+				name = "*" + name
+			default:
+				continue
+			}
+			impls[in.Obj().Name()] = append(impls[in.Obj().Name()], name)
+			impls[n.Obj().Name()] = append(impls[n.Obj().Name()], in.Obj().Name())
+		}
+	}
+	for _, names := range impls {
+		sort.Strings(names)
+	}
+	return impls
+}
+
+// implementationsMdFunc returns the cross-references of the type tname
+// of the package of info, set with WithImplementations: the types of the
+// package implementing it if it's an interface, or the interfaces of the
+// package it implements, linked to their declarations.
+func (c *Converter) implementationsMdFunc(info *godoc.PageInfo, tname string) string {
+	if !c.implementsRefs || info.PDoc == nil {
+		return ""
+	}
+	if c.implementations == nil {
+		c.implementations = c.findImplementations(info)
+	}
+	names := c.implementations[tname]
+	if len(names) == 0 {
+		return ""
+	}
+	links := make([]string, len(names))
+	for i, name := range names {
+		links[i] = "[" + c.mdFunc(name) + "](#" + strings.TrimPrefix(name, "*") + ")"
+	}
+	title := "Implements"
+	for _, t := range info.PDoc.Types {
+		if ts := typeSpec(t); t.Name == tname && ts != nil {
+			if _, ok := ts.Type.(*ast.InterfaceType); ok {
+				title = "Implemented by"
+			}
+		}
+	}
+	return title + ": " + strings.Join(links, ", ") + "\n\n"
+}
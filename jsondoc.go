@@ -0,0 +1,201 @@
+package godoc2md
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A PackageDoc is the documentation of a package or command, as written
+// by ConvertJSON.
+type PackageDoc struct {
+	Name       string               `json:"name"`
+	ImportPath string               `json:"importPath"`
+	IsCommand  bool                 `json:"isCommand,omitempty"`
+	Synopsis   string               `json:"synopsis"`
+	Doc        string               `json:"doc"`
+	Files      []string             `json:"files"`
+	Consts     []ValueDoc           `json:"consts,omitempty"`
+	Vars       []ValueDoc           `json:"vars,omitempty"`
+	Funcs      []FuncDoc            `json:"funcs,omitempty"`
+	Types      []TypeDoc            `json:"types,omitempty"`
+	Examples   []ExampleDoc         `json:"examples,omitempty"`
+	Notes      map[string][]NoteDoc `json:"notes,omitempty"`
+}
+
+// A Position is the location of a declaration in the sources of a
+// package, with the link to it.
+type Position struct {
+	File    string `json:"file"` // relative to the package directory
+	Line    int    `json:"line"`
+	EndLine int    `json:"endLine"`
+	URL     string `json:"url"`
+}
+
+// A ValueDoc is the documentation of a constant or variable declaration.
+type ValueDoc struct {
+	Names []string `json:"names"`
+	Decl  string   `json:"decl"`
+	Doc   string   `json:"doc"`
+	Pos   Position `json:"pos"`
+}
+
+// A FuncDoc is the documentation of a function or method.
+type FuncDoc struct {
+	Name string   `json:"name"`
+	Recv string   `json:"recv,omitempty"`
+	Decl string   `json:"decl"`
+	Doc  string   `json:"doc"`
+	Pos  Position `json:"pos"`
+}
+
+// A TypeDoc is the documentation of a type, with its associated
+// constants, variables, constructors and methods.
+type TypeDoc struct {
+	Name    string     `json:"name"`
+	Decl    string     `json:"decl"`
+	Doc     string     `json:"doc"`
+	Pos     Position   `json:"pos"`
+	Consts  []ValueDoc `json:"consts,omitempty"`
+	Vars    []ValueDoc `json:"vars,omitempty"`
+	Funcs   []FuncDoc  `json:"funcs,omitempty"`
+	Methods []FuncDoc  `json:"methods,omitempty"`
+}
+
+// An ExampleDoc is an example of a package, named like the example
+// functions without the Example prefix, such as "Client_Do" or "".
+type ExampleDoc struct {
+	Name      string `json:"name"`
+	Doc       string `json:"doc,omitempty"`
+	Code      string `json:"code"`
+	Output    string `json:"output,omitempty"`
+	Unordered bool   `json:"unordered,omitempty"`
+}
+
+// A NoteDoc is a note of a package, such as BUG(uid): body.
+type NoteDoc struct {
+	UID  string   `json:"uid"`
+	Body string   `json:"body"`
+	Pos  Position `json:"pos"`
+}
+
+// ConvertJSON writes to w the documentation of the package or command
+// importPath as JSON, see PackageDoc, for tools that would rather not
+// parse the Markdown written by Convert. The filters apply to it.
+func (c *Converter) ConvertJSON(importPath string, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := c.pageInfo(ioutil.Discard, importPath)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.packageDoc(info))
+}
+
+// PackageDoc returns the documentation model of the package or command
+// importPath written by ConvertJSON, for publishers building other
+// documents from it.
+func (c *Converter) PackageDoc(importPath string) (*PackageDoc, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := c.pageInfo(ioutil.Discard, importPath)
+	if err != nil {
+		return nil, err
+	}
+	return c.packageDoc(info), nil
+}
+
+// packageDoc returns the documentation model of info.
+func (c *Converter) packageDoc(info *godoc.PageInfo) *PackageDoc {
+	pdoc := info.PDoc
+	if pdoc == nil {
+		return &PackageDoc{}
+	}
+	p := &PackageDoc{
+		Name:       pdoc.Name,
+		ImportPath: pdoc.ImportPath,
+		IsCommand:  info.IsMain,
+		Synopsis:   pdoc.Synopsis(pdoc.Doc),
+		Doc:        pdoc.Doc,
+		Consts:     c.valueDocs(info, pdoc.Consts),
+		Vars:       c.valueDocs(info, pdoc.Vars),
+		Funcs:      c.funcDocs(info, pdoc.Funcs),
+	}
+	for _, f := range pdoc.Filenames {
+		p.Files = append(p.Files, path.Base(f))
+	}
+	for _, t := range pdoc.Types {
+		p.Types = append(p.Types, TypeDoc{
+			Name:    t.Name,
+			Decl:    c.declText(info, t.Decl),
+			Doc:     t.Doc,
+			Pos:     c.position(info, t.Decl),
+			Consts:  c.valueDocs(info, t.Consts),
+			Vars:    c.valueDocs(info, t.Vars),
+			Funcs:   c.funcDocs(info, t.Funcs),
+			Methods: c.funcDocs(info, t.Methods),
+		})
+	}
+	for _, eg := range info.Examples {
+		code, output := c.exampleCode(info, eg)
+		p.Examples = append(p.Examples, ExampleDoc{Name: eg.Name, Doc: eg.Doc, Code: code, Output: output, Unordered: eg.Unordered})
+	}
+	for marker, notes := range info.Notes {
+		if p.Notes == nil {
+			p.Notes = make(map[string][]NoteDoc)
+		}
+		for _, n := range notes {
+			p.Notes[marker] = append(p.Notes[marker], NoteDoc{UID: n.UID, Body: n.Body, Pos: c.position(info, n)})
+		}
+	}
+	return p
+}
+
+// valueDocs returns the documentation model of values.
+func (c *Converter) valueDocs(info *godoc.PageInfo, values []*doc.Value) []ValueDoc {
+	var docs []ValueDoc
+	for _, v := range values {
+		docs = append(docs, ValueDoc{Names: v.Names, Decl: c.declText(info, v.Decl), Doc: v.Doc, Pos: c.position(info, v.Decl)})
+	}
+	return docs
+}
+
+// funcDocs returns the documentation model of funcs.
+func (c *Converter) funcDocs(info *godoc.PageInfo, funcs []*doc.Func) []FuncDoc {
+	var docs []FuncDoc
+	for _, f := range funcs {
+		docs = append(docs, FuncDoc{Name: f.Name, Recv: f.Recv, Decl: c.declText(info, f.Decl), Doc: f.Doc, Pos: c.position(info, f.Decl)})
+	}
+	return docs
+}
+
+// declText returns the source of the declaration decl, without function
+// bodies.
+func (c *Converter) declText(info *godoc.PageInfo, decl ast.Decl) string {
+	var buf bytes.Buffer
+	config := &printer.Config{Mode: printer.UseSpaces, Tabwidth: c.pres.TabWidth}
+	if err := config.Fprint(&buf, info.FSet, decl); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// position returns the position of n, see nodeRange, with its source link.
+func (c *Converter) position(info *godoc.PageInfo, n interface{}) Position {
+	pos, end := nodeRange(n)
+	return Position{
+		File:    fileOfFunc(info, n),
+		Line:    lineOfFunc(info, n),
+		EndLine: endLineOfFunc(info, n),
+		URL:     c.sourceLink(info, pos, end),
+	}
+}
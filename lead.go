@@ -0,0 +1,61 @@
+package godoc2md
+
+import (
+	"go/doc/comment"
+	"strings"
+	"unicode"
+)
+
+// leadMarkers are the Markdown markers wrapping the first sentence of
+// doc comments, by lead style.
+var leadMarkers = map[string]string{
+	"bold":   "**",
+	"italic": "*",
+}
+
+// sentenceEnd returns the offset of the end of the first sentence of s,
+// or -1 if s doesn't end a sentence. As in go/doc, a sentence ends with
+// a period followed by a space, unless preceded by a single uppercase
+// letter, as in initials.
+func sentenceEnd(s string) int {
+	var ppp, pp, p rune
+	for i, q := range s {
+		if q == '\n' || q == '\r' || q == '\t' {
+			q = ' '
+		}
+		if q == ' ' && p == '.' && (!unicode.IsUpper(pp) || unicode.IsUpper(ppp)) {
+			return i
+		}
+		if p == '。' || p == '．' {
+			return i
+		}
+		ppp, pp, p = pp, p, q
+	}
+	if p == '.' || p == '。' || p == '．' {
+		return len(s)
+	}
+	return -1
+}
+
+// leadMD renders the text of a paragraph like textMD, with its first
+// sentence, the conventional summary of a doc comment, wrapped in the
+// marker of the lead style.
+func (c *Converter) leadMD(text []comment.Text) string {
+	marker := leadMarkers[c.lead]
+	for i, t := range text {
+		p, ok := t.(comment.Plain)
+		if !ok {
+			continue
+		}
+		n := sentenceEnd(string(p))
+		if n < 0 {
+			continue
+		}
+		lead := append(append([]comment.Text{}, text[:i]...), p[:n])
+		rest := append([]comment.Text{p[n:]}, text[i+1:]...)
+		return marker + c.textMD(lead) + marker + c.textMD(rest)
+	}
+	md := c.textMD(text)
+	trimmed := strings.TrimRight(md, "\n")
+	return marker + trimmed + marker + md[len(trimmed):]
+}
@@ -0,0 +1,169 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"io/ioutil"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A Finding is an issue of the documentation or the API of a package,
+// reported by Lint.
+type Finding struct {
+	Pos     token.Position // position of the symbol, relative to the package directory
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Pos, f.Message)
+}
+
+// lintChecks are the checks run by Lint.
+var lintChecks = []func(c *Converter, info *godoc.PageInfo) []Finding{
+	(*Converter).lintConcurrency,
+	(*Converter).lintContext,
+	(*Converter).lintTextFilter,
+}
+
+// Lint checks the documentation of the package importPath, instead of
+// converting it, and returns its findings.
+func (c *Converter) Lint(importPath string) ([]Finding, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := c.pageInfo(ioutil.Discard, importPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.PDoc == nil {
+		return nil, nil
+	}
+	var findings []Finding
+	for _, check := range lintChecks {
+		findings = append(findings, check(c, info)...)
+	}
+	return findings, nil
+}
+
+// lintConcurrency reports the exported types with methods whose doc
+// comment doesn't state whether they are safe for concurrent use.
+func (c *Converter) lintConcurrency(info *godoc.PageInfo) []Finding {
+	var findings []Finding
+	for _, t := range info.PDoc.Types {
+		if !ast.IsExported(t.Name) || len(t.Methods) == 0 || concurrencyFunc(t.Doc) != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Pos:     positionFunc(info, t.Decl),
+			Message: fmt.Sprintf("type %s has methods but its doc comment doesn't state whether it is safe for concurrent use", t.Name),
+		})
+	}
+	return findings
+}
+
+// lintContext reports the exported functions and methods taking a
+// context.Context other than as their first parameter, and those that
+// may block but take no context.Context.
+func (c *Converter) lintContext(info *godoc.PageInfo) []Finding {
+	var funcs []*doc.Func
+	funcs = append(funcs, info.PDoc.Funcs...)
+	for _, t := range info.PDoc.Types {
+		if !ast.IsExported(t.Name) {
+			continue
+		}
+		funcs = append(funcs, t.Funcs...)
+		for _, m := range t.Methods {
+			if m.Level == 0 { // not promoted from an embedded type
+				funcs = append(funcs, m)
+			}
+		}
+	}
+
+	var blocking map[string]bool
+	var findings []Finding
+	for _, f := range funcs {
+		if !ast.IsExported(f.Name) {
+			continue
+		}
+		name, _ := typeParams(f.Decl)
+		i := contextParam(f.Decl.Type)
+		switch {
+		case i > 0:
+			findings = append(findings, Finding{
+				Pos:     positionFunc(info, f.Decl),
+				Message: fmt.Sprintf("func %s takes a context.Context as parameter %d, it should be the first one", name, i+1),
+			})
+		case i < 0:
+			if blocking == nil {
+				blocking = blockingFuncs(parseFiles(c.fs, info.Dirname))
+			}
+			if blocking[name] {
+				findings = append(findings, Finding{
+					Pos:     positionFunc(info, f.Decl),
+					Message: fmt.Sprintf("func %s may block but takes no context.Context", name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// contextParam returns the index of the first context.Context parameter
+// of a function type, or -1.
+func contextParam(ft *ast.FuncType) int {
+	i := 0
+	for _, field := range ft.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Context" {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == "context" {
+				return i
+			}
+		}
+		i += n
+	}
+	return -1
+}
+
+// blockingFuncs returns the functions and methods of files, keyed by
+// Name or Recv.Name, whose body may block: they send or receive on
+// channels, select, sleep or call Wait methods. The function literals
+// in their body are not looked into, as they often run in goroutines.
+func blockingFuncs(files []*ast.File) map[string]bool {
+	blocking := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			block := false
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.SelectStmt, *ast.SendStmt:
+					block = true
+				case *ast.UnaryExpr:
+					block = block || n.Op == token.ARROW
+				case *ast.CallExpr:
+					if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
+						id, _ := sel.X.(*ast.Ident)
+						block = block || sel.Sel.Name == "Wait" ||
+							(id != nil && id.Name == "time" && sel.Sel.Name == "Sleep")
+					}
+				}
+				return !block
+			})
+			if block {
+				name, _ := typeParams(fn)
+				blocking[name] = true
+			}
+		}
+	}
+	return blocking
+}
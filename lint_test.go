@@ -0,0 +1,87 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestLintContext(t *testing.T) {
+	const src = `package p
+
+func First(ctx context.Context, n int) {}
+func Second(n, m int, ctx context.Context) { <-ch }
+func Recv(ch chan int) { <-ch }
+func Send(ch chan int) { ch <- 1 }
+func Select() { select {} }
+func Sleep() { time.Sleep(1) }
+func (w *T) Wait() { w.wg.Wait() }
+func Spawn() { go func() { time.Sleep(1) }() }
+func Plain() {}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		name     string
+		context  int
+		blocking bool
+	}{
+		{"First", 0, false},
+		{"Second", 2, true},
+		{"Recv", -1, true},
+		{"Send", -1, true},
+		{"Select", -1, true},
+		{"Sleep", -1, true},
+		{"T.Wait", -1, true},
+		{"Spawn", -1, false},
+		{"Plain", -1, false},
+	}
+	blocking := blockingFuncs([]*ast.File{file})
+	for i, tt := range testData {
+		fn := file.Decls[i].(*ast.FuncDecl)
+		if got := contextParam(fn.Type); got != tt.context {
+			t.Errorf("contextParam(%s) = %d, want %d", tt.name, got, tt.context)
+		}
+		if got := blocking[tt.name]; got != tt.blocking {
+			t.Errorf("blocking[%s] = %v, want %v", tt.name, got, tt.blocking)
+		}
+	}
+}
+
+func TestCommentProse(t *testing.T) {
+	const src = `package p
+
+// F does
+// things.
+//
+//	code()
+func F() {}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		text      string
+		line, col int
+	}{
+		{"F does", 3, 4},
+		{"things.", 4, 4},
+		{"", 5, 3},
+		{"", 6, 3},
+	}
+	lines := commentProse(fset, file.Decls[0].(*ast.FuncDecl).Doc)
+	if len(lines) != len(testData) {
+		t.Fatalf("commentProse: expected %d lines, got %d", len(testData), len(lines))
+	}
+	for i, tt := range testData {
+		l := lines[i]
+		if l.text != tt.text || l.pos.Line != tt.line || l.pos.Column != tt.col {
+			t.Errorf("commentProse(%d): expected %q at %d:%d, got %q at %d:%d", i, tt.text, tt.line, tt.col, l.text, l.pos.Line, l.pos.Column)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A markup is an output format of ConvertMarkup: how it writes the
+// blocks and the inline elements of the documentation. The blocks end
+// with a blank line.
+type markup struct {
+	// section returns the heading of a section of level 0, the title of
+	// the document, to 2, with the anchor id if set.
+	section func(level int, id, title string) string
+	// rubric returns a heading outside of the sections, for the headings
+	// of doc comments and the examples.
+	rubric func(title string) string
+	// code returns a block of the source src in the language lang, or
+	// of text if empty.
+	code func(lang, src string) string
+	// escape escapes the text, and literal returns the code s inline.
+	escape, literal func(s string) string
+	// italic returns the text s in italics.
+	italic func(s string) string
+	// link returns the link to url of the text, and ref the one to the
+	// anchor id.
+	link, ref func(url, text string) string
+	// number is the bullet of the items of numbered lists.
+	number string
+}
+
+// markups are the output formats of ConvertMarkup, by name.
+var markups = map[string]markup{
+	"asciidoc": {
+		section: func(level int, id, title string) string {
+			if id != "" {
+				id = "[#" + id + "]\n"
+			}
+			return id + strings.Repeat("=", level+1) + " " + title + "\n\n"
+		},
+		rubric:  func(title string) string { return "[discrete]\n==== " + title + "\n\n" },
+		code:    asciidocCode,
+		escape:  asciidocEscape,
+		literal: func(s string) string { return "`+" + s + "+`" },
+		italic:  func(s string) string { return "__" + s + "__" },
+		link: func(url, text string) string {
+			return "link:" + url + "[" + strings.Replace(text, "]", "\\]", -1) + "]"
+		},
+		ref:    func(id, text string) string { return "<<" + id + "," + text + ">>" },
+		number: ". ",
+	},
+	"rst": {
+		section: rstSection,
+		rubric:  func(title string) string { return ".. rubric:: " + title + "\n\n" },
+		code:    rstCode,
+		escape:  rstEscape,
+		literal: func(s string) string { return "``" + s + "``" },
+		italic:  func(s string) string { return "*" + s + "*" },
+		link: func(url, text string) string {
+			return "`" + strings.Replace(text, "<", "\\<", -1) + " <" + url + ">`__"
+		},
+		ref:    func(id, text string) string { return "`" + strings.Replace(text, "<", "\\<", -1) + " <" + id + "_>`__" },
+		number: "#. ",
+	},
+}
+
+// asciidocEscaper replaces the characters of AsciiDoc markup by the
+// built-in attributes of Asciidoctor standing for them, which apply in
+// titles and link texts too.
+var asciidocEscaper = strings.NewReplacer(
+	"*", "{asterisk}", "`", "{backtick}", "+", "{plus}", "^", "{caret}", "~", "{tilde}",
+	"[", "{startsb}", "]", "{endsb}", "<", "{lt}", "\\", "{backslash}",
+)
+
+// asciidocEscape escapes the AsciiDoc markup of the text s.
+func asciidocEscape(s string) string {
+	return asciidocEscaper.Replace(s)
+}
+
+// asciidocCode returns a listing block of src, a source block if lang
+// is set.
+func asciidocCode(lang, src string) string {
+	attrs := ""
+	if lang != "" {
+		attrs = "[source," + lang + "]\n"
+	}
+	return attrs + "----\n" + strings.TrimRight(src, "\n") + "\n----\n\n"
+}
+
+// rstEscape escapes the characters of reStructuredText inline markup of
+// s with a backslash.
+func rstEscape(s string) string {
+	return escape(s, "\\*`_|")
+}
+
+// rstUnderlines are the characters underlining the section titles of
+// each level, the title of the document also overlined.
+const rstUnderlines = "==-"
+
+// rstSection returns the section title of reStructuredText, underlined
+// by as many characters of its level, preceded by the target of id.
+func rstSection(level int, id, title string) string {
+	line := strings.Repeat(rstUnderlines[level:level+1], utf8.RuneCountInString(title))
+	s := title + "\n" + line + "\n\n"
+	if level == 0 {
+		s = line + "\n" + s
+	}
+	if id != "" {
+		s = ".. _" + id + ":\n\n" + s
+	}
+	return s
+}
+
+// rstCode returns a code block of src, highlighted as lang if set.
+func rstCode(lang, src string) string {
+	if lang == "" {
+		lang = "text"
+	}
+	var buf strings.Builder
+	buf.WriteString(".. code-block:: " + lang + "\n\n")
+	for _, line := range strings.Split(strings.TrimRight(src, "\n"), "\n") {
+		buf.WriteString(strings.TrimRight("   "+line, " ") + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// markupDoc returns the doc comment text written in the format m, its
+// links to the symbols syms of the package to their anchors, and those
+// to other packages to pkgLinkBase.
+func markupDoc(m markup, text string, syms map[string]bool, pkgLinkBase string) string {
+	p := comment.Parser{LookupSym: func(recv, name string) bool {
+		if recv != "" {
+			name = recv + "." + name
+		}
+		return syms[name]
+	}}
+	var inline func(text []comment.Text) string
+	inline = func(text []comment.Text) string {
+		var buf strings.Builder
+		for _, t := range text {
+			switch t := t.(type) {
+			case comment.Plain:
+				buf.WriteString(m.escape(string(t)))
+			case comment.Italic:
+				buf.WriteString(m.italic(m.escape(string(t))))
+			case *comment.Link:
+				buf.WriteString(m.link(t.URL, inline(t.Text)))
+			case *comment.DocLink:
+				if t.ImportPath == "" {
+					id := t.Name
+					if t.Recv != "" {
+						id = t.Recv + "." + t.Name
+					}
+					buf.WriteString(m.ref(id, inline(t.Text)))
+				} else {
+					buf.WriteString(m.link(t.DefaultURL(pkgLinkBase), inline(t.Text)))
+				}
+			}
+		}
+		return buf.String()
+	}
+	var buf strings.Builder
+	for _, b := range p.Parse(text).Content {
+		switch b := b.(type) {
+		case *comment.Heading:
+			buf.WriteString(m.rubric(inline(b.Text)))
+		case *comment.Paragraph:
+			buf.WriteString(inline(b.Text) + "\n\n")
+		case *comment.Code:
+			buf.WriteString(m.code("", b.Text))
+		case *comment.List:
+			for _, item := range b.Items {
+				bullet := "* "
+				if item.Number != "" {
+					bullet = m.number
+				}
+				var texts []string
+				for _, c := range item.Content {
+					if p, ok := c.(*comment.Paragraph); ok {
+						texts = append(texts, inline(p.Text))
+					}
+				}
+				buf.WriteString(bullet + strings.Replace(strings.Join(texts, " "), "\n", " ", -1) + "\n")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
+
+// markupTemplate is the built-in template of the formats of
+// ConvertMarkup, executed with the PackageDoc of a package. Its
+// functions write the blocks and the inline elements of the format.
+var markupTemplate = `{{section 0 "" (text title)}}
+{{- if not .IsCommand}}{{para (literal (printf "import %q" .ImportPath))}}{{end}}
+{{- section 1 "pkg-overview" "Overview"}}{{doc .Doc}}{{examples ""}}
+{{- if not .IsCommand}}
+{{- section 1 "pkg-index" "Index"}}
+{{- if .Consts}}{{item (ref "pkg-constants" "Constants")}}{{end}}
+{{- if .Vars}}{{item (ref "pkg-variables" "Variables")}}{{end}}
+{{- range .Funcs}}{{item (ref .Name (printf "func %s" .Name))}}{{end}}
+{{- range $t := .Types}}{{item (ref .Name (printf "type %s" .Name))}}
+{{- range .Funcs}}{{item (ref .Name (printf "func %s" .Name))}}{{end}}
+{{- range .Methods}}{{item (ref (printf "%s.%s" $t.Name .Name) (printf "func (%s) %s" .Recv .Name))}}{{end}}
+{{- end}}{{br}}
+{{- if .Consts}}{{section 1 "pkg-constants" "Constants"}}{{range .Consts}}{{code "go" .Decl}}{{doc .Doc}}{{end}}{{end}}
+{{- if .Vars}}{{section 1 "pkg-variables" "Variables"}}{{range .Vars}}{{code "go" .Decl}}{{doc .Doc}}{{end}}{{end}}
+{{- range .Funcs}}{{section 1 .Name (source .Pos (printf "func %s" .Name))}}{{code "go" .Decl}}{{doc .Doc}}{{examples .Name}}{{end}}
+{{- range $t := .Types}}{{section 1 .Name (source .Pos (printf "type %s" .Name))}}{{code "go" .Decl}}{{doc .Doc}}{{examples .Name}}
+{{- range .Consts}}{{code "go" .Decl}}{{doc .Doc}}{{end}}
+{{- range .Vars}}{{code "go" .Decl}}{{doc .Doc}}{{end}}
+{{- range .Funcs}}{{section 2 .Name (source .Pos (printf "func %s" .Name))}}{{code "go" .Decl}}{{doc .Doc}}{{examples .Name}}{{end}}
+{{- range .Methods}}{{section 2 (printf "%s.%s" $t.Name .Name) (source .Pos (printf "func (%s) %s" .Recv .Name))}}{{code "go" .Decl}}{{doc .Doc}}{{examples (printf "%s_%s" $t.Name .Name)}}{{end}}
+{{- end}}
+{{- range $marker, $notes := .Notes}}{{section 1 (printf "pkg-note-%s" $marker) (printf "%ss" $marker)}}
+{{- range $notes}}{{item (source .Pos .Body)}}{{end}}{{br}}
+{{- end}}
+{{- end}}`
+
+// ConvertMarkup writes to w the documentation of the package or command
+// importPath in the format, "asciidoc" for AsciiDoc, such as for Antora,
+// or "rst" for reStructuredText, from the documentation model written by
+// ConvertJSON, with the anchors of the symbols and their source links.
+func (c *Converter) ConvertMarkup(importPath, format string, w io.Writer) error {
+	m, ok := markups[format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, want asciidoc or rst", format)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := c.pageInfo(ioutil.Discard, importPath)
+	if err != nil {
+		return err
+	}
+	p := c.packageDoc(info)
+
+	syms := make(map[string]bool)
+	for _, vs := range [][]ValueDoc{p.Consts, p.Vars} {
+		for _, v := range vs {
+			for _, name := range v.Names {
+				syms[name] = true
+			}
+		}
+	}
+	for _, f := range p.Funcs {
+		syms[f.Name] = true
+	}
+	for _, t := range p.Types {
+		syms[t.Name] = true
+		for _, f := range t.Funcs {
+			syms[f.Name] = true
+		}
+		for _, f := range t.Methods {
+			syms[t.Name+"."+f.Name] = true
+		}
+	}
+	text := func(s string) string { return m.escape(s) }
+	funcs := template.FuncMap{
+		"section": m.section,
+		"text":    text,
+		"literal": m.literal,
+		"code":    m.code,
+		"para":    func(s string) string { return s + "\n\n" },
+		"br":      func() string { return "\n" },
+		"item":    func(s string) string { return "* " + strings.Replace(s, "\n", " ", -1) + "\n" },
+		"ref":     func(id, s string) string { return m.ref(id, text(s)) },
+		"title":   func() string { return c.packageMeta(info).Title },
+		"doc":     func(s string) string { return markupDoc(m, s, syms, c.pkgLinkBase) },
+		"source": func(pos Position, s string) string {
+			if pos.URL == "" {
+				return text(s)
+			}
+			return m.link(pos.URL, text(s))
+		},
+		"examples": func(name string) string {
+			var buf strings.Builder
+			for _, eg := range p.Examples {
+				suffix := strings.TrimPrefix(eg.Name, name)
+				if name != "" && suffix == eg.Name || suffix != "" && !strings.HasPrefix(suffix, "_") {
+					continue
+				}
+				suffix = strings.TrimPrefix(suffix, "_")
+				if suffix != "" && !unicode.IsLower([]rune(suffix)[0]) {
+					continue // the example of another symbol
+				}
+				title := "Example"
+				if suffix != "" {
+					title += " (" + suffix + ")"
+				}
+				buf.WriteString(m.rubric(text(title)) + markupDoc(m, eg.Doc, syms, c.pkgLinkBase) + m.code("go", eg.Code))
+				if eg.Output != "" {
+					buf.WriteString("Output:\n\n" + m.code("", eg.Output))
+				}
+			}
+			return buf.String()
+		},
+	}
+	tmpl, err := template.New(format).Funcs(funcs).Parse(markupTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, p)
+}
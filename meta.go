@@ -0,0 +1,129 @@
+package godoc2md
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// A PackageMeta overrides the title, the description and the tags of the
+// documentation of a package, those of its front matter and given to the
+// templates. The empty fields are derived from the package.
+type PackageMeta struct {
+	Title       string
+	Description string
+	Tags        []string
+}
+
+// directivePrefix is the prefix of the directives setting the fields of
+// the PackageMeta of a package in the comments before its package clause,
+// such as
+//
+//	//godoc2md:title HTTP client
+//	//godoc2md:description A client of the API, with retries.
+//	//godoc2md:tags http, client
+//
+// As Go directives, they are left out of the doc comments.
+const directivePrefix = "//godoc2md:"
+
+// fileMeta returns the PackageMeta set by the godoc2md directives of the
+// comments of src before its package clause. The descriptions of several
+// directives are joined with spaces, and the tags are comma-separated.
+func fileMeta(name string, src []byte) PackageMeta {
+	var meta PackageMeta
+	file, err := parser.ParseFile(token.NewFileSet(), name, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return meta
+	}
+	for _, cg := range file.Comments {
+		if cg.Pos() > file.Package {
+			break
+		}
+		for _, cm := range cg.List {
+			if !strings.HasPrefix(cm.Text, directivePrefix) {
+				continue
+			}
+			key, value := strings.TrimPrefix(cm.Text, directivePrefix), ""
+			if i := strings.IndexAny(key, " \t"); i >= 0 {
+				key, value = key[:i], strings.TrimSpace(key[i:])
+			}
+			switch key {
+			case "title":
+				meta.Title = value
+			case "description":
+				meta.Description = strings.TrimSpace(meta.Description + " " + value)
+			case "tags":
+				for _, tag := range strings.Split(value, ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						meta.Tags = append(meta.Tags, tag)
+					}
+				}
+			}
+		}
+	}
+	return meta
+}
+
+// packageMeta returns the PackageMeta of the package of info: the one set
+// with WithPackageMeta for its import path, and the directives of its
+// files for the fields it leaves empty, then the package name, or the
+// base of the import path of a command, as title, and the synopsis as
+// description. It's computed on first use.
+func (c *Converter) packageMeta(info *godoc.PageInfo) PackageMeta {
+	if c.meta != nil {
+		return *c.meta
+	}
+	if info.PDoc == nil {
+		return PackageMeta{}
+	}
+	meta := c.packageMetas[info.PDoc.ImportPath]
+	for _, name := range info.PDoc.Filenames {
+		src, err := vfs.ReadFile(c.fs, path.Join(info.Dirname, path.Base(name)))
+		if err != nil {
+			continue
+		}
+		m := fileMeta(name, src)
+		if meta.Title == "" {
+			meta.Title = m.Title
+		}
+		if meta.Description == "" {
+			meta.Description = m.Description
+		}
+		if meta.Tags == nil {
+			meta.Tags = m.Tags
+		}
+	}
+	if meta.Title == "" {
+		meta.Title = info.PDoc.Name
+		if info.IsMain {
+			meta.Title = path.Base(info.PDoc.ImportPath)
+		}
+	}
+	if meta.Description == "" {
+		meta.Description = info.PDoc.Synopsis(info.PDoc.Doc)
+	}
+	c.meta = &meta
+	return meta
+}
+
+// packageTitleFunc returns the title of the package of info, see
+// packageMeta.
+func (c *Converter) packageTitleFunc(info *godoc.PageInfo) string {
+	return c.packageMeta(info).Title
+}
+
+// packageDescriptionFunc returns the description of the package of info,
+// see packageMeta.
+func (c *Converter) packageDescriptionFunc(info *godoc.PageInfo) string {
+	return c.packageMeta(info).Description
+}
+
+// packageTagsFunc returns the tags of the package of info, see
+// packageMeta.
+func (c *Converter) packageTagsFunc(info *godoc.PageInfo) []string {
+	return c.packageMeta(info).Tags
+}
@@ -0,0 +1,167 @@
+package godoc2md
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// readmeSection is a section of a Markdown document: a heading with the
+// content following it, until the next heading of the same or a higher
+// level. Text before the first heading is a section with level 0.
+type readmeSection struct {
+	level   int
+	heading string
+	text    string
+}
+
+var (
+	atxHeadingRx = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+	htmlTagRx    = regexp.MustCompile(`<[^>]*>`)
+	mdLinkRx     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	importLineRx = regexp.MustCompile("^`import ([\\w]+ )?\"[^\"]+\"`\\s*$")
+)
+
+// generatedHeadings are the headings godoc2md generates, lower-cased.
+var generatedHeadings = map[string]bool{
+	"overview":                true,
+	"index":                   true,
+	"examples":                true,
+	"package files":           true,
+	"constants":               true,
+	"variables":               true,
+	"subdirectories":          true,
+	"security considerations": true,
+	"bugs":                    true,
+	"notes":                   true,
+	"options":                 true,
+	"accessors":               true,
+	"functions":               true,
+	"types":                   true,
+	"api summary":             true,
+}
+
+// headingText returns the plain text of a Markdown heading, without
+// HTML tags, link targets, emphasis or escapes.
+func headingText(heading string) string {
+	s := htmlTagRx.ReplaceAllString(heading, "")
+	s = mdLinkRx.ReplaceAllString(s, "$1")
+	s = strings.NewReplacer("`", "", "\\", "", "*", "").Replace(s)
+	return strings.TrimSpace(s)
+}
+
+// splitSections splits a Markdown document into sections. Level 1
+// headings, used by godoc2md for the package title only, end at the
+// next heading of any level.
+func splitSections(text string) []readmeSection {
+	var sections []readmeSection
+	cur := readmeSection{}
+	inFence := false
+	flush := func() {
+		if cur.heading != "" || cur.text != "" {
+			sections = append(sections, cur)
+		}
+	}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		if strings.HasPrefix(strings.TrimSpace(trimmed), "```") {
+			inFence = !inFence
+		}
+		m := atxHeadingRx.FindStringSubmatch(trimmed)
+		if inFence || m == nil {
+			cur.text += line
+			continue
+		}
+		level := len(m[1])
+		flush()
+		cur = readmeSection{level: level, heading: m[2], text: line}
+	}
+	flush()
+	return nestSections(sections)
+}
+
+// nestSections merges subsections into their enclosing section.
+func nestSections(flat []readmeSection) []readmeSection {
+	var out []readmeSection
+	for _, s := range flat {
+		if n := len(out); n > 0 {
+			parent := &out[n-1]
+			if parent.level > 1 && s.level > parent.level {
+				parent.text += s.text
+				continue
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// isGeneratedSection reports whether a README section looks like
+// content generated by godoc2md.
+func isGeneratedSection(s readmeSection) bool {
+	if s.heading == "" {
+		return false
+	}
+	text := headingText(s.heading)
+	lower := strings.ToLower(text)
+	if s.level == 1 {
+		// package title, followed by its import line
+		for _, line := range strings.Split(s.text, "\n")[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return importLineRx.MatchString(line)
+		}
+		return false
+	}
+	if generatedHeadings[lower] {
+		return true
+	}
+	for _, prefix := range []string{"func ", "type ", "example ", "examples "} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripFooter removes the trailing "Generated by godoc2md" footer of a
+// custom section.
+func stripFooter(text string) string {
+	if i := strings.Index(text, "- - -\nGenerated by [godoc2md]"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// MigrateReadme reads a hand-written README and saves the sections that
+// don't correspond to generated content into the header file, to be
+// prepended to the generated documentation with WithHeaderFile.
+func MigrateReadme(readme, header string) error {
+	data, err := ioutil.ReadFile(readme)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, s := range splitSections(string(data)) {
+		if isGeneratedSection(s) {
+			continue
+		}
+		if text := strings.TrimSpace(stripFooter(s.text)); text != "" {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(text)
+			buf.WriteString("\n")
+		}
+	}
+	return ioutil.WriteFile(header, buf.Bytes(), 0644)
+}
+
+// DefaultHeaderFile returns the header file conventionally associated
+// with a README: README.header.md in the same directory.
+func DefaultHeaderFile(readme string) string {
+	return filepath.Join(filepath.Dir(readme), "README.header.md")
+}
@@ -0,0 +1,36 @@
+package godoc2md
+
+import (
+	"testing"
+)
+
+func TestSplitSections(t *testing.T) {
+	readme := "[![badge](x)](y)\n\n# foo\n`import \"example.com/foo\"`\n\n* [Overview](#pkg-overview)\n\n" +
+		"## <a name=\"pkg-overview\">Overview</a>\nPackage foo.\n\n### Details\nMore.\n\n" +
+		"## Install\n\n```sh\n# not a heading\ngo get example.com/foo\n```\n\n" +
+		"## <a name=\"New\">func</a> [New](x)\n``` go\nfunc New()\n```\n\n" +
+		"- - -\nGenerated by [godoc2md](http://godoc.org/github.com/davecheney/godoc2md)\n"
+	testData := []struct {
+		heading   string
+		generated bool
+	}{
+		{"", false},
+		{"foo", true},
+		{`<a name="pkg-overview">Overview</a>`, true},
+		{"Install", false},
+		{`<a name="New">func</a> [New](x)`, true},
+	}
+	sections := splitSections(readme)
+	if len(sections) != len(testData) {
+		t.Fatalf("splitSections: expected %d sections, got %d: %q", len(testData), len(sections), sections)
+	}
+	for n, tt := range testData {
+		s := sections[n]
+		if s.heading != tt.heading {
+			t.Errorf("splitSections(%d): expected heading %q, got %q", n, tt.heading, s.heading)
+		}
+		if got := isGeneratedSection(s); got != tt.generated {
+			t.Errorf("isGeneratedSection(%d): expected %v, got %v", n, tt.generated, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package godoc2md
+
+import (
+	"bufio"
+	"fmt"
+	"go/doc"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// moduleInfo holds the fields available to the module template.
+type moduleInfo struct {
+	Path        string // module path
+	GoVersion   string // go directive of go.mod
+	License     string // name of the license, "" if none was found
+	LicenseFile string // name of the license file, relative to the module root
+	DocURL      string // URL of the documentation of the module, "" if not on pkg.go.dev
+	Doc         string // doc comment of the root package
+	Library     bool   // whether the module has non-main packages
+	Packages    []modulePackage
+}
+
+// modulePackage is a package of a module, in the module template.
+type modulePackage struct {
+	ImportPath string
+	Dir        string // directory of the package, relative to the module root
+	Doc        string
+	IsCommand  bool
+}
+
+// licenses maps the first words of well-known license texts to their
+// SPDX identifier.
+var licenses = []struct{ text, id string }{
+	{"MIT License", "MIT"},
+	{"Apache License", "Apache-2.0"},
+	{"Mozilla Public License", "MPL-2.0"},
+	{"GNU LESSER GENERAL PUBLIC LICENSE", "LGPL"},
+	{"GNU AFFERO GENERAL PUBLIC LICENSE", "AGPL"},
+	{"GNU GENERAL PUBLIC LICENSE", "GPL"},
+	{"BSD", "BSD"},
+	{"ISC License", "ISC"},
+	{"The Unlicense", "Unlicense"},
+}
+
+// ConvertModule writes to w the landing page of the module rooted at the
+// directory root: the overview of its root package, install
+// instructions, the index of its packages, and the metadata of the
+// module from its go.mod and LICENSE files.
+func (c *Converter) ConvertModule(root string, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedModule, Dir: root}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return err
+	}
+	if len(pkgs) == 0 || pkgs[0].Module == nil {
+		return fmt.Errorf("%s: not in a module", root)
+	}
+	mod := pkgs[0].Module
+	m := moduleInfo{Path: mod.Path, GoVersion: mod.GoVersion}
+	if c.pkgLinkBase == defaultPkgLinkBase {
+		m.DocURL = defaultPkgLinkBase + "/" + mod.Path
+	}
+	m.LicenseFile, m.License = findLicense(mod.Dir)
+
+	var rootDoc *doc.Package
+	for _, path := range c.expandPackages(filepath.Join(mod.Dir, "...")) {
+		if path != mod.Path && !strings.HasPrefix(path, mod.Path+"/") {
+			continue // nested module
+		}
+		info, err := c.pageInfo(ioutil.Discard, path)
+		if err != nil || info.PDoc == nil {
+			continue
+		}
+		p := modulePackage{ImportPath: path, Dir: ".", Doc: info.PDoc.Doc, IsCommand: info.IsMain}
+		if rel := strings.TrimPrefix(path, mod.Path+"/"); rel != path {
+			p.Dir = rel
+		}
+		m.Library = m.Library || !p.IsCommand
+		if path == mod.Path {
+			m.Doc, rootDoc = p.Doc, info.PDoc
+		}
+		m.Packages = append(m.Packages, p)
+	}
+	// resolves the doc links of the overview, its asset references left
+	// alone
+	c.pdoc, c.pdocDir = rootDoc, ""
+
+	tmpl, err := c.readTemplate("module.txt", moduleTemplate)
+	if err != nil {
+		return err
+	}
+	return c.execute(w, tmpl, m)
+}
+
+// findLicense returns the name of the license file of the directory dir
+// and the identifier of its license, "see <file>" if unknown.
+func findLicense(dir string) (file, license string) {
+	for _, name := range []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		for i := 0; i < 5 && s.Scan(); i++ {
+			line := strings.TrimSpace(s.Text())
+			for _, l := range licenses {
+				if strings.Contains(line, l.text) {
+					return name, l.id
+				}
+			}
+		}
+		return name, "see " + name
+	}
+	return "", ""
+}
@@ -0,0 +1,77 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/godoc"
+)
+
+// noteStyles lists the supported rendering styles for notes.
+// "list" is the historical rendering as an HTML list; the others
+// map onto GitHub admonitions (> [!NOTE], > [!WARNING], ...).
+var noteStyles = map[string]bool{
+	"list":      true,
+	"note":      true,
+	"tip":       true,
+	"important": true,
+	"warning":   true,
+	"caution":   true,
+}
+
+// noteTitleFunc returns the section title for the notes of the given marker,
+// e.g. "Bugs" for BUG, unless overridden with WithNoteTitles.
+func (c *Converter) noteTitleFunc(marker string) string {
+	if title, ok := c.noteTitles[marker]; ok {
+		return title
+	}
+	return strings.Title(strings.ToLower(marker)) + "s"
+}
+
+// noteStyle returns the rendering style for the notes of the given marker.
+func (c *Converter) noteStyle(marker string) string {
+	if style, ok := c.noteStyleByMarker[marker]; ok {
+		return style
+	}
+	return "list"
+}
+
+// notesMdFunc renders the notes collected for marker.
+func (c *Converter) notesMdFunc(info *godoc.PageInfo, marker string, notes []*doc.Note) string {
+	var buf bytes.Buffer
+	style := c.noteStyle(marker)
+	if style == "list" {
+		if c.dialect.jsx {
+			buf.WriteString("<ul>\n")
+		} else {
+			buf.WriteString(`<ul style="list-style: none; padding: 0;">` + "\n")
+		}
+		for _, note := range notes {
+			fmt.Fprintf(&buf, "<li><a href=\"%s\">&#x261e;</a> %s</li>\n",
+				c.noteLink(info, note), template.HTMLEscapeString(note.Body))
+		}
+		buf.WriteString("</ul>\n")
+		return buf.String()
+	}
+
+	for i, note := range notes {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "> [!%s]\n", strings.ToUpper(style))
+		body := strings.TrimRight(note.Body, "\n")
+		for _, line := range strings.Split(body, "\n") {
+			buf.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		fmt.Fprintf(&buf, "> ([source](%s))\n", c.noteLink(info, note))
+	}
+	return buf.String()
+}
+
+// noteLink returns the source link to a note.
+func (c *Converter) noteLink(info *godoc.PageInfo, note *doc.Note) string {
+	return c.sourceLink(info, note.Pos, note.End)
+}
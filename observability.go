@@ -0,0 +1,255 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// promKinds are the types of the Prometheus metrics, by the name of the
+// function of client_golang or promauto registering them.
+var promKinds = map[string]string{
+	"NewCounter": "counter", "NewCounterVec": "counter", "NewCounterFunc": "counter",
+	"NewGauge": "gauge", "NewGaugeVec": "gauge", "NewGaugeFunc": "gauge",
+	"NewHistogram": "histogram", "NewHistogramVec": "histogram",
+	"NewSummary": "summary", "NewSummaryVec": "summary",
+	"NewUntypedFunc": "untyped",
+}
+
+// otelKinds are the types of the OpenTelemetry instruments, by the name
+// of the method of the meter creating them, without its Int64 or Float64
+// prefix.
+var otelKinds = map[string]string{
+	"Counter": "counter", "UpDownCounter": "up-down counter", "Histogram": "histogram", "Gauge": "gauge",
+	"ObservableCounter": "observable counter", "ObservableUpDownCounter": "observable up-down counter",
+	"ObservableGauge": "observable gauge",
+}
+
+// An instrument is a metric or a tracer registered by a package: its
+// name, its type, its labels, its description, and the symbol declaring
+// it, the variable it's assigned to or the function registering it.
+type instrument struct {
+	name, kind  string
+	labels      []string
+	description string
+	symbol      string
+	recv        string // type of the method symbol
+}
+
+// stringConsts returns the values of the string constants declared by
+// files, by name.
+func stringConsts(files []*ast.File) map[string]string {
+	consts := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range d.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						if s, ok := stringLit(vs.Values[i]); ok {
+							consts[name.Name] = s
+						}
+					}
+				}
+			}
+		}
+	}
+	return consts
+}
+
+// instrumentsOf returns the instruments registered by the calls below n:
+// the Prometheus metrics of client_golang and promauto, with the name of
+// their options, prefixed with their namespace and subsystem, and the
+// labels of their vectors, and the OpenTelemetry instruments and
+// tracers, with their description. Their names are string literals or
+// the constants consts.
+func instrumentsOf(n ast.Node, consts map[string]string) []instrument {
+	str := func(x ast.Expr) (string, bool) {
+		if id, ok := x.(*ast.Ident); ok {
+			s, ok := consts[id.Name]
+			return s, ok
+		}
+		return stringLit(x)
+	}
+	var instruments []instrument
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		name := sel.Sel.Name
+		if kind := promKinds[name]; kind != "" {
+			opts, ok := call.Args[0].(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			var parts []string
+			fields := make(map[string]string)
+			for _, elt := range opts.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					if s, ok := str(kv.Value); ok {
+						fields[key.Name] = s
+					}
+				}
+			}
+			for _, key := range []string{"Namespace", "Subsystem", "Name"} {
+				if fields[key] != "" {
+					parts = append(parts, fields[key])
+				}
+			}
+			if fields["Name"] == "" {
+				return true
+			}
+			in := instrument{name: strings.Join(parts, "_"), kind: kind, description: fields["Help"]}
+			if strings.HasSuffix(name, "Vec") && len(call.Args) > 1 {
+				if labels, ok := call.Args[1].(*ast.CompositeLit); ok {
+					for _, elt := range labels.Elts {
+						if s, ok := str(elt); ok {
+							in.labels = append(in.labels, s)
+						}
+					}
+				}
+			}
+			instruments = append(instruments, in)
+			return true
+		}
+		s, ok := str(call.Args[0])
+		if !ok || s == "" {
+			return true
+		}
+		if name == "Tracer" {
+			instruments = append(instruments, instrument{name: s, kind: "tracer"})
+			return true
+		}
+		base := strings.TrimPrefix(name, "Int64")
+		if base == name {
+			base = strings.TrimPrefix(name, "Float64")
+		}
+		if base == name || otelKinds[base] == "" {
+			return true
+		}
+		in := instrument{name: s, kind: otelKinds[base]}
+		for _, arg := range call.Args[1:] {
+			opt, ok := arg.(*ast.CallExpr)
+			if !ok || len(opt.Args) != 1 {
+				continue
+			}
+			if sel, ok := opt.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "WithDescription" {
+				in.description, _ = str(opt.Args[0])
+			}
+		}
+		instruments = append(instruments, in)
+		return true
+	})
+	return instruments
+}
+
+// observabilityMdFunc returns the Observability section of the package
+// of info, set with WithObservability: the Prometheus metrics and the
+// OpenTelemetry instruments and tracers it registers, found in its
+// sources, sorted by name, with the symbols declaring them, or the empty
+// string if it registers none.
+func (c *Converter) observabilityMdFunc(info *godoc.PageInfo) string {
+	if !c.observability || info.PDoc == nil {
+		return ""
+	}
+	files := parseFiles(c.fs, info.Dirname)
+	consts := stringConsts(files)
+	var instruments []instrument
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, value := range vs.Values {
+						for _, in := range instrumentsOf(value, consts) {
+							if i < len(vs.Names) {
+								in.symbol = vs.Names[i].Name
+							}
+							instruments = append(instruments, in)
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Body == nil {
+					continue
+				}
+				for _, in := range instrumentsOf(d.Body, consts) {
+					in.symbol = d.Name.Name
+					if d.Recv != nil && len(d.Recv.List) == 1 {
+						in.recv = embeddedName(d.Recv.List[0].Type)
+					}
+					instruments = append(instruments, in)
+				}
+			}
+		}
+	}
+	if len(instruments) == 0 {
+		return ""
+	}
+	sort.SliceStable(instruments, func(i, j int) bool { return instruments[i].name < instruments[j].name })
+
+	anchors := make(map[string]string) // of the documented symbols
+	for _, v := range info.PDoc.Vars {
+		for _, name := range v.Names {
+			anchors[name] = "pkg-variables"
+		}
+	}
+	for _, f := range info.PDoc.Funcs {
+		anchors[f.Name] = f.Name
+	}
+	for _, t := range info.PDoc.Types {
+		for _, v := range t.Vars {
+			for _, name := range v.Names {
+				anchors[name] = t.Name
+			}
+		}
+		for _, f := range t.Funcs {
+			anchors[f.Name] = f.Name
+		}
+		for _, m := range t.Methods {
+			anchors[t.Name+"."+m.Name] = t.Name + "." + m.Name
+		}
+	}
+	escapeCell := func(s string) string { return strings.Replace(c.mdFunc(s), "|", "\\|", -1) }
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-observability") + c.headingFunc("section", 2) + "<a name=\"pkg-observability\">Observability</a>\n\n")
+	buf.WriteString("| Name | Type | Labels | Description | Declared by |\n| --- | --- | --- | --- | --- |\n")
+	for _, in := range instruments {
+		labels := make([]string, len(in.labels))
+		for i, l := range in.labels {
+			labels[i] = "`" + l + "`"
+		}
+		symbol := in.symbol
+		if in.recv != "" {
+			symbol = in.recv + "." + symbol
+		}
+		if anchor := anchors[symbol]; anchor != "" {
+			symbol = "[" + c.mdFunc(symbol) + "](#" + anchor + ")"
+		} else if symbol != "" {
+			symbol = "`" + symbol + "`"
+		}
+		buf.WriteString("| `" + in.name + "` | " + in.kind + " | " + strings.Join(labels, ", ") + " | " + escapeCell(in.description) + " | " + symbol + " |\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
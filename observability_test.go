@@ -0,0 +1,59 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentsOf(t *testing.T) {
+	src := `package p
+
+const (
+	namespace   = "shop"
+	ordersTotal = "orders_total"
+)
+
+var orders = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: "api",
+	Name:      ordersTotal,
+	Help:      "Orders placed.",
+}, []string{"method", "code"})
+
+var inflight = prometheus.NewGauge(prometheus.GaugeOpts{Name: "inflight"})
+
+var unnamed = prometheus.NewGauge(prometheus.GaugeOpts{Help: "No name."})
+
+var opts = prometheus.NewHistogram(histogramOpts)
+
+func init() {
+	latency, _ := meter.Float64Histogram("http.latency", metric.WithDescription("Latency of the requests."), metric.WithUnit("s"))
+	meter.Int64ObservableGauge("queue.size")
+	meter.Float64Sum("not.an.instrument")
+	tracer := otel.Tracer("example.com/p")
+	_, _ = latency, tracer
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, in := range instrumentsOf(file, stringConsts([]*ast.File{file})) {
+		got = append(got, in.name+" "+in.kind+" ["+strings.Join(in.labels, ",")+"] "+in.description)
+	}
+	expected := []string{
+		"shop_api_orders_total counter [method,code] Orders placed.",
+		"inflight gauge [] ",
+		"http.latency histogram [] Latency of the requests.",
+		"queue.size observable gauge [] ",
+		"example.com/p tracer [] ",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("instrumentsOf: expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,78 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/doc"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// optionsForFunc returns the functional options grouped under the type tname.
+func (c *Converter) optionsForFunc(tname string) []*doc.Func {
+	return c.optionFuncs[tname]
+}
+
+// isOptionType reports whether t looks like the option type of
+// the functional options pattern.
+func isOptionType(t *doc.Type) bool {
+	return strings.HasSuffix(t.Name, "Option")
+}
+
+// variadicType returns the name of the element type of the trailing
+// variadic parameter of fn, if any.
+func variadicType(fn *ast.FuncType) string {
+	if fn.Params == nil || len(fn.Params.List) == 0 {
+		return ""
+	}
+	last := fn.Params.List[len(fn.Params.List)-1]
+	ellipsis, ok := last.Type.(*ast.Ellipsis)
+	if !ok {
+		return ""
+	}
+	if id, ok := ellipsis.Elt.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// groupOptions detects the functional options pattern: a type named
+// "Option" (or "...Option") with "With..." constructors, consumed as
+// the variadic parameter of another type's constructor or methods.
+// The With functions are moved under the consuming type.
+func (c *Converter) groupOptions(info *godoc.PageInfo) {
+	c.optionFuncs = make(map[string][]*doc.Func)
+	if info.PDoc == nil {
+		return
+	}
+
+	// find which type consumes each option type
+	consumer := make(map[string]string)
+	for _, t := range info.PDoc.Types {
+		for _, fns := range [][]*doc.Func{t.Funcs, t.Methods} {
+			for _, f := range fns {
+				if opt := variadicType(f.Decl.Type); opt != "" && opt != t.Name {
+					if _, ok := consumer[opt]; !ok {
+						consumer[opt] = t.Name
+					}
+				}
+			}
+		}
+	}
+
+	for _, t := range info.PDoc.Types {
+		owner, ok := consumer[t.Name]
+		if !ok || !isOptionType(t) {
+			continue
+		}
+		var rest []*doc.Func
+		for _, f := range t.Funcs {
+			if strings.HasPrefix(f.Name, "With") {
+				c.optionFuncs[owner] = append(c.optionFuncs[owner], f)
+			} else {
+				rest = append(rest, f)
+			}
+		}
+		t.Funcs = rest
+	}
+}
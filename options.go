@@ -0,0 +1,627 @@
+package godoc2md
+
+import (
+	"io"
+	"strings"
+)
+
+// An Option configures a Converter.
+type Option func(*Converter)
+
+// WithVerbose enables verbose logging.
+func WithVerbose(verbose bool) Option {
+	return func(c *Converter) { c.verbose = verbose }
+}
+
+// WithGoroot sets the Go root directory, runtime.GOROOT() by default.
+func WithGoroot(dir string) Option {
+	return func(c *Converter) { c.goroot = dir }
+}
+
+// WithTabWidth sets the tab width of code blocks, 4 by default.
+func WithTabWidth(width int) Option {
+	return func(c *Converter) { c.tabWidth = width }
+}
+
+// WithCodeLanguage sets the language of the code blocks of doc comments,
+// such as go, for their syntax highlighting. They have none by default.
+func WithCodeLanguage(lang string) Option {
+	return func(c *Converter) { c.codeLang = lang }
+}
+
+// WithTimestamps shows timestamps with directory listings.
+func WithTimestamps(show bool) Option {
+	return func(c *Converter) { c.showTimestamps = show }
+}
+
+// WithPlayground enables the playground in the web interface.
+func WithPlayground(show bool) Option {
+	return func(c *Converter) { c.showPlayground = show }
+}
+
+// WithUnexported includes the unexported constants, variables, types,
+// functions and methods in the documentation, like go doc -u.
+func WithUnexported(all bool) Option {
+	return func(c *Converter) { c.unexported = all }
+}
+
+// WithDialect selects the flavor of Markdown of the output, which
+// drives the escaping of text, the emission of heading anchors and the
+// IDs of headings, see the kebab template function: "gfm", the default,
+// "commonmark", "mdx", "bitbucket", "gitlab", "azure", "azure-wiki",
+// for the wikis of Azure DevOps projects, or "plain", for the renderers
+// without tables, HTML and fenced code blocks, such as review comments
+// and plain-text mail.
+func WithDialect(name string) Option {
+	return func(c *Converter) { c.dialectName = name }
+}
+
+// WithTemplate replaces the package template with text.
+func WithTemplate(text string) Option {
+	return func(c *Converter) { c.templateText = text }
+}
+
+// WithTemplatePartials adds the named templates partials, by name, to the
+// templates, to be included with {{template "name" .}}, such as the
+// sections of a template split in several files.
+func WithTemplatePartials(partials map[string]string) Option {
+	return func(c *Converter) { c.templatePartials = partials }
+}
+
+// WithStrictTemplates makes the conversion fail when the templates use
+// missing map keys or print "<no value>", such as after a change of the
+// data model of a custom template, instead of writing the output.
+func WithStrictTemplates(strict bool) Option {
+	return func(c *Converter) { c.strictTemplates = strict }
+}
+
+// WithVerifyOutput makes the conversion fail when the Markdown output,
+// parsed as GFM, links to anchors or headings it lacks, declares an
+// anchor twice, has links without destination or leaves code blocks
+// open, instead of writing it.
+func WithVerifyOutput(verify bool) Option {
+	return func(c *Converter) { c.verifyOutput = verify }
+}
+
+// WithLayout selects the built-in package template: "flat", the default,
+// "pkgsite", which groups the functions and the types in sections like
+// pkg.go.dev, or "summary", a table of the exported symbols. A template
+// set with WithTemplate takes precedence.
+func WithLayout(name string) Option {
+	return func(c *Converter) { c.layout = name }
+}
+
+// WithExamples renders the examples of the package.
+func WithExamples(show bool) Option {
+	return func(c *Converter) { c.showExamples = show }
+}
+
+// WithExampleLevel sets the heading level of examples, 5 by default.
+func WithExampleLevel(level int) Option {
+	return func(c *Converter) { c.exampleLevel = level }
+}
+
+// WithExampleTitle sets the template of example titles, with fields
+// Name, Suffix, RawSuffix and Link.
+func WithExampleTitle(tmpl string) Option {
+	return func(c *Converter) { c.exampleTitle = tmpl }
+}
+
+// WithTypeDiagram adds to the index of a package a Mermaid diagram of
+// its types, the interfaces of the package they implement and the types
+// they embed, of the given kind: "class" for a classDiagram or "graph".
+func WithTypeDiagram(kind string) Option {
+	return func(c *Converter) { c.typeDiagram = kind }
+}
+
+// WithImplementations lists under each interface the types of the package
+// implementing it, and under each type the interfaces of the package it
+// implements, by type-checking the package and the packages it imports
+// from their sources.
+func WithImplementations(refs bool) Option {
+	return func(c *Converter) { c.implementsRefs = refs }
+}
+
+// WithSchemas adds to each struct type a Schema table of its fields, for
+// packages of DTOs: their JSON name and options, type, whether they're
+// required by their validate or binding tag, and description.
+func WithSchemas(schemas bool) Option {
+	return func(c *Converter) { c.schemas = schemas }
+}
+
+// WithDataModel adds a Data model section mapping the persistence models
+// of the package, the structs with gorm, sqlx or bun tags, to their tables
+// and columns, with their constraints.
+func WithDataModel(dataModel bool) Option {
+	return func(c *Converter) { c.dataModel = dataModel }
+}
+
+// WithLocalLinks links the declarations and files of the package to its
+// sources on disk rather than on its code host, for the output to be
+// browsed inside the repository, in IDEs and in offline mirrors: mode is
+// "relative", for links relative to the directory of the output, such as
+// ./client.go#L42, or "file", for file:// URLs.
+func WithLocalLinks(mode string) Option {
+	return func(c *Converter) { c.localLinks = mode }
+}
+
+// WithGRPCServices summarizes the gRPC services of the packages generated
+// by protoc-gen-go-grpc in a table of their RPCs, with their request and
+// response types and whether they stream them.
+func WithGRPCServices(services bool) Option {
+	return func(c *Converter) { c.grpcServices = services }
+}
+
+// WithRoutes adds a Routes section listing the HTTP routes registered by
+// the package with net/http, chi, gin or echo, found in its sources, with
+// their methods and handlers.
+func WithRoutes(routes bool) Option {
+	return func(c *Converter) { c.routes = routes }
+}
+
+// WithObservability adds an Observability section listing the Prometheus
+// metrics and the OpenTelemetry instruments and tracers registered by the
+// package, found in its sources, with their types, labels and
+// descriptions, and the symbols declaring them.
+func WithObservability(observability bool) Option {
+	return func(c *Converter) { c.observability = observability }
+}
+
+// WithConfigKeys adds a Configuration section listing the flags defined
+// by the package with flag, pflag or cobra, and the configuration keys it
+// reads with viper or koanf, found in its sources, with their types,
+// defaults and descriptions, from their usages or the comments next to
+// them.
+func WithConfigKeys(keys bool) Option {
+	return func(c *Converter) { c.configKeys = keys }
+}
+
+// WithBenchmarks adds a Benchmarks section listing the Benchmark
+// functions of the test files of the package, with their doc comments.
+func WithBenchmarks(benchmarks bool) Option {
+	return func(c *Converter) { c.benchmarks = benchmarks }
+}
+
+// WithBenchstatTable follows the Benchmarks section with an empty table
+// of the benchmarks, to paste benchstat results into.
+func WithBenchstatTable(table bool) Option {
+	return func(c *Converter) { c.benchstatTable = table }
+}
+
+// WithTests adds a Tests section listing the Test functions of the test
+// files of the package, with their doc comments, for packages whose tests
+// serve as documentation.
+func WithTests(tests bool) Option {
+	return func(c *Converter) { c.tests = tests }
+}
+
+// WithExampleTabs renders multiple examples of a symbol as tabs for the
+// given renderer: "docusaurus" or "mkdocs".
+func WithExampleTabs(renderer string) Option {
+	return func(c *Converter) { c.exampleTabs = renderer }
+}
+
+// WithDeclLinks links identifiers to their declarations. It is enabled
+// by default.
+func WithDeclLinks(links bool) Option {
+	return func(c *Converter) { c.declLinks = links }
+}
+
+// WithLocalDeclLinks links the types of the package used in the
+// declarations to their headings in the document, and the identifiers of
+// other packages to their documentation, for the output to be navigable
+// on its own.
+func WithLocalDeclLinks(local bool) Option {
+	return func(c *Converter) { c.localDeclLinks = local }
+}
+
+// WithHTMLAnchors emits explicit <a id> anchors before headings, for
+// renderers that don't generate heading IDs.
+func WithHTMLAnchors(anchors bool) Option {
+	return func(c *Converter) { c.htmlAnchors = anchors }
+}
+
+// WithLineBreaks preserves the line breaks of doc comment paragraphs
+// instead of letting the renderer reflow them, with the given style:
+// "spaces" ends lines with two spaces, "br" ends them with <br>.
+func WithLineBreaks(style string) Option {
+	return func(c *Converter) { c.lineBreaks = style }
+}
+
+// WithBackticks wraps the exported identifiers of the package mentioned
+// in doc comments in backticks, so that they render as code.
+func WithBackticks(backticks bool) Option {
+	return func(c *Converter) { c.backticks = backticks }
+}
+
+// WithLead styles the first sentence of doc comments, the conventional
+// summary sentence, with the given style: "bold" or "italic".
+func WithLead(style string) Option {
+	return func(c *Converter) { c.lead = style }
+}
+
+// WithPackageLinks links the identifiers of other packages mentioned in
+// doc comments and declarations, such as io.Reader, to their documentation.
+func WithPackageLinks(links bool) Option {
+	return func(c *Converter) { c.pkgLinks = links }
+}
+
+// WithPackageLinkBase sets the base URL of the documentation of other
+// packages, "https://pkg.go.dev" by default. It applies to package links
+// and to doc links such as [io.Reader].
+func WithPackageLinkBase(url string) Option {
+	return func(c *Converter) { c.pkgLinkBase = strings.TrimSuffix(url, "/") }
+}
+
+// WithGroupOptions groups functional options (With... constructors of an
+// Option type) under the type they configure.
+func WithGroupOptions(group bool) Option {
+	return func(c *Converter) { c.groupOpts = group }
+}
+
+// WithFoldAccessors collapses trivially documented getter and setter
+// methods into a table under their type.
+func WithFoldAccessors(fold bool) Option {
+	return func(c *Converter) { c.foldAccess = fold }
+}
+
+// WithPermalinks emits a row of source, pkg.go.dev and permalink links
+// under each symbol heading.
+func WithPermalinks(permalinks bool) Option {
+	return func(c *Converter) { c.permalinks = permalinks }
+}
+
+// WithPkgsiteLinks appends to the heading of each exported symbol a
+// small link to its documentation on pkg.go.dev, such as
+// https://pkg.go.dev/net/http#Client.Do.
+func WithPkgsiteLinks(links bool) Option {
+	return func(c *Converter) { c.pkgsiteLinks = links }
+}
+
+// WithTOC extends the links at the top of the output into a table of
+// contents of the functions, types, methods and examples of the package.
+func WithTOC(toc bool) Option {
+	return func(c *Converter) { c.toc = toc }
+}
+
+// WithConstValues annotates the constant declarations using iota, such
+// as enums, with a table of the values of their constants.
+func WithConstValues(values bool) Option {
+	return func(c *Converter) { c.constValues = values }
+}
+
+// WithConstGroups puts the constants of each type under their own
+// Constants heading in the section of the type, with an anchor and an
+// entry in the index.
+func WithConstGroups(group bool) Option {
+	return func(c *Converter) { c.constGroups = group }
+}
+
+// WithHazards flags the functions and methods whose implementation uses
+// package unsafe, the unsafe parts of package reflect or //go:linkname
+// with a warning admonition.
+func WithHazards(hazards bool) Option {
+	return func(c *Converter) { c.hazards = hazards }
+}
+
+// WithDeprecated renders the "Deprecated: " paragraphs of doc comments
+// as a caution admonition.
+func WithDeprecated(deprecated bool) Option {
+	return func(c *Converter) { c.deprecated = deprecated }
+}
+
+// WithDeprecatedSection lists the deprecated symbols of packages, with
+// their deprecation notice, in a Deprecated APIs section.
+func WithDeprecatedSection(section bool) Option {
+	return func(c *Converter) { c.deprecatedSection = section }
+}
+
+// WithBuildConstraints notes the build constraint of the file declaring
+// each symbol, from its //go:build line and its name, such as
+// foo_linux.go, under the heading of the symbol.
+func WithBuildConstraints(show bool) Option {
+	return func(c *Converter) { c.buildConstraints = show }
+}
+
+// WithHeadingOffset shifts the level of all the generated headings by
+// offset, such as 2 for the title to be a level 3 heading, to embed the
+// output in a larger document.
+func WithHeadingOffset(offset int) Option {
+	return func(c *Converter) { c.headingOffset = offset }
+}
+
+// WithHeadingLevels sets the level of the headings of each role, one of
+// title, section, symbol, member, subsection or comment, as in the flat
+// layout: the headings of the role in the other layouts move along,
+// keeping their nesting. The heading offset applies on top of them.
+func WithHeadingLevels(levels map[string]int) Option {
+	return func(c *Converter) { c.headingLevels = levels }
+}
+
+// WithTextFilter makes Lint pipe the prose of the doc comments of the
+// package through the shell command cmd, such as a spell checker, and
+// report its findings at the position of the comments in the sources.
+// The command reads the comments on its standard input, separated by
+// blank lines, and writes a finding per line, given as "line:message"
+// or "line:column:message" optionally prefixed with a file name, or as a
+// single misspelled word.
+func WithTextFilter(cmd string) Option {
+	return func(c *Converter) { c.textFilter = cmd }
+}
+
+// WithHeaderFile prepends the content of the Markdown file name to the
+// generated documentation.
+func WithHeaderFile(name string) Option {
+	return func(c *Converter) { c.headerFile = name }
+}
+
+// WithBadges adds badges under the title of the documentation: the
+// built-in "reference" (pkg.go.dev), "goreportcard", "license" and
+// "goversion" badges, or Markdown templates with the placeholders
+// {import}, {module}, {go} and {license}. The badges whose placeholders
+// have no value, such as {license} without a license file, are left out.
+func WithBadges(badges ...string) Option {
+	return func(c *Converter) { c.badges = badges }
+}
+
+// WithMetadata lists the module path, Go version and license of the
+// module of the package, from its go.mod and LICENSE files, under the
+// title of the documentation.
+func WithMetadata(metadata bool) Option {
+	return func(c *Converter) { c.metadata = metadata }
+}
+
+// WithConcurrencyBadges shows a badge under the types whose doc comment
+// states whether they're safe for concurrent use, such as "Safe for
+// concurrent use.".
+func WithConcurrencyBadges(show bool) Option {
+	return func(c *Converter) { c.concurrencyBadges = show }
+}
+
+// WithFrontMatter prepends front matter in the given format, "yaml" or
+// "toml", to the generated documentation, for static site generators
+// such as Hugo or Docusaurus. Its title and slug default to the package
+// name and its description to the package synopsis; fields overrides
+// them, or adds others such as weight.
+func WithFrontMatter(format string, fields map[string]string) Option {
+	return func(c *Converter) { c.frontMatterFormat, c.frontMatterFields = format, fields }
+}
+
+// WithPackageMeta overrides the titles, the descriptions and the tags of
+// the packages, by import path, set by their godoc2md directives or
+// derived from them, see PackageMeta.
+func WithPackageMeta(meta map[string]PackageMeta) Option {
+	return func(c *Converter) { c.packageMetas = meta }
+}
+
+// WithAssetDir copies the assets referenced by "Image:" lines of doc
+// comments into dir, the directory of the generated file.
+func WithAssetDir(dir string) Option {
+	return func(c *Converter) { c.assetDir = dir }
+}
+
+// WithEmbedAssets links the files of the package directory referenced by
+// their relative paths from the text of doc comments, such as
+// doc/arch.png, as images for pictures, and copies them next to the
+// generated file, like the assets of "Image:" lines.
+func WithEmbedAssets(embed bool) Option {
+	return func(c *Converter) { c.embedAssets = embed }
+}
+
+// WithOutPath sets the template of the output file path of each package
+// written by ConvertTree, with fields ImportPath, Dir and RelPath.
+func WithOutPath(tmpl string) Option {
+	return func(c *Converter) { c.outPathFormat = tmpl }
+}
+
+// WithReport makes ConvertTree write to w the summary of the symbols
+// whose documentation changed in each file, see ReportChanges.
+func WithReport(w io.Writer) Option {
+	return func(c *Converter) { c.report = w }
+}
+
+// WithReportFormat sets the format of the summary written with
+// WithReport: "text", the default, see ReportChanges, "json", see
+// ReportChangesJSON, or "digest", see ReportChangesDigest.
+func WithReportFormat(format string) Option {
+	return func(c *Converter) { c.reportFormat = format }
+}
+
+// WithWhatsNew adds a "What's new" section at the top of the
+// documentation, listing the functions, types and methods missing from
+// the previous output: the file being regenerated by ConvertTree, or the
+// one set with WithPrevious.
+func WithWhatsNew(whatsNew bool) Option {
+	return func(c *Converter) { c.whatsNew = whatsNew }
+}
+
+// WithPrevious sets the previous output of Convert, see WithWhatsNew.
+func WithPrevious(md []byte) Option {
+	return func(c *Converter) { c.previous = md }
+}
+
+// WithCache makes ConvertTree skip the packages whose Go files didn't
+// change since the previous run, as recorded in the cache file name.
+// The settings identify the configuration of the conversion, such as
+// the command line: a change of settings invalidates the cache.
+func WithCache(name, settings string) Option {
+	return func(c *Converter) { c.cacheFile, c.cacheSettings = name, settings }
+}
+
+// WithWatchFiles adds the files, or directories of files, that Watch
+// polls along with the sources of the packages, such as the template the
+// caller read, to rebuild its Converter with New when they change.
+func WithWatchFiles(names ...string) Option {
+	return func(c *Converter) {
+		for _, name := range names {
+			if name != "" {
+				c.watchFiles = append(c.watchFiles, name)
+			}
+		}
+	}
+}
+
+// WithJobs makes ConvertTree render up to n packages at a time, each by
+// its own Converter configured with the same options. The files are
+// still written, reported and cached in order, so the outputs are the
+// same as with a single job, the default.
+func WithJobs(n int) Option {
+	return func(c *Converter) { c.jobs = n }
+}
+
+// WithSymbols records where the documentation of each symbol of the
+// converted packages is, by output file and anchor, to be written to the
+// JSON file name by WriteSymbols. As Convert writes to an io.Writer, the
+// symbols it converts are recorded as written to the file output.
+func WithSymbols(name, output string) Option {
+	return func(c *Converter) { c.symbolsFile, c.symbolsOutput = name, output }
+}
+
+// WithRedirects makes ConvertTree record the anchors of the renamed
+// symbols of each file in the JSON redirect map name, see
+// UpdateRedirects.
+func WithRedirects(name string) Option {
+	return func(c *Converter) { c.redirectsFile = name }
+}
+
+// WithCheck makes ConvertTree write no files, and fail if any of them is
+// out of date.
+func WithCheck(check bool) Option {
+	return func(c *Converter) { c.check = check }
+}
+
+// WithWikiRoot sets the root directory of the wiki the output is written
+// to, for the dialects of wikis, such as "azure-wiki", whose assets are
+// copied into the attachments directory at its root. It's the directory
+// of the output by default.
+func WithWikiRoot(dir string) Option {
+	return func(c *Converter) { c.wikiRoot = dir }
+}
+
+// WithExclude leaves the packages whose import paths match one of the
+// patterns out of the packages resolved by Packages, as in recursive
+// mode. A pattern matches the import path, or its end after a slash,
+// where "..." matches any string and a trailing "/..." the package and
+// those under it, such as "internal/..." or "example.com/mod/cmd/...".
+func WithExclude(patterns ...string) Option {
+	return func(c *Converter) {
+		for _, pattern := range patterns {
+			if pattern != "" {
+				c.excludeRx = append(c.excludeRx, excludeRx(pattern))
+			}
+		}
+	}
+}
+
+// WithExcludeFiles leaves the declarations of the Go files whose names
+// match one of the patterns, such as "*_gen.go" or "zz_generated*", out
+// of the output, see path.Match.
+func WithExcludeFiles(patterns ...string) Option {
+	return func(c *Converter) {
+		for _, pattern := range patterns {
+			if pattern != "" {
+				c.excludeFiles = append(c.excludeFiles, pattern)
+			}
+		}
+	}
+}
+
+// WithExcludeSymbols leaves the symbols whose names match the regular
+// expression rx out of the output, the methods by their name or by
+// T.M, with their examples.
+func WithExcludeSymbols(rx string) Option {
+	return func(c *Converter) { c.excludeSymbols = rx }
+}
+
+// WithVanity enables the resolution of the vanity import paths, such as
+// go.uber.org/zap, into the repositories of their go-import meta tags, for
+// their source links. The resolutions are saved in the cache file, if not
+// empty. The repositories of well-known vanity import paths are used
+// when their meta tags can't be fetched.
+func WithVanity(enabled bool, cache string) Option {
+	return func(c *Converter) { c.vanity, c.vanityCache = enabled, cache }
+}
+
+// WithHashFormat sets the format of the line hash of source links,
+// "#L%d" by default. A format with two verbs, such as "#L%d-L%d", is
+// passed the first and the last line of the declaration.
+func WithHashFormat(format string) Option {
+	return func(c *Converter) { c.srcLinkHashFormat = format }
+}
+
+// WithLineRanges links the declarations spanning several lines to the
+// range of their lines, with the range anchors of their host, see
+// SourceHost.Lines, instead of their first line.
+func WithLineRanges(ranges bool) Option {
+	return func(c *Converter) { c.lineRanges = ranges }
+}
+
+// WithSelections links the declarations to the selection of their
+// source, from their first to their last character, on the hosts with
+// selection anchors, see SourceHost.Selection. It takes precedence over
+// WithLineRanges.
+func WithSelections(selections bool) Option {
+	return func(c *Converter) { c.selections = selections }
+}
+
+// WithSrcLinkFormat sets the format of source links, appended to the URL
+// of the package directory: a text/template with the fields of
+// SourceLink, such as "/{{.File}}#L{{.Line}}-L{{.EndLine}}", or a printf
+// format passed the file name, line, and start and end offsets.
+func WithSrcLinkFormat(format string) Option {
+	return func(c *Converter) { c.srcLinkFormat = format }
+}
+
+// WithSourceHosts registers the platforms hosting packages, to link to
+// their sources. They take precedence over the built-in ones, which
+// cover GitHub, Bitbucket, GitLab, Gitea, Codeberg, SourceHut and Azure
+// DevOps.
+func WithSourceHosts(hosts ...SourceHost) Option {
+	return func(c *Converter) { c.sourceHosts = append(c.sourceHosts, hosts...) }
+}
+
+// WithRawLinks links the assets referenced by "Image:" lines of doc
+// comments and the package files to their raw contents on the host of
+// the package, such as raw.githubusercontent.com, see SourceHost.Raw,
+// instead of copying the assets next to the output.
+func WithRawLinks(raw bool) Option {
+	return func(c *Converter) { c.rawLinks = raw }
+}
+
+// WithStdlibLinks selects the site of the source links of the standard
+// library packages: "golang.org", the default, "go.dev", or "cs" for
+// https://cs.opensource.google, which links to the revision set with
+// WithRef, such as go1.22.0.
+func WithStdlibLinks(site string) Option {
+	return func(c *Converter) { c.stdlibLinks = site }
+}
+
+// WithRef sets the branch, tag or commit that source links point at,
+// "master" by default. See GitHead to link to the current branch or
+// commit of a repository.
+func WithRef(ref string) Option {
+	return func(c *Converter) { c.ref = ref }
+}
+
+// WithNotes sets the regular expression matching the note markers to
+// show, "BUG" by default.
+func WithNotes(rx string) Option {
+	return func(c *Converter) { c.notesRx = rx }
+}
+
+// WithNoteStyles sets the rendering style of the notes of each marker:
+// list, note, tip, important, warning or caution.
+func WithNoteStyles(styles map[string]string) Option {
+	return func(c *Converter) { c.noteStyleByMarker = styles }
+}
+
+// WithNoteTitles overrides the section title of the notes of each marker.
+func WithNoteTitles(titles map[string]string) Option {
+	return func(c *Converter) { c.noteTitles = titles }
+}
+
+// WithFilters restricts the documentation to the symbols matching names,
+// which are exact names or regular expressions.
+func WithFilters(names ...string) Option {
+	return func(c *Converter) { c.filters = names }
+}
@@ -0,0 +1,27 @@
+package godoc2md
+
+import (
+	pathpkg "path"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// overviewFiles are the Markdown files of a package directory merged
+// into the Overview section, in order of preference.
+var overviewFiles = []string{"doc.md", "OVERVIEW.md"}
+
+// overviewMdFunc returns the content of the first Markdown overview file
+// found in the package directory, so that long-form prose can be written
+// in Markdown rather than in comment blocks.
+func (c *Converter) overviewMdFunc(info *godoc.PageInfo) string {
+	for _, name := range overviewFiles {
+		data, err := vfs.ReadFile(c.fs, pathpkg.Join(info.Dirname, name))
+		if err != nil {
+			continue
+		}
+		return strings.TrimRight(string(data), "\n") + "\n\n"
+	}
+	return ""
+}
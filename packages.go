@@ -0,0 +1,132 @@
+package godoc2md
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// expandPackages resolves a package pattern, such as
+// "." or "./...", into the import paths of the matching packages, using
+// the go command so that packages of the current module (including
+// replaced and nested modules) are found as well as GOPATH packages.
+// If the pattern can't be resolved this way, it is returned as is, to
+// be looked up in the godoc file system. The patterns of remote modules
+// at a version, such as github.com/foo/bar/...@v1.2.3, are resolved in
+// the module cache, downloading them.
+func (c *Converter) expandPackages(pattern string) []string {
+	if strings.HasPrefix(pattern, cmdPathPrefix) || strings.HasPrefix(pattern, srcPathPrefix) {
+		return []string{pattern}
+	}
+	if pattern, version, ok := splitVersion(pattern); ok {
+		return c.remotePackages(pattern, version)
+	}
+
+	dirs := []string{""}
+	if strings.HasSuffix(pattern, "/...") && isLocalPattern(pattern) {
+		dirs = append(dirs, nestedModules(strings.TrimSuffix(pattern, "/..."))...)
+	}
+
+	var paths []string
+	loaded := false
+	for _, dir := range dirs {
+		pat := pattern
+		if dir != "" {
+			pat = "./..."
+		}
+		cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: dir}
+		pkgs, err := packages.Load(cfg, pat)
+		if err != nil {
+			if c.verbose {
+				log.Printf("loading %s: %v", pat, err)
+			}
+			continue
+		}
+		for _, pkg := range pkgs {
+			pkgDir := packageDir(pkg)
+			if pkgDir == "" || pkg.PkgPath == "" {
+				continue
+			}
+			loaded = true
+			if c.excluded(pkg.PkgPath) {
+				continue
+			}
+			if _, seen := c.pkgDirs[pkg.PkgPath]; seen {
+				continue
+			}
+			c.pkgDirs[pkg.PkgPath] = pkgDir
+			paths = append(paths, pkg.PkgPath)
+		}
+	}
+	if len(paths) == 0 && !loaded {
+		return []string{pattern}
+	}
+	return paths
+}
+
+// excluded reports whether the import path matches one of the patterns
+// set with WithExclude.
+func (c *Converter) excluded(importPath string) bool {
+	for _, rx := range c.excludeRx {
+		if rx.MatchString(importPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeRx returns the regular expression matching the import paths
+// of the exclude pattern, such as "internal/..." for the packages named
+// internal and under them, anywhere in the tree, where "..." matches
+// any string.
+func excludeRx(pattern string) *regexp.Regexp {
+	rx := regexp.QuoteMeta(strings.TrimSuffix(pattern, "/"))
+	rx = strings.Replace(rx, `/\.\.\.`, `(/.*)?`, -1)
+	rx = strings.Replace(rx, `\.\.\.`, `.*`, -1)
+	return regexp.MustCompile(`(^|/)` + rx + `$`)
+}
+
+// isLocalPattern reports whether pattern designates a directory
+// rather than an import path.
+func isLocalPattern(pattern string) bool {
+	return filepath.IsAbs(pattern) || pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
+
+// packageDir returns the directory holding the files of pkg.
+func packageDir(pkg *packages.Package) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
+		}
+	}
+	return ""
+}
+
+// nestedModules returns the directories below root, excluding root
+// itself, that hold a go.mod file. Packages of nested modules are not
+// matched by a "./..." pattern run from the enclosing module.
+func nestedModules(root string) []string {
+	var dirs []string
+	_ = filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		}
+		name := fi.Name()
+		if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		if path == root {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
@@ -0,0 +1,43 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+const pkgGoDevURL = "https://pkg.go.dev/"
+
+// permalinksMdFunc returns the link row emitted under the heading of the
+// symbol declared by decl when permalinks are enabled. The anchor is the one
+// used by both the generated headings and pkg.go.dev, e.g. "Type.Method".
+func (c *Converter) permalinksMdFunc(info *godoc.PageInfo, decl ast.Node, anchor string) string {
+	if !c.permalinks || info.PDoc == nil {
+		return ""
+	}
+	return fmt.Sprintf("[source](%s) [pkg.go.dev](%s%s#%s) [permalink](#%s)\n\n",
+		c.sourceLink(info, decl.Pos(), decl.End()), pkgGoDevURL, info.PDoc.ImportPath, anchor, anchor)
+}
+
+// pkgsiteLinkMdFunc returns the link to the documentation of the symbol
+// anchor, such as "Type.Method", on pkg.go.dev, appended to its heading
+// when enabled, or the empty string for the unexported symbols and the
+// commands, which pkg.go.dev doesn't document.
+func (c *Converter) pkgsiteLinkMdFunc(info *godoc.PageInfo, anchor string) string {
+	if !c.pkgsiteLinks || info.PDoc == nil || info.IsMain {
+		return ""
+	}
+	for _, name := range strings.Split(anchor, ".") {
+		if !token.IsExported(name) {
+			return ""
+		}
+	}
+	link := fmt.Sprintf("[pkg.go.dev](%s%s#%s)", pkgGoDevURL, info.PDoc.ImportPath, anchor)
+	if c.dialect.plain {
+		return " " + link
+	}
+	return " <sub>" + link + "</sub>"
+}
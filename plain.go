@@ -0,0 +1,135 @@
+package godoc2md
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// plainAnchorRx matches the lines of explicit heading anchors.
+	plainAnchorRx = regexp.MustCompile(`^<a id="([^"]*)"></a>$`)
+
+	// plainDocLinkRx matches the intra-document Markdown links.
+	plainDocLinkRx = regexp.MustCompile(`\[([^\]]*)\]\(#([^)]*)\)`)
+
+	// plainLinkRx matches the HTML links, rewritten as Markdown links.
+	plainLinkRx = regexp.MustCompile(`<a href="([^"]*)"[^>]*>(.*?)</a>`)
+
+	// plainTagRx matches the HTML tags of the output, which are dropped.
+	plainTagRx = regexp.MustCompile(`</?(a|b|br|code|details|div|em|i|li|ol|p|pre|span|strong|summary|sup|ul)\b[^>]*>`)
+
+	// plainAlertRx matches the first line of the GitHub alerts, such as
+	// "> [!NOTE]".
+	plainAlertRx = regexp.MustCompile(`^> \[!([A-Z]+)\]$`)
+)
+
+// plainMarkdown rewrites the Markdown md for the plain dialect, whose
+// renderers support neither tables, nor HTML, nor fenced code blocks:
+// the fenced and <pre> code blocks are indented, the rows of tables are
+// listed, the GitHub alerts are titled in text, the HTML links are
+// replaced by Markdown links, the items of HTML lists by Markdown ones,
+// and the other HTML tags and the heading anchors are dropped. The
+// intra-document links to the anchors of headings link to their slugs
+// instead, those to other anchors are left as text.
+func plainMarkdown(md string) string {
+	var out []string
+	var text []int // the lines of out outside code blocks
+	// codeBlock starts an indented code block, which can't interrupt a
+	// paragraph
+	codeBlock := func() {
+		if n := len(out); n > 0 && out[n-1] != "" && !strings.HasPrefix(out[n-1], "#") {
+			out = append(out, "")
+		}
+	}
+	headings := make(map[string]string) // slugs, by anchor
+	slugs := make(map[string]int)       // of the headings, to number those repeated
+	var pending []string                // anchors of the next heading
+	lines := strings.Split(md, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "```"):
+			codeBlock()
+			// the fence ends at a line of at least as many backticks
+			fence := line[:len(line)-len(strings.TrimLeft(line, "`"))]
+			for i++; i < len(lines) && !(strings.HasPrefix(lines[i], fence) && strings.Trim(lines[i], "`") == ""); i++ {
+				out = append(out, strings.TrimRight("    "+lines[i], " "))
+			}
+		case strings.HasPrefix(line, "<pre>"):
+			code := line
+			for ; !strings.Contains(lines[i], "</pre>") && i+1 < len(lines); i++ {
+				code += "\n" + lines[i+1]
+			}
+			code = html.UnescapeString(plainTagRx.ReplaceAllString(code, ""))
+			codeBlock()
+			for _, l := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+				out = append(out, strings.TrimRight("    "+l, " "))
+			}
+		case strings.HasPrefix(line, "|"):
+			// the header row, followed by the delimiter row, is left out
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "| ---") {
+				i++
+				continue
+			}
+			var cells []string
+			for _, cell := range strings.Split(strings.Trim(strings.TrimSpace(line), "|"), " | ") {
+				if cell = strings.TrimSpace(cell); cell != "" {
+					cells = append(cells, strings.Replace(cell, `\|`, "|", -1))
+				}
+			}
+			text = append(text, len(out))
+			out = append(out, "* "+plainLine(strings.Join(cells, " — ")))
+		case plainAnchorRx.MatchString(line):
+			pending = append(pending, plainAnchorRx.FindStringSubmatch(line)[1])
+		case plainAlertRx.MatchString(line):
+			kind := plainAlertRx.FindStringSubmatch(line)[1]
+			out = append(out, "> **"+kind[:1]+strings.ToLower(kind[1:])+":**")
+		default:
+			l := plainLine(strings.Replace(line, "<li>", "* ", -1))
+			if strings.TrimSpace(l) == "" && strings.TrimSpace(line) != "" {
+				// a line of HTML tags only
+				continue
+			}
+			if m := atxHeadingRx.FindStringSubmatch(l); m != nil {
+				// numbered as verifyMarkdown does
+				slug := githubSlug(mdLinkRx.ReplaceAllString(m[2], "$1"))
+				if k := slugs[slug]; k > 0 {
+					slugs[slug]++
+					slug = fmt.Sprintf("%s-%d", slug, k)
+				} else {
+					slugs[slug] = 1
+				}
+				headings[slug] = slug
+				for _, a := range htmlAnchorRx.FindAllStringSubmatch(line, -1) {
+					pending = append(pending, a[2])
+				}
+				for _, id := range pending {
+					headings[id] = slug
+				}
+				pending = nil
+			}
+			text = append(text, len(out))
+			out = append(out, l)
+		}
+	}
+	for _, i := range text {
+		out[i] = plainDocLinkRx.ReplaceAllStringFunc(out[i], func(link string) string {
+			m := plainDocLinkRx.FindStringSubmatch(link)
+			if slug, ok := headings[m[2]]; ok {
+				return "[" + m[1] + "](#" + slug + ")"
+			}
+			return m[1]
+		})
+	}
+	return strings.Join(out, "\n")
+}
+
+// plainLine rewrites the HTML links of the text of Markdown line as
+// Markdown links, drops the other HTML tags and unescapes the HTML
+// entities.
+func plainLine(line string) string {
+	line = plainLinkRx.ReplaceAllString(line, "[$2]($1)")
+	return html.UnescapeString(plainTagRx.ReplaceAllString(line, ""))
+}
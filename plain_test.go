@@ -0,0 +1,35 @@
+package godoc2md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlainMarkdown(t *testing.T) {
+	testData := []struct {
+		md, expected string
+	}{
+		{"<a id=\"Foo\"></a>\n## <a name=\"Foo\">func</a> [Foo](foo.go)\n", "## func [Foo](foo.go)\n"},
+		{"Text\n``` go\nx := 1\n```\n", "Text\n\n    x := 1\n"},
+		{"#### Example\n````\n```\n````\n", "#### Example\n    ```\n"},
+		{"<pre>func <a href=\"#T\">F</a>() T &amp; U</pre>\n", "    func F() T & U\n"},
+		{"| Name | Synopsis |\n| --- | --- |\n| [a](a/) | A \\| B |\n| [b](b/) |  |\n", "* [a](a/) — A | B\n* [b](b/)\n"},
+		{"> [!NOTE]\n> Read this.\n", "> **Note:**\n> Read this.\n"},
+		{"<ul style=\"list-style: none;\">\n<li><a href=\"x.go\">&#x261e;</a> broken</li>\n</ul>\n", "* [☞](x.go) broken\n"},
+		{"* [Index](#pkg-index)\n* [func (t \\*T) Do()](#T.Do)\n\n## <a name=\"pkg-index\">Index</a>\n### <a name=\"T.Do\">func</a> (\\*T) [Do](t.go)\n",
+			"* [Index](#index)\n* [func (t \\*T) Do()](#func-t-do)\n\n## Index\n### func (\\*T) [Do](t.go)\n"},
+		{"<a id=\"example-t\"></a>\n#### Example [T](t.go):\n<a id=\"example-u\"></a>\n#### Example [T](u.go):\nSee [T](#example-t), [U](#example-u).\n",
+			"#### Example [T](t.go):\n#### Example [T](u.go):\nSee [T](#example-t), [U](#example-t-1).\n"},
+		{"| Name |\n| --- |\n| <a name=\"T\"></a>[T](t.go) |\n\nSee [T](#T) and [Max](#pkg-constants).\n", "* [T](t.go)\n\nSee T and Max.\n"},
+	}
+	for _, tt := range testData {
+		got := plainMarkdown(tt.md)
+		if got != tt.expected {
+			t.Errorf("plainMarkdown(%q): expected %q, got %q", tt.md, tt.expected, got)
+		}
+		// the links left resolve to the headings
+		if findings := verifyMarkdown([]byte(got), githubSlug); len(findings) > 0 {
+			t.Errorf("plainMarkdown(%q): %s", tt.md, strings.Join(findings, "; "))
+		}
+	}
+}
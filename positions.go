@@ -0,0 +1,117 @@
+package godoc2md
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// nodeRange returns the range of n, which must be an ast.Node,
+// a *doc.Note, a *doc.Example or a token.Pos.
+func nodeRange(n interface{}) (pos, end token.Pos) {
+	switch n := n.(type) {
+	case ast.Node:
+		return n.Pos(), n.End()
+	case *doc.Note:
+		return n.Pos, n.End
+	case *doc.Example:
+		return n.Code.Pos(), n.Code.End()
+	case token.Pos:
+		return n, n
+	}
+	panic(fmt.Sprintf("wrong type for position template function: %T", n))
+}
+
+// relativePosition turns the position of a file of the package bound at
+// targetPath into a position relative to the package directory.
+func relativePosition(p token.Position) token.Position {
+	p.Filename = strings.TrimPrefix(p.Filename, targetPath+"/")
+	return p
+}
+
+// positionFunc returns the position of the start of n. The file name is
+// relative to the package directory.
+func positionFunc(info *godoc.PageInfo, n interface{}) token.Position {
+	pos, _ := nodeRange(n)
+	return relativePosition(info.FSet.Position(pos))
+}
+
+// endPositionFunc returns the position of the end of n.
+func endPositionFunc(info *godoc.PageInfo, n interface{}) token.Position {
+	_, end := nodeRange(n)
+	return relativePosition(info.FSet.Position(end))
+}
+
+// fileOfFunc returns the name of the file declaring n, relative to the
+// package directory.
+func fileOfFunc(info *godoc.PageInfo, n interface{}) string {
+	return positionFunc(info, n).Filename
+}
+
+// lineOfFunc returns the line of the start of n.
+func lineOfFunc(info *godoc.PageInfo, n interface{}) int {
+	return positionFunc(info, n).Line
+}
+
+// endLineOfFunc returns the line of the end of n.
+func endLineOfFunc(info *godoc.PageInfo, n interface{}) int {
+	return endPositionFunc(info, n).Line
+}
+
+// A srcFile is a source file of the package being converted, indexed
+// by line.
+type srcFile struct {
+	data   []byte
+	starts []int // offsets of the lines
+}
+
+// offsetPosition returns the line and the column, in characters, of
+// offset in the file name of the file system, or zeros if the file can't
+// be read.
+func (c *Converter) offsetPosition(name string, offset int) (line, col int) {
+	f := c.srcFile(name)
+	if f == nil || offset < 0 || offset > len(f.data) {
+		return 0, 0
+	}
+	// the number of lines starting at or before offset
+	line = sort.Search(len(f.starts), func(i int) bool { return f.starts[i] > offset })
+	col = utf8.RuneCount(f.data[f.starts[line-1]:offset]) + 1
+	return line, col
+}
+
+// srcFile returns the file name of the file system, read on first use,
+// or nil if it can't be read.
+func (c *Converter) srcFile(name string) *srcFile {
+	f, ok := c.srcFiles[name]
+	if !ok {
+		if data, err := vfs.ReadFile(c.fs, name); err == nil {
+			f = &srcFile{data: data, starts: []int{0}}
+			for i, b := range data {
+				if b == '\n' {
+					f.starts = append(f.starts, i+1)
+				}
+			}
+		}
+		if c.srcFiles == nil {
+			c.srcFiles = make(map[string]*srcFile)
+		}
+		c.srcFiles[name] = f
+	}
+	return f
+}
+
+// lineAt returns the line of offset in the file name of the file system,
+// or line if the file can't be read or offset is before line.
+func (c *Converter) lineAt(name string, offset, line int) int {
+	if n, _ := c.offsetPosition(name, offset); n > line {
+		return n
+	}
+	return line
+}
@@ -0,0 +1,260 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// outputPath holds the fields available to the output path template.
+type outputPath struct {
+	ImportPath string // import path of the package
+	Dir        string // directory of the package, relative to the current directory
+	RelPath    string // path of the package relative to the root, "." for the root itself
+}
+
+// recursivePattern returns the package pattern matching all the
+// packages under root, followed by its version if it has one.
+func recursivePattern(root string) string {
+	if root, version, ok := splitVersion(root); ok {
+		return recursivePattern(root) + "@" + version
+	}
+	root = strings.TrimSuffix(root, "/...")
+	if root == "." || root == "" {
+		return "./..."
+	}
+	return strings.TrimSuffix(root, "/") + "/..."
+}
+
+// packageOutputPath expands the output path template for the package
+// importPath found under root. The directories of the packages of a
+// remote module at a version, in the module cache, are their relative
+// paths, for their files to be written under the current directory.
+func (c *Converter) packageOutputPath(tmpl *template.Template, root, importPath string) (string, error) {
+	root, _, remote := splitVersion(root)
+	root = strings.TrimSuffix(strings.TrimSuffix(root, "/..."), "/")
+	p := outputPath{ImportPath: importPath, RelPath: "."}
+	dir := c.pkgDirs[importPath]
+	if remote {
+		dir = ""
+	}
+	if cwd, err := os.Getwd(); err == nil && dir != "" {
+		if rel, err := filepath.Rel(cwd, dir); err == nil {
+			p.Dir = filepath.ToSlash(rel)
+		}
+	}
+	if isLocalPattern(root) {
+		if abs, err := filepath.Abs(root); err == nil && dir != "" {
+			if rel, err := filepath.Rel(abs, dir); err == nil {
+				p.RelPath = filepath.ToSlash(rel)
+			}
+		}
+	} else if strings.HasPrefix(importPath, root+"/") {
+		p.RelPath = strings.TrimPrefix(importPath, root+"/")
+	}
+	if remote {
+		p.Dir = p.RelPath
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, p); err != nil {
+		return "", err
+	}
+	return filepath.Clean(filepath.FromSlash(buf.String())), nil
+}
+
+// ConvertTree writes the documentation of every package under root
+// into its own file, as laid out by the output path template set with
+// WithOutPath. The assets of each package are copied next to its file.
+// The files whose content doesn't change are left untouched, and the
+// changes of the others are reported to the writer set with WithReport,
+// if any. With WithCache, the packages whose sources didn't change since
+// the previous run are skipped. With WithCheck, no file is written and
+// an error lists the files that are out of date. The packages that fail
+// to convert are reported to the log, and listed by the error returned
+// once the others are written.
+func (c *Converter) ConvertTree(root string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pathTmpl, err := template.New("outpath").Parse(c.outPathFormat)
+	if err != nil {
+		return fmt.Errorf("outpath: %v", err)
+	}
+	var cache map[string]string
+	if c.cacheFile != "" && !c.check {
+		cache = readCache(c.cacheFile)
+	}
+	var outOfDate, failed []string
+	paths := c.expandPackages(recursivePattern(root))
+	c.treeOutputs = make(map[string]string)
+	defer func() { c.treeOutputs = nil }()
+	for _, path := range paths {
+		if _, ok := c.pkgDirs[path]; !ok {
+			return fmt.Errorf("%s: no packages found", root)
+		}
+		name, err := c.packageOutputPath(pathTmpl, root, path)
+		if err != nil {
+			return fmt.Errorf("outpath: %v", err)
+		}
+		c.treeOutputs[path] = name
+	}
+	hashes := make(map[string]string)
+	var todo []string
+	for _, path := range paths {
+		if cache != nil {
+			hash, name := c.sourceHash(c.pkgDirs[path]), c.treeOutputs[path]
+			if _, err := os.Stat(name); err == nil && hash != "" && cache[path] == hash {
+				if c.verbose {
+					log.Printf("%s is up to date", name)
+				}
+				c.recordOutput(name)
+				continue
+			}
+			hashes[path] = hash
+		}
+		todo = append(todo, path)
+	}
+
+	outs, err := c.renderTree(todo)
+	if err != nil {
+		return err
+	}
+	for i, out := range outs {
+		path, name := todo[i], c.treeOutputs[todo[i]]
+		c.symbols = append(c.symbols, out.symbols...)
+		for _, o := range out.outputs {
+			c.recordOutput(o)
+		}
+		if out.err != nil {
+			// write the other packages, then fail
+			log.Printf("%s: %v", path, out.err)
+			failed = append(failed, path)
+			continue
+		}
+		if !c.check {
+			c.recordOutput(name)
+		}
+		old, new := out.old, out.new
+		hash := hashes[path]
+		if cache != nil && hash != "" {
+			cache[path] = hash
+		}
+		if bytes.Equal(old, new) {
+			continue
+		}
+		if c.check {
+			outOfDate = append(outOfDate, name)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(name, new, 0644); err != nil {
+			return err
+		}
+		if c.verbose {
+			log.Printf("wrote %s", name)
+		}
+		if c.report != nil {
+			if err := reportFormats[c.reportFormat](c.report, name, old, new); err != nil {
+				return err
+			}
+		}
+		if c.redirectsFile != "" {
+			if err := UpdateRedirects(c.redirectsFile, name, old, new); err != nil {
+				return err
+			}
+		}
+	}
+	if cache != nil {
+		if err := writeCache(c.cacheFile, cache); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to convert: %s", strings.Join(failed, ", "))
+	}
+	if len(outOfDate) > 0 {
+		return fmt.Errorf("out of date: %s", strings.Join(outOfDate, ", "))
+	}
+	return nil
+}
+
+// A treeOutput is the documentation of a package rendered by renderTree,
+// with the previous content of its file, or the error rendering it. The
+// symbols and the outputs recorded by a worker are kept to be merged in
+// order into the Converter.
+type treeOutput struct {
+	old, new []byte
+	err      error
+	symbols  []Symbol
+	outputs  []string
+}
+
+// renderTree renders the documentation of the packages paths of
+// ConvertTree to their files of c.treeOutputs, without writing them, in
+// the order of paths. With WithJobs, they're rendered concurrently by as
+// many workers, Converters made with the options of c that share the
+// packages it resolved.
+func (c *Converter) renderTree(paths []string) ([]treeOutput, error) {
+	outs := make([]treeOutput, len(paths))
+	render := func(w *Converter, i int) {
+		name := c.treeOutputs[paths[i]]
+		old, _ := ioutil.ReadFile(name)
+		w.previous = old
+		var buf bytes.Buffer
+		assetDir := filepath.Dir(name)
+		if c.check {
+			assetDir = ""
+		}
+		err := w.writeOutput(&buf, paths[i], assetDir, name)
+		outs[i] = treeOutput{old: old, new: buf.Bytes(), err: err}
+	}
+	jobs := c.jobs
+	if jobs > len(paths) {
+		jobs = len(paths)
+	}
+	if jobs <= 1 {
+		for i := range paths {
+			render(c, i)
+		}
+		return outs, nil
+	}
+
+	workers := make([]*Converter, jobs)
+	for j := range workers {
+		w, err := New(c.opts...)
+		if err != nil {
+			return nil, err
+		}
+		w.pkgDirs, w.treeOutputs = c.pkgDirs, c.treeOutputs
+		workers[j] = w
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				symbols, outputs := len(w.symbols), len(w.outputs)
+				render(w, i)
+				outs[i].symbols = w.symbols[symbols:]
+				outs[i].outputs = w.outputs[outputs:]
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return outs, nil
+}
@@ -0,0 +1,97 @@
+package godoc2md
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Renames returns the anchors of the symbols renamed between old and new,
+// two outputs of the same packages, mapped to their new anchor. A removed
+// symbol is renamed to an added one if their documentation is the same
+// once their names are replaced, such as Client.Get and Client.Fetch with
+// the same doc comment and signature but for the name. The ambiguous
+// renames are left out.
+func Renames(old, new []byte) map[string]string {
+	added := make(map[string][]string)
+	removed := make(map[string][]string)
+	for _, ch := range Changes(old, new) {
+		switch ch.Change {
+		case "added":
+			key := renameKey(ch.Symbol, ch.After)
+			added[key] = append(added[key], ch.Symbol)
+		case "removed":
+			key := renameKey(ch.Symbol, ch.Before)
+			removed[key] = append(removed[key], ch.Symbol)
+		}
+	}
+	renames := make(map[string]string)
+	for key, from := range removed {
+		if to := added[key]; len(from) == 1 && len(to) == 1 {
+			renames[from[0]] = to[0]
+		}
+	}
+	return renames
+}
+
+// renameKey returns the section of the anchor name with the parts of the
+// name, such as the type and the method of Client.Do, replaced with
+// placeholders, so that the sections of a symbol before and after a
+// rename have the same key.
+func renameKey(name, section string) string {
+	for i, part := range strings.Split(name, ".") {
+		rx := regexp.MustCompile(`\b` + regexp.QuoteMeta(part) + `\b`)
+		section = rx.ReplaceAllLiteralString(section, "\x00"+string(rune('0'+i)))
+	}
+	return section
+}
+
+// UpdateRedirects merges the renames of the symbols of the file between
+// its previous content old and its new content new, see Renames, into
+// the JSON file name, which maps "file#anchor" to "file#anchor", the file
+// relative to the directory of name, for static site generators to
+// redirect the links to the old anchors. The redirects to an anchor
+// renamed again are updated, and the redirects renamed back removed.
+func UpdateRedirects(name, file string, old, new []byte) error {
+	renames := Renames(old, new)
+	if len(renames) == 0 {
+		return nil
+	}
+	redirects := make(map[string]string)
+	if data, err := ioutil.ReadFile(name); err == nil {
+		if err := json.Unmarshal(data, &redirects); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if rel, err := filepath.Rel(filepath.Dir(name), file); err == nil {
+		file = filepath.ToSlash(rel)
+	}
+	prefix := file + "#"
+	update := func(anchor string) string {
+		if to, ok := renames[strings.TrimPrefix(anchor, prefix)]; ok && strings.HasPrefix(anchor, prefix) {
+			return prefix + to
+		}
+		return anchor
+	}
+	for from, to := range redirects {
+		redirects[from] = update(to)
+	}
+	for from, to := range renames {
+		redirects[prefix+from] = prefix + to
+	}
+	for from, to := range redirects {
+		if from == to {
+			delete(redirects, from)
+		}
+	}
+	data, err := json.MarshalIndent(redirects, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,125 @@
+package godoc2md
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/tools/go/packages"
+)
+
+// A moduleVersion is a module downloaded by the go command into the
+// module cache, as reported by go mod download -json.
+type moduleVersion struct {
+	Path    string
+	Version string
+	Dir     string
+	Error   string
+	Origin  *struct {
+		Subdir string // of the module in the repository
+		Hash   string // commit
+		Ref    string // such as refs/tags/v1.2.3
+	}
+}
+
+// splitVersion splits the pattern path@version of the packages of a
+// remote module, such as github.com/foo/bar/...@v1.2.3, into the pattern
+// of its packages and the version, if it has one.
+func splitVersion(pattern string) (string, string, bool) {
+	i := strings.LastIndex(pattern, "@")
+	if i <= 0 || isLocalPattern(pattern) {
+		return pattern, "", false
+	}
+	return pattern[:i], pattern[i+1:], true
+}
+
+// downloadModule downloads the module providing the package pattern at
+// version into the module cache, trying the import path of the package
+// and then those of its parent directories as module paths.
+func downloadModule(pattern, version string) (*moduleVersion, error) {
+	var first string // error, for the path of the package
+	for p := strings.TrimSuffix(pattern, "/..."); ; p = pathpkg.Dir(p) {
+		cmd := exec.Command("go", "mod", "download", "-json", p+"@"+version)
+		cmd.Dir = os.TempDir() // outside of any module
+		out, err := cmd.Output()
+		var mod moduleVersion
+		if jerr := json.Unmarshal(out, &mod); jerr == nil && mod.Error == "" && mod.Dir != "" {
+			return &mod, nil
+		}
+		if first == "" {
+			if first = mod.Error; first == "" {
+				first = fmt.Sprintf("%s@%s: go mod download: %v", p, version, err)
+			}
+		}
+		if !strings.Contains(p, "/") {
+			return nil, errors.New(first)
+		}
+	}
+}
+
+// ModuleRef downloads the module of the packages pattern@version, such
+// as github.com/foo/bar@v1.2.3, and returns the tag or the commit of its
+// version, to be linked to with WithRef: the tag of its module, prefixed
+// with its directory in the repository, or the commit of a
+// pseudo-version.
+func ModuleRef(pattern string) (string, error) {
+	pattern, version, ok := splitVersion(pattern)
+	if !ok {
+		return "", fmt.Errorf("%s: no version", pattern)
+	}
+	mod, err := downloadModule(pattern, version)
+	if err != nil {
+		return "", err
+	}
+	if o := mod.Origin; o != nil && strings.HasPrefix(o.Ref, "refs/tags/") {
+		return strings.TrimPrefix(o.Ref, "refs/tags/"), nil
+	}
+	if module.IsPseudoVersion(mod.Version) {
+		return module.PseudoVersionRev(mod.Version)
+	}
+	tag := strings.TrimSuffix(mod.Version, "+incompatible")
+	if o := mod.Origin; o != nil && o.Subdir != "" {
+		tag = o.Subdir + "/" + tag
+	}
+	return tag, nil
+}
+
+// remotePackages resolves the packages pattern of a remote module at
+// version, downloading it into the module cache, like expandPackages.
+func (c *Converter) remotePackages(pattern, version string) []string {
+	mod, err := downloadModule(pattern, version)
+	if err != nil {
+		log.Print(err)
+		return nil
+	}
+	rel := "."
+	if p := strings.TrimSuffix(pattern, "/..."); p != mod.Path {
+		rel = "./" + strings.TrimPrefix(p, mod.Path+"/")
+	}
+	if strings.HasSuffix(pattern, "/...") {
+		rel = "./" + pathpkg.Join(rel, "...")
+	}
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: mod.Dir, Env: append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")}
+	pkgs, err := packages.Load(cfg, rel)
+	if err != nil {
+		log.Printf("loading %s@%s: %v", pattern, version, err)
+		return nil
+	}
+	var paths []string
+	for _, pkg := range pkgs {
+		dir := packageDir(pkg)
+		if dir == "" || pkg.PkgPath == "" || c.excluded(pkg.PkgPath) || !strings.HasPrefix(dir, filepath.Clean(mod.Dir)) {
+			continue
+		}
+		c.pkgDirs[pkg.PkgPath] = dir
+		paths = append(paths, pkg.PkgPath)
+	}
+	return paths
+}
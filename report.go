@@ -0,0 +1,178 @@
+package godoc2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// sectionRx matches the headings of the generated documentation, with
+// the name of their anchor.
+var sectionRx = regexp.MustCompile(`^#+ .*<a name="([^"]+)">`)
+
+// linkTargetRx matches the targets of Markdown and HTML links.
+var linkTargetRx = regexp.MustCompile(`\]\([^)\s]*\)|href="[^"]*"`)
+
+// symbolSections splits generated Markdown into sections, one per heading
+// with an anchor: the symbols, and the package sections such as
+// pkg-overview. It returns the section texts keyed by anchor name, and
+// the anchor names in order of appearance.
+func symbolSections(md string) (sections map[string]string, names []string) {
+	sections = make(map[string]string)
+	name := ""
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if m := sectionRx.FindStringSubmatch(line); m != nil {
+			name = m[1]
+			if _, ok := sections[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		if name != "" {
+			sections[name] += linkTargetRx.ReplaceAllString(line, "")
+		}
+	}
+	return sections, names
+}
+
+// A Change is a change of the documentation of a symbol between two
+// outputs.
+type Change struct {
+	// Symbol is the anchor name of the symbol, such as Client.Do, or of
+	// the package section, such as pkg-overview.
+	Symbol string `json:"symbol"`
+	// Kind is "func", "method", "type" or "section".
+	Kind string `json:"kind"`
+	// Change is "added", "removed" or "changed".
+	Change string `json:"change"`
+	// Before and After are the rendered documentation of the symbol,
+	// without link targets, in the previous and the new output.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// kindRx matches the kind of symbol in the heading of its section.
+var kindRx = regexp.MustCompile(`^#+ <a name="[^"]+">(func|type)</a>`)
+
+// sectionKind returns the kind of symbol documented by the section of the
+// anchor name, see Change.
+func sectionKind(name, section string) string {
+	m := kindRx.FindStringSubmatch(section)
+	switch {
+	case m == nil:
+		return "section"
+	case m[1] == "func" && strings.Contains(name, "."):
+		return "method"
+	}
+	return m[1]
+}
+
+// Changes returns the symbols whose rendered documentation differs
+// between old and new, two outputs of the same packages: the changed and
+// added symbols in the order of new, then the removed ones in the order
+// of old. Link targets are ignored, as the source links of a file move
+// whenever the file is edited.
+func Changes(old, new []byte) []Change {
+	oldSections, oldNames := symbolSections(string(old))
+	newSections, newNames := symbolSections(string(new))
+	var changes []Change
+	for _, n := range newNames {
+		prev, ok := oldSections[n]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Symbol: n, Kind: sectionKind(n, newSections[n]), Change: "added", After: newSections[n]})
+		case prev != newSections[n]:
+			changes = append(changes, Change{Symbol: n, Kind: sectionKind(n, newSections[n]), Change: "changed", Before: prev, After: newSections[n]})
+		}
+	}
+	for _, n := range oldNames {
+		if _, ok := newSections[n]; !ok {
+			changes = append(changes, Change{Symbol: n, Kind: sectionKind(n, oldSections[n]), Change: "removed", Before: oldSections[n]})
+		}
+	}
+	return changes
+}
+
+// changeMarks are the marks of the changes in the summary of
+// ReportChanges.
+var changeMarks = map[string]string{"added": "+", "removed": "-", "changed": "~"}
+
+// ReportChanges writes to w a summary of the symbols whose rendered
+// documentation differs between old and new, the previous and the
+// regenerated content of the file name: "+" marks added symbols, "-"
+// removed ones and "~" changed ones. Nothing is written when no symbol
+// changed. See Changes.
+func ReportChanges(w io.Writer, name string, old, new []byte) error {
+	changes := Changes(old, new)
+	if len(changes) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, ch := range changes {
+		lines = append(lines, "\t"+changeMarks[ch.Change]+" "+ch.Symbol)
+	}
+	_, err := fmt.Fprintf(w, "%s:\n%s\n", name, strings.Join(lines, "\n"))
+	return err
+}
+
+// reportFormats are the functions writing the summary of changes in
+// each format of WithReportFormat.
+var reportFormats = map[string]func(w io.Writer, name string, old, new []byte) error{
+	"":       ReportChanges,
+	"text":   ReportChanges,
+	"json":   ReportChangesJSON,
+	"digest": ReportChangesDigest,
+}
+
+// digestSymbols is the number of symbols listed by file in the digests,
+// sized for chat messages.
+const digestSymbols = 10
+
+// digestList returns the first digestSymbols items, followed by the
+// number of the others.
+func digestList(items []string, sep string) string {
+	if len(items) <= digestSymbols {
+		return strings.Join(items, sep)
+	}
+	return fmt.Sprintf("%s%s… and %d more", strings.Join(items[:digestSymbols], sep), sep, len(items)-digestSymbols)
+}
+
+// ReportChangesDigest is like ReportChanges, but writes a compact digest
+// of the changes of the file name, to be posted to Slack or Discord: the
+// number of symbols added, changed and removed, followed by the first
+// symbols, in Markdown.
+func ReportChangesDigest(w io.Writer, name string, old, new []byte) error {
+	changes := Changes(old, new)
+	if len(changes) == 0 {
+		return nil
+	}
+	count := make(map[string]int)
+	var lines []string
+	for _, ch := range changes {
+		count[ch.Change]++
+		lines = append(lines, changeMarks[ch.Change]+" `"+ch.Symbol+"`")
+	}
+	var counts []string
+	for _, change := range []string{"added", "changed", "removed"} {
+		if count[change] > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count[change], change))
+		}
+	}
+	_, err := fmt.Fprintf(w, "*%s*: %s\n%s\n", name, strings.Join(counts, ", "), digestList(lines, "\n"))
+	return err
+}
+
+// ReportChangesJSON is like ReportChanges, but writes the changes of the
+// file name as a line of JSON, an object with fields "file" and
+// "changes", the list of Change, for tools such as review bots.
+func ReportChangesJSON(w io.Writer, name string, old, new []byte) error {
+	changes := Changes(old, new)
+	if len(changes) == 0 {
+		return nil
+	}
+	return json.NewEncoder(w).Encode(struct {
+		File    string   `json:"file"`
+		Changes []Change `json:"changes"`
+	}{name, changes})
+}
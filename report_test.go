@@ -0,0 +1,84 @@
+package godoc2md
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReportChanges(t *testing.T) {
+	old := "# foo\n## <a name=\"pkg-overview\">Overview</a>\nFoo.\n" +
+		"## <a name=\"A\">func</a> A\nA does.\n" +
+		"## <a name=\"B\">func</a> B\nB does.\n" +
+		"## <a name=\"C\">func</a> C\nC does.\n"
+	new := "# foo\n## <a name=\"pkg-overview\">Overview</a>\nFoo.\n" +
+		"## <a name=\"A\">func</a> A\nA does more.\n" +
+		"## <a name=\"C\">func</a> C\nC does.\n" +
+		"## <a name=\"D\">func</a> D\nD does.\n"
+	testData := []struct {
+		old, new string
+		expected string
+	}{
+		{old, old, ""},
+		{old, new, "README.md:\n\t~ A\n\t+ D\n\t- B\n"},
+	}
+	for n, tt := range testData {
+		var buf bytes.Buffer
+		if err := ReportChanges(&buf, "README.md", []byte(tt.old), []byte(tt.new)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("ReportChanges(%d): expected %q, got %q", n, tt.expected, got)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+	old := "# foo\n## <a name=\"T\">type</a> T\nT is.\n" +
+		"### <a name=\"T.M\">func</a> (T) M\nM does.\n"
+	new := "# foo\n## <a name=\"T\">type</a> T\nT is.\n" +
+		"### <a name=\"T.M\">func</a> (T) M\nM does more.\n" +
+		"## <a name=\"pkg-note-BUG\">Bugs</a>\nBug.\n"
+	expected := []Change{
+		{"T.M", "method", "changed", "### <a name=\"T.M\">func</a> (T) M\nM does.\n", "### <a name=\"T.M\">func</a> (T) M\nM does more.\n"},
+		{"pkg-note-BUG", "section", "added", "", "## <a name=\"pkg-note-BUG\">Bugs</a>\nBug.\n"},
+	}
+	if got := Changes([]byte(old), []byte(new)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Changes: expected %q, got %q", expected, got)
+	}
+}
+
+func TestRenames(t *testing.T) {
+	old := "# foo\n## <a name=\"Get\">func</a> Get\nGet gets.\n" +
+		"## <a name=\"T\">type</a> T\nT is.\n" +
+		"### <a name=\"T.M\">func</a> (T) M\nM does.\n" +
+		"## <a name=\"X\">func</a> X\nX does.\n" +
+		"## <a name=\"Y\">func</a> Y\nY does.\n"
+	new := "# foo\n## <a name=\"Fetch\">func</a> Fetch\nFetch gets.\n" +
+		"## <a name=\"U\">type</a> U\nU is.\n" +
+		"### <a name=\"U.M\">func</a> (U) M\nM does.\n" +
+		"## <a name=\"Z\">func</a> Z\nZ does.\n"
+	expected := map[string]string{"Get": "Fetch", "T": "U", "T.M": "U.M"}
+	if got := Renames([]byte(old), []byte(new)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Renames: expected %v, got %v", expected, got)
+	}
+}
+
+func TestPreviousSurfaces(t *testing.T) {
+	md := "## <a name=\"T\">type</a> T\n``` go\ntype T struct {\n    // contains filtered or unexported fields\n}\n```\n" +
+		"``` go\nconst (\n    A = 1\n    b = 2\n)\n```\n" +
+		"### <a name=\"T.M\">func</a> (\\*T) M\n<pre>func (t *<a href=\"#T\">T</a>) M() chan&lt;- int</pre>\n" +
+		"``` go\nfmt.Println(1)\n```\n"
+	expected := map[string]apiSymbol{
+		"T":   {"type", "type T struct {\n}"},
+		"A":   {"const", "const A = 1"},
+		"T.M": {"method", "func (t *T) M() chan<- int"},
+	}
+	surfaces, err := previousSurfaces([]byte(md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := surfaces[""]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("previousSurfaces: expected %q, got %q", expected, got)
+	}
+}
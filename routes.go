@@ -0,0 +1,207 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// routeMethods are the HTTP methods of the registrations of chi, gin and
+// echo routers, by the name of the registering method: Get for chi, GET
+// for gin and echo.
+var routeMethods = map[string]string{
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Delete": "DELETE", "Patch": "PATCH",
+	"Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE", "PATCH": "PATCH",
+	"HEAD": "HEAD", "OPTIONS": "OPTIONS", "CONNECT": "CONNECT", "TRACE": "TRACE",
+	"Any": "ANY",
+}
+
+// A route is an HTTP route registered by a package: its method, ANY for
+// all of them, its path, and the expression of its handler.
+type route struct {
+	method, path string
+	handler      ast.Expr
+}
+
+// stringLit returns the value of the string literal x, if it is one.
+func stringLit(x ast.Expr) (string, bool) {
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// fileRoutes returns the routes registered by the functions of file with
+// net/http, chi, gin or echo, their paths prefixed with those of the
+// gin and echo groups assigned to variables, and of the chi subrouters
+// of Route. Only the paths given as string literals are found.
+func fileRoutes(file *ast.File) []route {
+	echo := false
+	for _, imp := range file.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); strings.HasPrefix(path, "github.com/labstack/echo") {
+			echo = true
+		}
+	}
+	var routes []route
+	var walk func(n ast.Node, prefixes map[string]string)
+	walk = func(n ast.Node, prefixes map[string]string) {
+		var prefixOf func(x ast.Expr) string
+		prefixOf = func(x ast.Expr) string {
+			switch x := x.(type) {
+			case *ast.Ident:
+				return prefixes[x.Name]
+			case *ast.CallExpr:
+				if sel, ok := x.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" && len(x.Args) > 0 {
+					if s, ok := stringLit(x.Args[0]); ok {
+						return prefixOf(sel.X) + s
+					}
+				}
+			}
+			return ""
+		}
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+					if id, ok := n.Lhs[0].(*ast.Ident); ok {
+						if p := prefixOf(n.Rhs[0]); p != "" {
+							prefixes[id.Name] = p
+						}
+					}
+				}
+			case *ast.CallExpr:
+				sel, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || len(n.Args) < 2 {
+					return true
+				}
+				prefix := prefixOf(sel.X)
+				name, args := sel.Sel.Name, n.Args
+				if fn, ok := args[1].(*ast.FuncLit); ok && name == "Route" && len(fn.Type.Params.List) == 1 && len(fn.Type.Params.List[0].Names) == 1 {
+					if s, ok := stringLit(args[0]); ok {
+						sub := make(map[string]string, len(prefixes)+1)
+						for k, v := range prefixes {
+							sub[k] = v
+						}
+						sub[fn.Type.Params.List[0].Names[0].Name] = prefix + s
+						walk(fn.Body, sub)
+						return false
+					}
+				}
+				var method, path string
+				var handler ast.Expr
+				switch {
+				case routeMethods[name] != "":
+					method, handler = routeMethods[name], args[1]
+					if !echo {
+						handler = args[len(args)-1] // gin: after the middlewares
+					}
+					path, ok = stringLit(args[0])
+				case (name == "Method" || name == "MethodFunc" || name == "Handle" || name == "Add") && len(args) >= 3:
+					if method, ok = stringLit(args[0]); ok && method == strings.ToUpper(method) {
+						path, ok = stringLit(args[1])
+						handler = args[2]
+					}
+				case name == "Handle" || name == "HandleFunc":
+					// net/http patterns, since Go 1.22: [METHOD ][HOST]/[PATH]
+					method, handler = "ANY", args[1]
+					if path, ok = stringLit(args[0]); ok {
+						if i := strings.IndexByte(path, ' '); i > 0 && strings.ToUpper(path[:i]) == path[:i] {
+							method, path = path[:i], strings.TrimLeft(path[i+1:], " \t")
+						}
+					}
+				default:
+					return true
+				}
+				path = prefix + path
+				if ok && (strings.HasPrefix(path, "/") || method == "ANY" && strings.Contains(path, "/")) {
+					routes = append(routes, route{method, path, handler})
+				}
+			}
+			return true
+		})
+	}
+	for _, decl := range file.Decls {
+		if f, ok := decl.(*ast.FuncDecl); ok && f.Body != nil {
+			walk(f.Body, make(map[string]string))
+		}
+	}
+	return routes
+}
+
+// routesMdFunc returns the Routes section of the package of info, set
+// with WithRoutes: the HTTP routes it registers with net/http, chi, gin
+// or echo, sorted by path, their handlers linked to their declarations,
+// or the empty string if it registers none.
+func (c *Converter) routesMdFunc(info *godoc.PageInfo) string {
+	if !c.routes || info.PDoc == nil {
+		return ""
+	}
+	var routes []route
+	for _, file := range parseFiles(c.fs, info.Dirname) {
+		routes = append(routes, fileRoutes(file)...)
+	}
+	if len(routes) == 0 {
+		return ""
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].path != routes[j].path {
+			return routes[i].path < routes[j].path
+		}
+		return routes[i].method < routes[j].method
+	})
+
+	funcs := make(map[string]bool)
+	methods := make(map[string][]string) // types of the methods, by name
+	for _, f := range info.PDoc.Funcs {
+		funcs[f.Name] = true
+	}
+	for _, t := range info.PDoc.Types {
+		for _, f := range t.Funcs {
+			funcs[f.Name] = true
+		}
+		for _, m := range t.Methods {
+			methods[m.Name] = append(methods[m.Name], t.Name)
+		}
+	}
+	var handlerMD func(x ast.Expr) string
+	handlerMD = func(x ast.Expr) string {
+		switch x := x.(type) {
+		case *ast.Ident:
+			if funcs[x.Name] {
+				return "[" + c.mdFunc(x.Name) + "](#" + x.Name + ")"
+			}
+		case *ast.SelectorExpr:
+			if tnames := methods[x.Sel.Name]; len(tnames) == 1 {
+				name := tnames[0] + "." + x.Sel.Name
+				return "[" + c.mdFunc(name) + "](#" + name + ")"
+			}
+		case *ast.CallExpr:
+			if sel, ok := x.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "HandlerFunc" && len(x.Args) == 1 {
+				return handlerMD(x.Args[0])
+			}
+			if md := handlerMD(x.Fun); !strings.HasPrefix(md, "`") {
+				return md + "(…)"
+			}
+		case *ast.FuncLit:
+			return "func literal"
+		}
+		return "`" + strings.Replace(types.ExprString(x), "|", "\\|", -1) + "`"
+	}
+
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-routes") + c.headingFunc("section", 2) + "<a name=\"pkg-routes\">Routes</a>\n\n")
+	buf.WriteString("| Method | Path | Handler |\n| --- | --- | --- |\n")
+	for _, r := range routes {
+		buf.WriteString("| " + r.method + " | `" + strings.Replace(r.path, "|", "\\|", -1) + "` | " + handlerMD(r.handler) + " |\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
@@ -0,0 +1,79 @@
+package godoc2md
+
+import (
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestFileRoutes(t *testing.T) {
+	testData := []struct {
+		src      string
+		expected []string
+	}{
+		{`package p
+
+import "net/http"
+
+func routes(mux *http.ServeMux) {
+	mux.HandleFunc("/health", health)
+	mux.Handle("POST /items/{id}", http.HandlerFunc(createItem))
+	http.HandleFunc("example.com/", home)
+	mux.HandleFunc(pattern, dynamic)
+}
+`, []string{"ANY /health health", "POST /items/{id} http.HandlerFunc(createItem)", "ANY example.com/ home"}},
+		{`package p
+
+import "github.com/go-chi/chi/v5"
+
+func routes(r chi.Router) {
+	r.Get("/", index)
+	r.Route("/api", func(api chi.Router) {
+		api.Post("/users", s.createUser)
+		api.Route("/v2", func(v2 chi.Router) {
+			v2.Method("DELETE", "/users/{id}", deleteUser)
+		})
+	})
+	r.Get("/after", after)
+}
+`, []string{"GET / index", "POST /api/users s.createUser", "DELETE /api/v2/users/{id} deleteUser", "GET /after after"}},
+		{`package p
+
+import "github.com/gin-gonic/gin"
+
+func routes(g *gin.Engine) {
+	v1 := g.Group("/v1")
+	admin := v1.Group("/admin")
+	v1.GET("/items", auth, logging, listItems)
+	admin.Any("/debug", debug)
+	g.Handle("PATCH", "/items/:id", patchItem)
+}
+`, []string{"GET /v1/items listItems", "ANY /v1/admin/debug debug", "PATCH /items/:id patchItem"}},
+		{`package p
+
+import "github.com/labstack/echo/v4"
+
+func routes(e *echo.Echo) {
+	g := e.Group("/api")
+	g.GET("/items", listItems, auth)
+	e.Add("PUT", "/items/:id", putItem)
+	e.GET(path, dynamic)
+}
+`, []string{"GET /api/items listItems", "PUT /items/:id putItem"}},
+	}
+	for n, tt := range testData {
+		file, err := parser.ParseFile(token.NewFileSet(), "p.go", tt.src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range fileRoutes(file) {
+			got = append(got, r.method+" "+r.path+" "+types.ExprString(r.handler))
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d: fileRoutes: expected %q, got %q", n, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A schemaField is a row of the schema of a struct, see schemaMdFunc.
+type schemaField struct {
+	name, json, typ string
+	required        bool
+	doc             string
+}
+
+// requiredTag reports whether the struct tag marks its field required,
+// with the validate tag of go-playground/validator or the binding tag of
+// gin.
+func requiredTag(tag reflect.StructTag) bool {
+	for _, key := range []string{"validate", "binding"} {
+		for _, rule := range strings.Split(tag.Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaFields returns the fields of the JSON encoding of the struct st,
+// the fields of the embedded structs of the package types inlined, as
+// encoding/json does.
+func schemaFields(st *ast.StructType, types map[string]*doc.Type, seen map[string]bool) []schemaField {
+	var fields []schemaField
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			if s, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		name, opts := tag.Get("json"), ""
+		if i := strings.Index(name, ","); i >= 0 {
+			name, opts = name[:i], name[i:]
+		}
+		if name == "-" && opts == "" {
+			continue
+		}
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, token.NewFileSet(), f.Type)
+		text := ""
+		if f.Doc != nil {
+			text = f.Doc.Text()
+		} else if f.Comment != nil {
+			text = f.Comment.Text()
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		var names []string
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(f.Names) == 0 {
+			e := embeddedName(f.Type)
+			if t, ok := types[e]; ok && name == "" && !seen[e] {
+				if ts := typeSpec(t); ts != nil {
+					if est, ok := ts.Type.(*ast.StructType); ok {
+						seen[e] = true
+						fields = append(fields, schemaFields(est, types, seen)...)
+						continue
+					}
+				}
+			}
+			// the field is named after its type
+			typ := strings.TrimPrefix(buf.String(), "*")
+			names = []string{typ[strings.LastIndex(typ, ".")+1:]}
+		}
+		for _, n := range names {
+			if !ast.IsExported(n) {
+				continue
+			}
+			json := name
+			if json == "" {
+				json = n
+			}
+			fields = append(fields, schemaField{
+				name:     n,
+				json:     json + opts,
+				typ:      buf.String(),
+				required: requiredTag(tag),
+				doc:      text,
+			})
+		}
+	}
+	return fields
+}
+
+// schemaMdFunc returns the Schema subsection of the struct type t of the
+// package of info, set with WithSchemas: a table of its fields, with
+// their JSON name and options, type, whether they're required by their
+// validation tags, and description, or the empty string if t isn't a
+// struct with exported fields.
+func (c *Converter) schemaMdFunc(info *godoc.PageInfo, t *doc.Type) string {
+	if !c.schemas || info.PDoc == nil {
+		return ""
+	}
+	ts := typeSpec(t)
+	if ts == nil {
+		return ""
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	types := make(map[string]*doc.Type)
+	for _, t := range info.PDoc.Types {
+		types[t.Name] = t
+	}
+	fields := schemaFields(st, types, map[string]bool{t.Name: true})
+	if len(fields) == 0 {
+		return ""
+	}
+	cell := func(s string) string {
+		return strings.Replace(s, "|", "\\|", -1)
+	}
+	var buf strings.Builder
+	buf.WriteString("\n" + c.anchorFunc(t.Name+"-schema") + c.headingFunc("subsection", 4) + `<a name="` + t.Name + `-schema">Schema</a>` + "\n\n")
+	buf.WriteString("| Field | JSON | Type | Required | Description |\n| --- | --- | --- | --- | --- |\n")
+	for _, f := range fields {
+		required := "no"
+		if f.required {
+			required = "yes"
+		}
+		buf.WriteString("| " + c.mdFunc(f.name) + " | `" + cell(f.json) + "` | `" + cell(f.typ) + "` | " + required + " | " + cell(c.mdFunc(f.doc)) + " |\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,100 @@
+package godoc2md
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+const securityMarker = "SECURITY"
+
+// isSecurityHeading reports whether the heading text introduces
+// a security section.
+func isSecurityHeading(head string) bool {
+	head = strings.ToLower(head)
+	return head == "security" || head == "security considerations"
+}
+
+// headingAt returns the heading text if lines[i] is a section heading:
+// either a "# Heading" line or a legacy godoc heading, that is a single
+// unindented line surrounded by blank lines.
+func headingAt(lines []string, i int) string {
+	line := lines[i]
+	if indentLen(line) > 0 {
+		return ""
+	}
+	if strings.HasPrefix(line, "# ") {
+		return strings.TrimSpace(line[2:])
+	}
+	if i > 0 && !isBlank(lines[i-1]) {
+		return ""
+	}
+	if i+2 >= len(lines) || !isBlank(lines[i+1]) {
+		return ""
+	}
+	return heading(line)
+}
+
+// splitSecuritySection splits a package comment into the comment without
+// its "Security" section, and the body of that section.
+func splitSecuritySection(text string) (rest, section string) {
+	lines := strings.SplitAfter(text, "\n")
+	start, end := -1, len(lines)
+	for i := range lines {
+		head := headingAt(lines, i)
+		if head == "" {
+			continue
+		}
+		if start < 0 {
+			if isSecurityHeading(head) {
+				start = i
+			}
+			continue
+		}
+		end = i
+		break
+	}
+	if start < 0 {
+		return text, ""
+	}
+	rest = strings.Join(lines[:start], "") + strings.Join(lines[end:], "")
+	section = strings.Trim(strings.Join(lines[start+1:end], ""), "\n")
+	return rest, section
+}
+
+// overviewDocFunc returns the package comment without the section
+// rendered by securityMdFunc.
+func overviewDocFunc(text string) string {
+	rest, _ := splitSecuritySection(text)
+	return rest
+}
+
+// hasSecurityFunc reports whether the package documents security considerations.
+func hasSecurityFunc(info *godoc.PageInfo) bool {
+	if info.PDoc == nil {
+		return false
+	}
+	_, section := splitSecuritySection(info.PDoc.Doc)
+	return section != "" || len(info.PDoc.Notes[securityMarker]) > 0
+}
+
+// securityMdFunc renders the "Security" section of the package comment
+// together with the SECURITY notes of the package.
+func (c *Converter) securityMdFunc(info *godoc.PageInfo) string {
+	if !hasSecurityFunc(info) {
+		return ""
+	}
+	var buf bytes.Buffer
+	_, section := splitSecuritySection(info.PDoc.Doc)
+	if section != "" {
+		c.toMD(&buf, section+"\n")
+	}
+	if notes := info.PDoc.Notes[securityMarker]; len(notes) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(c.notesMdFunc(info, securityMarker, notes))
+	}
+	return buf.String()
+}
@@ -0,0 +1,25 @@
+package godoc2md
+
+import (
+	"testing"
+)
+
+func TestSplitSecuritySection(t *testing.T) {
+	testData := []struct {
+		doc      string
+		rest     string
+		security string
+	}{
+		{"Package foo.\n", "Package foo.\n", ""},
+		{"Package foo.\n\nSecurity\n\nDo not.\n", "Package foo.\n\n", "Do not."},
+		{"Package foo.\n\n# Security\n\nDo not.\n\n# Usage\n\nUse it.\n", "Package foo.\n\n# Usage\n\nUse it.\n", "Do not."},
+		{"Package foo.\n\nSecurity considerations\n\nDo not.\n\nUsage\n\nUse it.\n", "Package foo.\n\nUsage\n\nUse it.\n", "Do not."},
+		{"Package foo.\nSecurity\n\nis not a heading.\n", "Package foo.\nSecurity\n\nis not a heading.\n", ""},
+	}
+	for n, tt := range testData {
+		rest, security := splitSecuritySection(tt.doc)
+		if rest != tt.rest || security != tt.security {
+			t.Errorf("splitSecuritySection(%d): expected (%q, %q), got (%q, %q)", n, tt.rest, tt.security, rest, security)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package godoc2md
+
+import (
+	"fmt"
+	pathpkg "path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+)
+
+// snippetRx matches the lines marking the start and the end of the
+// regions of snippets.
+var snippetRx = regexp.MustCompile(`^\s*//\s*snippet:(start\s+(\S+)|end)\s*$`)
+
+// snippetFunc returns the code of the region of the file name of the
+// package of info, between the lines "// snippet:start region" and
+// "// snippet:end", as a fenced code block. The marker lines of nested
+// regions are left out, and the common indentation is removed.
+func (c *Converter) snippetFunc(info *godoc.PageInfo, name, region string) (string, error) {
+	src, err := vfs.ReadFile(c.fs, pathpkg.Join(info.Dirname, name))
+	if err != nil {
+		return "", fmt.Errorf("snippet: %v", err)
+	}
+	var lines []string
+	found := false
+	depth := 0 // nesting level within the region, 0 outside
+	for _, line := range strings.Split(string(src), "\n") {
+		m := snippetRx.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+			if depth > 0 {
+				lines = append(lines, strings.TrimRight(line, " \t\r"))
+			}
+			continue
+		case m[1] == "end":
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0:
+			depth++
+		case m[2] == region && !found:
+			found, depth = true, 1
+		}
+		if found && depth == 0 {
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("snippet: no region %q in %s", region, name)
+	}
+	code := strings.Trim(strings.Join(dedent(lines), "\n"), "\n")
+	lang := strings.TrimPrefix(pathpkg.Ext(name), ".")
+	return "``` " + lang + "\n" + code + "\n```\n", nil
+}
+
+// dedent removes the indentation common to the non-blank lines.
+func dedent(lines []string) []string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, prefix)
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package godoc2md
+
+import (
+	"testing"
+
+	"golang.org/x/tools/godoc"
+	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/godoc/vfs/mapfs"
+)
+
+func TestSnippet(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\t// snippet:start setup\n\tc := New()\n\t// snippet:start inner\n\tc.Run()\n\t// snippet:end\n\n\t// snippet:end\n\tc.Close()\n}\n"
+	c := Converter{fs: vfs.NameSpace{}}
+	c.fs.Bind("/", mapfs.New(map[string]string{"p/p.go": src}), "/", vfs.BindReplace)
+	info := &godoc.PageInfo{Dirname: "/p"}
+	testData := []struct {
+		region   string
+		expected string
+	}{
+		{"setup", "``` go\nc := New()\nc.Run()\n```\n"},
+		{"inner", "``` go\nc.Run()\n```\n"},
+	}
+	for _, tt := range testData {
+		got, err := c.snippetFunc(info, "p.go", tt.region)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.expected {
+			t.Errorf("snippet(%s): expected %q, got %q", tt.region, tt.expected, got)
+		}
+	}
+	if _, err := c.snippetFunc(info, "p.go", "unknown"); err == nil {
+		t.Error("snippet(unknown): expected an error")
+	}
+}
@@ -0,0 +1,264 @@
+package godoc2md
+
+import (
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// A SourceHost describes how to link to the sources of the packages
+// hosted on a code hosting platform.
+type SourceHost struct {
+	// Pattern matches the import paths of the packages of the host. Its
+	// named groups, such as host, owner, repo and dir (the directory of
+	// the package in the repository, with a leading slash), are the
+	// placeholders of Tree.
+	Pattern *regexp.Regexp
+
+	// Tree is the URL template of the directory of a package, such as
+	// "https://{host}/{owner}/{repo}/tree/{ref}{dir}", where {ref} is
+	// the branch, tag or commit linked to. The file names are appended
+	// to it, with a slash.
+	Tree string
+
+	// Line is the format of the line anchor of source links. If empty,
+	// the format set with WithHashFormat is used.
+	Line string
+
+	// Lines is the format of the range anchor of source links to
+	// declarations spanning several lines, with WithLineRanges, such as
+	// "#L%d-L%d", passed the first and the last line.
+	Lines string
+
+	// Selection is the format of the selection anchor of source links,
+	// with WithSelections, such as "#L%dC%d-L%dC%d", passed the line and
+	// the column of the first and of the last character of the
+	// declaration.
+	Selection string
+
+	// Raw is the URL template of the raw contents of the directory of a
+	// package, with the placeholders of Tree, such as
+	// "https://raw.githubusercontent.com/{owner}/{repo}/{ref}{dir}". It
+	// is linked to by assets and package files with WithRawLinks.
+	Raw string
+}
+
+// A SourceLink holds the fields of the template of WithSrcLinkFormat.
+type SourceLink struct {
+	ImportPath string // import path of the package
+	Path       string // path of the file in the godoc file system
+	File       string // name of the file, relative to the package directory
+	Line       int    // first line of the declaration
+	EndLine    int    // last line of the declaration
+	Low, High  int    // offsets of the start and the end of the declaration
+	Ref        string // branch, tag or commit set with WithRef
+	Branch     string // Ref, unless it is a commit
+	Commit     string // Ref, if it is a commit
+}
+
+// commitRx matches the abbreviated or full hashes of git commits.
+var commitRx = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// srcLinkTemplate returns the source link of the template of
+// WithSrcLinkFormat for the range [low, high) of the file s.
+func (c *Converter) srcLinkTemplate(s string, line, low, high int) string {
+	l := SourceLink{
+		Path:    s,
+		File:    path.Base(s),
+		Line:    line,
+		EndLine: c.lineAt(s, high, line),
+		Low:     low,
+		High:    high,
+		Ref:     c.ref,
+	}
+	if c.pdoc != nil {
+		l.ImportPath = c.pdoc.ImportPath
+	}
+	if commitRx.MatchString(c.ref) {
+		l.Commit = c.ref
+	} else {
+		l.Branch = c.ref
+	}
+	var buf strings.Builder
+	if err := c.srcLinkTmpl.Execute(&buf, l); err != nil {
+		log.Printf("src link format: %v", err)
+	}
+	return buf.String()
+}
+
+// sourceTemplates are the built-in templates of SourceHost.Tree,
+// by platform name.
+var sourceTemplates = map[string]SourceHost{
+	"github": {
+		Tree:      "https://{host}/{owner}/{repo}/tree/{ref}{dir}",
+		Lines:     "#L%d-L%d",
+		Selection: "#L%dC%d-L%dC%d",
+		Raw:       "https://raw.githubusercontent.com/{owner}/{repo}/{ref}{dir}",
+	},
+	"bitbucket": {
+		Tree:  "https://{host}/{owner}/{repo}/src/{ref}{dir}",
+		Lines: "#lines-%d:%d",
+		Raw:   "https://{host}/{owner}/{repo}/raw/{ref}{dir}",
+	},
+	"gitlab": {
+		Tree:  "https://{host}/{owner}/{repo}/-/blob/{ref}{dir}",
+		Lines: "#L%d-%d",
+		Raw:   "https://{host}/{owner}/{repo}/-/raw/{ref}{dir}",
+	},
+	"gitea": {
+		Tree:  "https://{host}/{owner}/{repo}/src/branch/{ref}{dir}",
+		Lines: "#L%d-L%d",
+		Raw:   "https://{host}/{owner}/{repo}/raw/branch/{ref}{dir}",
+	},
+	"sourcehut": {
+		Tree:  "https://{host}/{owner}/{repo}/tree/{ref}/item{dir}",
+		Lines: "#L%d-%d",
+		Raw:   "https://{host}/{owner}/{repo}/blob/{ref}{dir}",
+	},
+	"azure": {
+		Tree:      "https://{host}/{owner}/{project}/_git/{repo}?version=GB{ref}&path={dir}",
+		Line:      "&line=%d",
+		Lines:     "&line=%d&lineEnd=%d",
+		Selection: "&line=%d&lineStartColumn=%d&lineEnd=%d&lineEndColumn=%d&lineStyle=plain",
+		Raw:       "https://{host}/{owner}/{project}/_apis/git/repositories/{repo}/items?versionDescriptor.version={ref}&path={dir}",
+	},
+}
+
+// Patterns used to rewrite the package names to http urls. Those come from
+// https://github.com/golang/gddo/tree/master/gosrc
+var defaultSourceHosts = []SourceHost{
+	{Pattern: regexp.MustCompile(`^(?P<host>github\.com)/(?P<owner>[a-z0-9A-Z_.\-]+)/(?P<repo>[a-z0-9A-Z_.\-]+)(?P<dir>/.*)?$`), Tree: sourceTemplates["github"].Tree, Lines: sourceTemplates["github"].Lines, Selection: sourceTemplates["github"].Selection, Raw: sourceTemplates["github"].Raw},
+	{Pattern: regexp.MustCompile(`^(?P<host>bitbucket\.org)/(?P<owner>[a-z0-9A-Z_.\-]+)/(?P<repo>[a-z0-9A-Z_.\-]+)(?P<dir>/[a-z0-9A-Z_.\-/]*)?$`), Tree: sourceTemplates["bitbucket"].Tree, Lines: sourceTemplates["bitbucket"].Lines, Raw: sourceTemplates["bitbucket"].Raw},
+	NewSourceHost("gitlab.com", "gitlab"),
+	NewSourceHost("gitea.com", "gitea"),
+	NewSourceHost("codeberg.org", "gitea"),
+	NewSourceHost("git.sr.ht", "sourcehut"),
+	NewSourceHost("dev.azure.com", "azure"),
+	// all other
+	{Pattern: otherHostPattern, Tree: "https://{host}/{owner}/{repo}/src{dir}"},
+}
+
+// otherHostPattern matches the import paths of the hosts unknown to
+// defaultSourceHosts.
+var otherHostPattern = regexp.MustCompile(`^(?P<host>[a-z0-9A-Z_.\-]+\.[a-z]+)/(?P<owner>[a-z0-9A-Z_.\-]+)/(?P<repo>[a-z0-9A-Z_.\-]+)(?P<dir>/[a-z0-9A-Z_.\-/]*)?$`)
+
+// stdlibPattern matches the import paths of the standard library, whose
+// first element has no dot.
+var stdlibPattern = regexp.MustCompile(`^(?P<pkg>[^./]+(/.*)?)$`)
+
+// stdlibHosts are the sites linked to by the source links of the
+// standard library, by the name given to WithStdlibLinks.
+var stdlibHosts = map[string]SourceHost{
+	"golang.org": {},
+	"go.dev":     {Pattern: stdlibPattern, Tree: "https://go.dev/src/{pkg}", Line: "#L%d"},
+	"cs":         {Pattern: stdlibPattern, Tree: "https://cs.opensource.google/go/go/+/{ref}:src/{pkg}", Line: ";l=%d"},
+}
+
+// NewSourceHost returns the SourceHost of the import paths of the form
+// host/owner/repo/dir. The tree is either a URL template, see
+// SourceHost.Tree, or the name of a built-in one: github, bitbucket,
+// gitlab, gitea, sourcehut or azure.
+func NewSourceHost(host, tree string) SourceHost {
+	h := SourceHost{Tree: tree}
+	if t, ok := sourceTemplates[tree]; ok {
+		h = t
+	}
+	path := `/(?P<owner>[^/]+)/(?P<repo>[^/]+)(?P<dir>/.*)?$`
+	if tree == "azure" {
+		path = `/(?P<owner>[^/]+)/(?P<project>[^/]+)/_git/(?P<repo>[^/.]+)(\.git)?(?P<dir>/.*)?$`
+	}
+	h.Pattern = regexp.MustCompile(`^(?P<host>` + regexp.QuoteMeta(host) + `)` + path)
+	return h
+}
+
+// match returns the expansion of the tree template of h for the import
+// path src and ref, if h hosts src.
+func (h SourceHost) match(src, ref string) (string, bool) {
+	return h.expand(h.Tree, src, ref)
+}
+
+// expand returns the expansion of the URL template tmpl of h for the
+// import path src and ref, if h hosts src.
+func (h SourceHost) expand(tmpl, src, ref string) (string, bool) {
+	m := h.Pattern.FindStringSubmatch(src)
+	if m == nil {
+		return "", false
+	}
+	oldnew := []string{"{ref}", ref}
+	for i, name := range h.Pattern.SubexpNames() {
+		if name != "" {
+			oldnew = append(oldnew, "{"+name+"}", m[i])
+		}
+	}
+	return strings.NewReplacer(oldnew...).Replace(tmpl), true
+}
+
+// sourceHost returns the first of hosts hosting the import path src,
+// with the URL of its directory at ref.
+func sourceHost(hosts []SourceHost, src, ref string) (SourceHost, string, bool) {
+	// the source for golang.org/x is on github
+	src = strings.Replace(src, "golang.org/x", "github.com/golang", -1)
+	for _, h := range hosts {
+		if url, ok := h.match(src, ref); ok {
+			return h, url, true
+		}
+	}
+	return SourceHost{}, "", false
+}
+
+// rawURL returns the URL of the raw contents of the directory of the
+// package of import path src, if its host has a raw template.
+func (c *Converter) rawURL(src string) (string, bool) {
+	src = c.hostedPath(src)
+	h, _, ok := sourceHost(c.sourceHosts, src, c.ref)
+	if !ok || h.Raw == "" {
+		return "", false
+	}
+	src = strings.Replace(src, "golang.org/x", "github.com/golang", -1)
+	return h.expand(h.Raw, src, c.ref)
+}
+
+// fileLinkFunc returns the link to the file name of the package of
+// import path src: its raw contents with WithRawLinks, if the host has a
+// raw template, or its page on the host.
+func (c *Converter) fileLinkFunc(src, name string) string {
+	if c.rawLinks {
+		if url, ok := c.rawURL(src); ok {
+			return url + "/" + name
+		}
+	}
+	return c.urlFromPackage(src) + "/" + name
+}
+
+// localURL returns the link to the package directory dir on disk, set
+// with WithLocalLinks: relative to the directory of the output, such as
+// "." or "../client", or a file:// URL.
+func (c *Converter) localURL(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	if c.localLinks == "file" {
+		abs = filepath.ToSlash(abs)
+		if !strings.HasPrefix(abs, "/") {
+			// a Windows drive
+			abs = "/" + abs
+		}
+		return "file://" + abs
+	}
+	out, err := filepath.Abs(c.outputDir)
+	if err != nil {
+		return abs
+	}
+	rel, err := filepath.Rel(out, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return "./" + rel
+}
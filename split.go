@@ -0,0 +1,121 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ConvertSplit writes the Markdown documentation of the package
+// importPath into the file name, except for its exported types, which
+// are written with their constructors, methods and examples into their
+// own file next to it, named after the type, such as Client.md. The file
+// name links to the files of the types.
+func (c *Converter) ConvertSplit(importPath, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var index bytes.Buffer
+	info, err := c.pageInfo(&index, importPath)
+	if err != nil {
+		return err
+	}
+	c.regroup(info)
+	dir := filepath.Dir(name)
+	c.outputDir = dir
+
+	pdoc := *info.PDoc
+	var types, others []*doc.Type
+	for _, t := range pdoc.Types {
+		if ast.IsExported(t.Name) {
+			types = append(types, t)
+		} else {
+			others = append(others, t)
+		}
+	}
+	c.splitTypes, c.splitIndex = types, filepath.Base(name)
+	defer func() { c.splitTypes, c.splitIndex = nil, "" }()
+	c.recordSymbols(info, name, c.layout)
+
+	var examples []*doc.Example
+	for _, eg := range info.Examples {
+		if exampleType(types, c.optionFuncs, eg) == nil {
+			examples = append(examples, eg)
+		}
+	}
+
+	for _, t := range types {
+		tdoc := pdoc
+		tdoc.Consts, tdoc.Vars, tdoc.Funcs, tdoc.Types, tdoc.Notes = nil, nil, nil, []*doc.Type{t}, nil
+		tinfo := *info
+		tinfo.PDoc, tinfo.Notes, tinfo.Examples = &tdoc, nil, nil
+		for _, eg := range info.Examples {
+			if exampleType(types, c.optionFuncs, eg) == t {
+				tinfo.Examples = append(tinfo.Examples, eg)
+			}
+		}
+		var buf bytes.Buffer
+		if err := c.execute(&buf, c.typeTmpl, &tinfo); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, typeFile(t)), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		c.recordOutput(filepath.Join(dir, typeFile(t)))
+	}
+
+	pdoc.Types = others
+	info.PDoc, info.Examples = &pdoc, examples
+	if err := c.render(&index, c.tmpl, info); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(name, index.Bytes(), 0644); err != nil {
+		return err
+	}
+	c.recordOutput(name)
+	return c.copyAssets(info.Dirname, dir)
+}
+
+// exampleType returns the type of types that the example eg is about,
+// directly or through one of its constructors, options or methods, or nil.
+func exampleType(types []*doc.Type, options map[string][]*doc.Func, eg *doc.Example) *doc.Type {
+	name := strings.SplitN(stripExampleSuffix(eg.Name), "_", 2)[0]
+	for _, t := range types {
+		if t.Name == name {
+			return t
+		}
+		for _, f := range append(t.Funcs, options[t.Name]...) {
+			if f.Name == name {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// typeFile returns the name of the file documenting the type t.
+func typeFile(t *doc.Type) string {
+	return t.Name + ".md"
+}
+
+// typesIndexMdFunc returns the section of the index page linking to the
+// pages of the types, written by ConvertSplit.
+func (c *Converter) typesIndexMdFunc() string {
+	if len(c.splitTypes) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(c.anchorFunc("pkg-types"))
+	buf.WriteString(c.headingFunc("section", 2) + "<a name=\"pkg-types\">Types</a>\n\n")
+	for _, t := range c.splitTypes {
+		buf.WriteString("* [" + t.Name + "](" + typeFile(t) + ")")
+		if synopsis := c.synopsisMdFunc(t.Doc); synopsis != "" {
+			buf.WriteString(": " + synopsis)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
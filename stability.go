@@ -0,0 +1,51 @@
+package godoc2md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// stabilityRx matches the "Stability: level" annotation line
+// in a doc comment.
+var stabilityRx = regexp.MustCompile(`(?m)^Stability:[ \t]*([A-Za-z-]+)\.?[ \t]*\n?`)
+
+// stabilityColors maps well-known stability levels to badge colors.
+var stabilityColors = map[string]string{
+	"experimental": "orange",
+	"alpha":        "red",
+	"beta":         "yellow",
+	"stable":       "brightgreen",
+	"frozen":       "blue",
+	"deprecated":   "lightgrey",
+}
+
+// stabilityFunc returns the stability level annotated in the
+// doc comment, or the empty string.
+func stabilityFunc(doc string) string {
+	m := stabilityRx.FindStringSubmatch(doc)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1])
+}
+
+// stripStability removes the stability annotation from a doc comment.
+func stripStability(doc string) string {
+	return stabilityRx.ReplaceAllString(doc, "")
+}
+
+// stabilityBadgeFunc returns a badge showing the stability level
+// annotated in the doc comment, followed by a blank line.
+func stabilityBadgeFunc(doc string) string {
+	level := stabilityFunc(doc)
+	if level == "" {
+		return ""
+	}
+	color, ok := stabilityColors[level]
+	if !ok {
+		color = "informational"
+	}
+	badge := strings.Replace(level, "-", "--", -1)
+	return fmt.Sprintf("![Stability: %s](https://img.shields.io/badge/stability-%s-%s.svg)\n\n", level, badge, color)
+}
@@ -0,0 +1,111 @@
+package godoc2md
+
+import (
+	"reflect"
+	"strconv"
+	"text/template"
+	"text/template/parse"
+)
+
+// strictValueFunc is the name of the template function checking the
+// values printed by the actions of strict templates, see strictTemplate.
+const strictValueFunc = "strict_value"
+
+// A noValueError is the error of a strict template printing a missing
+// value, at the location and action given by action, such as
+// "package.txt:12:4: {{.Doc}}".
+type noValueError struct {
+	action string
+}
+
+func (e *noValueError) Error() string {
+	return "template: " + e.action + " prints <no value>"
+}
+
+// strictValue returns v, failing if it's nil: the missing value, such as
+// the field of a nil interface, that the action would print as
+// "<no value>".
+func strictValue(action string, v interface{}) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Value{}, &noValueError{action}
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// textEscapers are the functions printing their arguments escaped, which
+// would print a missing value as an escaped "<no value>".
+var textEscapers = map[string]bool{"html": true, "js": true, "urlquery": true, "print": true, "println": true}
+
+// strictTemplate rewrites the actions of the templates of t printing a
+// value to check it with strictValue first, so that a missing value fails
+// the execution at the action, rather than printing "<no value>".
+func strictTemplate(t *template.Template) {
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil && tt.Tree.Root != nil {
+			strictNode(tt.Tree, tt.Tree.Root)
+		}
+	}
+}
+
+// strictNode rewrites the actions of the node n of tree, see
+// strictTemplate.
+func strictNode(tree *parse.Tree, n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, n := range n.Nodes {
+			strictNode(tree, n)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			location, _ := tree.ErrorContext(n)
+			strictPipe(tree, n.Pipe, location+": "+n.String())
+		}
+	case *parse.IfNode:
+		strictNode(tree, n.List)
+		strictNode(tree, n.ElseList)
+	case *parse.RangeNode:
+		strictNode(tree, n.List)
+		strictNode(tree, n.ElseList)
+	case *parse.WithNode:
+		strictNode(tree, n.List)
+		strictNode(tree, n.ElseList)
+	}
+}
+
+// strictPipe rewrites the pipeline pipe of the action to check the value
+// it prints: before the escapers ending the pipeline, such as html, and
+// in their arguments.
+func strictPipe(tree *parse.Tree, pipe *parse.PipeNode, action string) {
+	check := func(args ...parse.Node) *parse.CommandNode {
+		ident := parse.NewIdentifier(strictValueFunc).SetTree(tree).SetPos(pipe.Pos)
+		quoted := &parse.StringNode{NodeType: parse.NodeString, Pos: pipe.Pos, Quoted: strconv.Quote(action), Text: action}
+		return &parse.CommandNode{NodeType: parse.NodeCommand, Pos: pipe.Pos, Args: append([]parse.Node{ident, quoted}, args...)}
+	}
+	i := len(pipe.Cmds)
+	for i > 0 && isTextEscaper(pipe.Cmds[i-1]) {
+		i--
+	}
+	for _, cmd := range pipe.Cmds[i:] {
+		for j, arg := range cmd.Args[1:] {
+			switch arg.(type) {
+			case *parse.FieldNode, *parse.ChainNode, *parse.VariableNode, *parse.DotNode, *parse.PipeNode:
+				cmd.Args[j+1] = &parse.PipeNode{NodeType: parse.NodePipe, Pos: pipe.Pos, Cmds: []*parse.CommandNode{check(arg)}}
+			}
+		}
+	}
+	if i > 0 {
+		cmds := append([]*parse.CommandNode{}, pipe.Cmds[:i]...)
+		cmds = append(cmds, check())
+		pipe.Cmds = append(cmds, pipe.Cmds[i:]...)
+	}
+}
+
+// isTextEscaper reports whether the command cmd calls one of the
+// textEscapers.
+func isTextEscaper(cmd *parse.CommandNode) bool {
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	return ok && textEscapers[ident.Ident]
+}
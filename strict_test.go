@@ -0,0 +1,49 @@
+package godoc2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStrictTemplates(t *testing.T) {
+	data := struct {
+		S   string
+		E   error
+		P   *int
+		M   map[string]string
+		Doc string
+	}{S: "s", M: map[string]string{"k": "v"}}
+	testData := []struct {
+		text     string
+		expected string // the output, or the error
+	}{
+		{"{{.S}} {{.M.k}} {{.P}}", "s v <nil>"},
+		{"a literal <no value>, {{.S}}", "a literal <no value>, s"},
+		{"{{$e := .E}}{{if $e}}{{$e}}{{end}}ok", "ok"},
+		{"line\n  {{.E}}", `template: package.txt:2:4: {{.E}} prints <no value>`},
+		{"{{.E | html}}", `template: package.txt:1:2: {{.E | html}} prints <no value>`},
+		{"{{html .S .E}}", `template: package.txt:1:2: {{html .S .E}} prints <no value>`},
+		{"{{.M.missing}}", `template: package.txt:1:4: executing "package.txt" at <.M.missing>: map has no entry for key "missing"`},
+		{"{{define \"part\"}}{{with .S}}\n{{$.E}}{{end}}{{end}}{{template \"part\" .}}", `template: package.txt:2:2: {{$.E}} prints <no value>`},
+	}
+	c, err := New(WithStrictTemplates(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range testData {
+		tmpl, err := c.readTemplate("package.txt", tt.text)
+		if err != nil {
+			t.Fatalf("readTemplate(%q): %v", tt.text, err)
+		}
+		var buf bytes.Buffer
+		got := ""
+		if err := c.execute(&buf, tmpl, data); err != nil {
+			got = err.Error()
+		} else {
+			got = buf.String()
+		}
+		if got != tt.expected {
+			t.Errorf("execute(%q): expected %q, got %q", tt.text, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package godoc2md
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A subdir is an entry of the Subdirectories section of a package.
+type subdir struct {
+	Name     string
+	Link     string
+	Synopsis string
+}
+
+// subdirsFunc returns the child directories of the package of info that
+// hold packages, as listed by godoc, linked to their documentation: the
+// file written by ConvertTree if they're part of the tree, their page
+// at the package link base if the package has an import path, or their
+// directory otherwise.
+func (c *Converter) subdirsFunc(info *godoc.PageInfo) []subdir {
+	if info.Dirs == nil {
+		return nil
+	}
+	var importPath string
+	if info.PDoc != nil && !isLocalPattern(info.PDoc.ImportPath) {
+		importPath = info.PDoc.ImportPath
+	}
+	var dirs []subdir
+	for _, e := range info.Dirs.List {
+		if !e.HasPkg || e.Depth != 0 {
+			continue
+		}
+		d := subdir{Name: e.Path, Link: e.Path + "/", Synopsis: e.Synopsis}
+		if importPath == "" {
+			dirs = append(dirs, d)
+			continue
+		}
+		child := c.childPath(importPath, e.Path)
+		if name, ok := c.treeOutputs[child]; ok {
+			if rel, err := filepath.Rel(filepath.Dir(c.treeOutputs[importPath]), name); err == nil {
+				d.Link = filepath.ToSlash(rel)
+			}
+		} else {
+			d.Link = strings.TrimSuffix(c.pkgLinkBase, "/") + "/" + child
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// childPath returns the import path of the package in the directory rel
+// below the package importPath, looked up among the packages resolved
+// with the go command, as it differs for nested modules.
+func (c *Converter) childPath(importPath, rel string) string {
+	if dir, ok := c.pkgDirs[importPath]; ok {
+		dir = filepath.Join(dir, filepath.FromSlash(rel))
+		for path, d := range c.pkgDirs {
+			if d == dir {
+				return path
+			}
+		}
+	}
+	return importPath + "/" + rel
+}
@@ -0,0 +1,111 @@
+package godoc2md
+
+import (
+	"encoding/json"
+	"go/doc"
+	"io/ioutil"
+	"path/filepath"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A Symbol locates the documentation of a symbol in the output, as
+// written by WriteSymbols for editor plugins to link code to it.
+type Symbol struct {
+	ImportPath string `json:"importPath"`
+	Name       string `json:"name"` // such as "Client" or "Client.Do"
+	Kind       string `json:"kind"` // const, var, func, type or method
+	File       string `json:"file"` // relative to the symbols file
+	Anchor     string `json:"anchor"`
+}
+
+// recordSymbols records the symbols of info, written to the file name
+// with the layout layout, when enabled with WithSymbols. The types are
+// in their own file when they're split, see ConvertSplit.
+func (c *Converter) recordSymbols(info *godoc.PageInfo, name, layout string) {
+	if c.symbolsFile == "" || info.PDoc == nil {
+		return
+	}
+	file := func(name string) string {
+		if rel, err := filepath.Rel(filepath.Dir(c.symbolsFile), name); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return filepath.ToSlash(name)
+	}
+	c.eachSymbol(info.PDoc, func(s Symbol, t *doc.Type, _ string) {
+		s.File = file(name)
+		if t != nil && c.isSplit(t) {
+			s.File = file(filepath.Join(filepath.Dir(name), typeFile(t)))
+		}
+		if layout == "summary" && t != nil && (s.Kind == "const" || s.Kind == "var") {
+			s.Anchor = t.Name // the row of their type
+		}
+		c.symbols = append(c.symbols, s)
+	})
+}
+
+// eachSymbol calls fn with the symbols of pdoc, in the order of the
+// flat layout, the type they're documented with, if any, and their doc
+// comment. The file of the symbols is left empty.
+func (c *Converter) eachSymbol(pdoc *doc.Package, fn func(s Symbol, t *doc.Type, doc string)) {
+	add := func(name, kind, anchor string, t *doc.Type, doc string) {
+		fn(Symbol{ImportPath: pdoc.ImportPath, Name: name, Kind: kind, Anchor: anchor}, t, doc)
+	}
+	values := func(vs []*doc.Value, kind, anchor string, t *doc.Type) {
+		for _, v := range vs {
+			for _, n := range v.Names {
+				add(n, kind, anchor, t, v.Doc)
+			}
+		}
+	}
+	values(pdoc.Consts, "const", "pkg-constants", nil)
+	values(pdoc.Vars, "var", "pkg-variables", nil)
+	for _, f := range pdoc.Funcs {
+		add(f.Name, "func", f.Name, nil, f.Doc)
+	}
+	for _, t := range pdoc.Types {
+		add(t.Name, "type", t.Name, t, t.Doc)
+		constAnchor := t.Name
+		if c.constGroups {
+			constAnchor = t.Name + "-constants"
+		}
+		values(t.Consts, "const", constAnchor, t)
+		values(t.Vars, "var", t.Name, t)
+		for _, f := range append(t.Funcs, c.optionFuncs[t.Name]...) {
+			add(f.Name, "func", f.Name, t, f.Doc)
+		}
+		for _, m := range t.Methods {
+			add(t.Name+"."+m.Name, "method", t.Name+"."+m.Name, t, m.Doc)
+		}
+	}
+}
+
+// isSplit reports whether the type t is written to its own file by
+// ConvertSplit.
+func (c *Converter) isSplit(t *doc.Type) bool {
+	for _, st := range c.splitTypes {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteSymbols writes the symbols of the packages converted since New
+// to the JSON file set with WithSymbols, if any.
+func (c *Converter) WriteSymbols() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.symbolsFile == "" {
+		return nil
+	}
+	symbols := c.symbols
+	if symbols == nil {
+		symbols = []Symbol{}
+	}
+	data, err := json.MarshalIndent(symbols, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.symbolsFile, append(data, '\n'), 0644)
+}
@@ -0,0 +1,65 @@
+package godoc2md
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// A Target is an output of ConvertTargets: the file Path, written with
+// the built-in layout Layout, see WithLayout.
+type Target struct {
+	Layout string
+	Path   string
+}
+
+// ConvertTargets writes the Markdown documentation of the package
+// importPath to several files at once, such as a summary README.md and
+// a full reference, loading the package only once. The assets of the
+// package are copied next to every file.
+func (c *Converter) ConvertTargets(importPath string, targets ...Target) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tmpls := make(map[string]*template.Template)
+	for _, t := range targets {
+		if _, ok := tmpls[t.Layout]; ok {
+			continue
+		}
+		text, err := layoutTemplate(t.Layout)
+		if err != nil {
+			return err
+		}
+		if tmpls[t.Layout], err = c.readTemplate(t.Layout+".txt", text); err != nil {
+			return err
+		}
+	}
+
+	var hints bytes.Buffer
+	info, err := c.pageInfo(&hints, importPath)
+	if err != nil {
+		return err
+	}
+	c.regroup(info)
+	for _, t := range targets {
+		buf := bytes.NewBuffer(append([]byte(nil), hints.Bytes()...))
+		dir := filepath.Dir(t.Path)
+		c.outputDir = dir
+		if err := c.render(buf, tmpls[t.Layout], info); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(t.Path, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		c.recordOutput(t.Path)
+		c.recordSymbols(info, t.Path, t.Layout)
+		if err := c.copyAssets(info.Dirname, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,86 +1,299 @@
-package main
+package godoc2md
 
-var pkgTemplate = `{{with .PDoc}}
+import "fmt"
+
+// layouts are the built-in package templates, by name: pkgTemplate is
+// the flat layout.
+var layouts = map[string]string{
+	"flat":    pkgTemplate,
+	"pkgsite": pkgHeaderTemplate + pkgsiteSymbolsTemplate + pkgFooterTemplate,
+	"summary": summaryHeaderTemplate + summarySymbolsTemplate + pkgFooterTemplate,
+}
+
+// layoutTemplate returns the built-in template of the named layout.
+func layoutTemplate(name string) (string, error) {
+	text, ok := layouts[name]
+	if !ok {
+		return "", fmt.Errorf("unknown layout %q, want flat, pkgsite or summary", name)
+	}
+	return text, nil
+}
+
+var pkgTemplate = pkgHeaderTemplate + pkgSymbolsTemplate + pkgFooterTemplate
+
+// pkgHeaderTemplate renders the title, overview, index and type diagram
+// of a package, or the documentation of a command.
+var pkgHeaderTemplate = `{{example_tabs_header}}{{with .PDoc}}
 {{if $.IsMain}}
-> {{ base .ImportPath }}
-{{comment_md .Doc}}
+> {{package_title $}}
+{{overview_md $}}{{comment_md .Doc}}
 {{else}}
-# {{ .Name }}
-` + "`" + `import "{{.ImportPath}}"` + "`" + `
+{{h "title" 1}}{{package_title $}}
+` + "`" + `{{import_stmt .}}` + "`" + `
 
-* [Overview](#pkg-overview)
-* [Index](#pkg-index){{if and $.Examples show_examples}}
-* [Examples](#pkg-examples){{- end}}{{if $.Dirs}}
+{{badges_md $}}{{whats_new_md $}}{{stability_badge .Doc}}* [Overview](#pkg-overview){{if has_security $}}
+* [Security considerations](#pkg-security){{- end}}
+* [Index](#pkg-index){{toc_md $}}{{if and $.Examples show_examples}}
+* [Examples](#pkg-examples){{- end}}{{if has_deprecated $}}
+* [Deprecated APIs](#pkg-deprecated){{- end}}{{if subdirs $}}
 * [Subdirectories](#pkg-subdirectories){{- end}}
-
-## <a name="pkg-overview">Overview</a>
-{{comment_md .Doc}}
+{{if has_security $}}
+{{anchor "pkg-security"}}{{h "section" 2}}<a name="pkg-security">Security considerations</a>
+{{security_md $}}
+{{end}}
+{{anchor "pkg-overview"}}{{h "section" 2}}<a name="pkg-overview">Overview</a>
+{{overview_md $}}{{comment_md (overview_doc .Doc)}}
 {{example_md $ ""}}
 
-## <a name="pkg-index">Index</a>{{if .Consts}}
+{{anchor "pkg-index"}}{{h "section" 2}}<a name="pkg-index">Index</a>{{if .Consts}}
 * [Constants](#pkg-constants){{end}}{{if .Vars}}
-* [Variables](#pkg-variables){{end}}{{- range .Funcs -}}{{$name_html := html .Name}}
-* [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range .Types}}{{$tname_html := html .Name}}
-* [type {{$tname_html}}](#{{$tname_html}}){{- range .Funcs}}{{$name_html := html .Name}}
+* [Variables](#pkg-variables){{end}}{{- range section_funcs $ "" -}}{{$name_html := html .Name}}
+* [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range section_types $ ""}}{{$tname_html := html .Name}}
+* [type {{$tname_html}}](#{{$tname_html}}){{- if and .Consts const_groups}}
+  * [Constants](#{{$tname_html}}-constants){{- end}}{{- range .Funcs}}{{$name_html := html .Name}}
+  * [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range options_for .Name}}{{$name_html := html .Name}}
   * [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range .Methods}}{{$name_html := html .Name}}
-  * [{{node_html $ .Decl false | sanitize}}](#{{$tname_html}}.{{$name_html}}){{- end}}{{- end}}{{- if $.Notes}}{{- range $marker, $item := $.Notes}}
-* [{{noteTitle $marker | html}}s](#pkg-note-{{$marker}}){{end}}{{end}}
+  * [{{node_html $ .Decl false | sanitize}}](#{{$tname_html}}.{{$name_html}}){{- end}}{{- range accessors_for .Name}}{{$name_html := html .Name}}
+  * [{{node_html $ .Decl false | sanitize}}](#{{$tname_html}}.{{$name_html}}){{- end}}{{- end}}{{- range $section := decl_sections}}
+* [{{html $section}}](#{{section_id $section}}){{- range section_funcs $ $section}}{{$name_html := html .Name}}
+  * [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range section_types $ $section}}{{$tname_html := html .Name}}
+  * [type {{$tname_html}}](#{{$tname_html}}){{- range .Funcs}}{{$name_html := html .Name}}
+    * [{{node_html $ .Decl false | sanitize}}](#{{$name_html}}){{- end}}{{- range .Methods}}{{$name_html := html .Name}}
+    * [{{node_html $ .Decl false | sanitize}}](#{{$tname_html}}.{{$name_html}}){{- end}}{{- end}}{{- end}}{{- if $.Notes}}{{- range $marker, $item := $.Notes}}
+* [{{note_title $marker | html}}](#pkg-note-{{$marker}}){{end}}{{end}}
 {{if and $.Examples show_examples}}
-#### <a name="pkg-examples">Examples</a>{{- range $.Examples}}
-* [{{example_name .Name}}](#example-{{example_link .Name}}){{- end}}{{- end}}
+{{anchor "pkg-examples"}}{{h "subsection" 4}}<a name="pkg-examples">Examples</a>{{- range $.Examples}}
+* [{{example_name .Name}}](#{{example_id $ .}}){{- end}}{{- end}}
 {{with .Filenames}}
-#### <a name="pkg-files">Package files</a>
-{{range $i, $f := .}}{{ if $i }} {{ end }}[{{$f|filename|html}}]({{.|srcLink|html}}){{end}}
-{{end}}
+{{anchor "pkg-files"}}{{h "subsection" 4}}<a name="pkg-files">Package files</a>
+{{range $i, $f := .}}{{ if $i }} {{ end }}[{{$f|filename|html}}]({{file_link $.PDoc.ImportPath ($f|filename)|html}}){{end}}
+{{end}}{{type_diagram_md $}}{{grpc_services_md $}}
 
-{{with .Consts}}## <a name="pkg-constants">Constants</a>
+`
+
+// pkgSymbolsTemplate renders the symbols of a package in declaration
+// order, the constructors and methods of types following the types.
+var pkgSymbolsTemplate = `{{with .Consts}}{{anchor "pkg-constants"}}{{h "section" 2}}<a name="pkg-constants">Constants</a>
 {{range .}}{{node $ .Decl | pre}}
-{{comment_md .Doc}}{{end}}{{end}}
-{{with .Vars}}## <a name="pkg-variables">Variables</a>
+{{const_values_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{end}}
+{{with .Vars}}{{anchor "pkg-variables"}}{{h "section" 2}}<a name="pkg-variables">Variables</a>
 {{range .}}{{node $ .Decl | pre}}
-{{comment_md .Doc}}{{end}}{{end}}
+{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{end}}
 
-{{range .Funcs}}{{$name_html := html .Name}}## <a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}})
-{{node $ .Decl | pre}}
-{{comment_md .Doc}}
+{{range section_funcs $ ""}}` + pkgFuncTemplate + `{{end}}
+{{types_index_md}}{{range section_types $ ""}}` + pkgTypeTemplate + `{{end}}` + pkgSectionsTemplate + `{{other_examples_md $}}`
+
+// pkgFuncTemplate renders a function of a package.
+var pkgFuncTemplate = `{{$name_html := html .Name}}{{anchor $name_html}}{{h "symbol" 2}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
 {{example_md $ .Name}}
-{{callgraph_html $ "" .Name}}{{end}}
-{{range .Types}}{{$tname := .Name}}{{$tname_html := html .Name}}## <a name="{{$tname_html}}">type</a> [{{$tname_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}})
-{{node $ .Decl | pre}}
-{{comment_md .Doc}}{{range .Consts}}
+{{callgraph_html $ "" .Name}}`
+
+// pkgSectionsTemplate renders the functions and types moved to sections
+// by their section directives, see declDirectives.
+var pkgSectionsTemplate = `{{range $section := decl_sections}}{{anchor (section_id $section)}}{{h "section" 2}}<a name="{{section_id $section}}">{{html $section}}</a>
+{{range section_funcs $ $section}}` + pkgFuncTemplate + `{{end}}{{range section_types $ $section}}` + pkgTypeTemplate + `{{end}}{{end}}`
+
+// pkgTypeTemplate renders a type, its constructors and its methods.
+var pkgTypeTemplate = `{{$tname := .Name}}{{$tname_html := html .Name}}{{anchor $tname_html}}{{h "symbol" 2}}<a name="{{$tname_html}}">type</a> [{{$tname_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{stability_badge .Doc}}{{concurrency_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{schema_md $ .}}{{if and .Consts const_groups}}
+{{anchor (printf "%s-constants" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-constants">Constants</a>
+{{end}}{{range .Consts}}
 {{node $ .Decl | pre }}
-{{comment_md .Doc}}{{end}}{{range .Vars}}
+{{const_values_md $ .Decl}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{range .Vars}}
 {{node $ .Decl | pre }}
-{{comment_md .Doc}}{{end}}
+{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}
 
 {{example_md $ $tname}}
-{{implements_html $ $tname}}
+{{implements_html $ $tname}}{{implementations_md $ $tname}}
 {{methodset_html $ $tname}}
 
-{{range .Funcs}}{{$name_html := html .Name}}### <a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}})
-{{node $ .Decl | pre}}
-{{comment_md .Doc}}
+{{range .Funcs}}{{$name_html := html .Name}}{{anchor $name_html}}{{h "member" 3}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
 {{example_md $ .Name}}{{end}}
 {{callgraph_html $ "" .Name}}
-
-{{range .Methods}}{{$name_html := html .Name}}### <a name="{{$tname_html}}.{{$name_html}}">func</a> ({{md .Recv}}) [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}})
-{{node $ .Decl | pre}}
-{{comment_md .Doc}}
+{{with options_for $tname}}{{anchor (printf "%s-options" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-options">Options</a>
+{{range .}}{{$name_html := html .Name}}{{anchor $name_html}}{{h "member" 5}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
+{{example_md $ .Name}}{{end}}
+{{end}}
+{{range .Methods}}{{$name_html := html .Name}}{{anchor (printf "%s.%s" $tname_html $name_html)}}{{h "member" 3}}<a name="{{$tname_html}}.{{$name_html}}">func</a> ({{md .Recv}}) [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ (printf "%s.%s" $tname .Name)}}
+{{permalinks_md $ .Decl (printf "%s.%s" $tname .Name)}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
 {{$name := printf "%s_%s" $tname .Name}}{{example_md $ $name}}
 {{callgraph_html $ .Recv .Name}}
-{{end}}{{end}}{{end}}
+{{end}}{{with accessors_for $tname}}
+{{anchor (printf "%s-accessors" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-accessors">Accessors</a>
+| Method | Signature | Description |
+| --- | --- | --- |
+{{range .}}{{accessor_row $ $tname .}}
+{{end}}
+{{end}}`
+
+// typePageTemplate renders a type of a package on its own page, linking
+// back to the index page of the package, see ConvertSplit.
+var typePageTemplate = `{{example_tabs_header}}{{with .PDoc}}[{{.Name}}]({{split_index}})
+
+{{range .Types}}` + pkgTypeTemplate + `{{end}}{{end}}{{other_examples_md $}}
+- - -
+Generated by [godoc2md](http://godoc.org/github.com/davecheney/godoc2md)
+`
 
+// pkgFooterTemplate renders the deprecated symbols, the data model, the
+// HTTP routes, the metrics, the configuration keys, the benchmarks and
+// tests, the subdirectories and the notes of a package.
+var pkgFooterTemplate = `{{deprecated_md $}}{{data_model_md $}}{{routes_md $}}{{observability_md $}}{{configuration_md $}}{{test_funcs_md $}}{{end}}
+{{with subdirs $}}{{anchor "pkg-subdirectories"}}{{h "section" 2}}<a name="pkg-subdirectories">Subdirectories</a>
+
+| Name | Synopsis |
+| --- | --- |
+{{range .}}| [{{html .Name}}]({{html .Link}}) | {{synopsis_md .Synopsis}} |
+{{end}}
+{{end}}
 {{with $.Notes}}
 {{range $marker, $content := .}}
-## <a name="pkg-note-{{$marker}}">{{noteTitle $marker | html}}s
-<ul style="list-style: none; padding: 0;">
-{{range .}}
-<li><a href="{{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .}}">&#x261e;</a> {{html .Body}}</li>
+{{anchor (printf "pkg-note-%s" $marker)}}{{h "section" 2}}<a name="pkg-note-{{$marker}}">{{note_title $marker | html}}</a>
+{{notes_md $ $marker $content}}
+{{end}}
 {{end}}
-</ul>
 {{end}}
+- - -
+Generated by [godoc2md](http://godoc.org/github.com/davecheney/godoc2md)
+`
+
+// pkgsiteSymbolsTemplate renders the symbols of a package the way
+// pkg.go.dev does: the functions and the types in their own sections,
+// the constructors and methods of types one level below them.
+var pkgsiteSymbolsTemplate = `{{with .Consts}}{{anchor "pkg-constants"}}{{h "section" 2}}<a name="pkg-constants">Constants</a>
+{{range .}}{{node $ .Decl | pre}}
+{{const_values_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{end}}
+{{with .Vars}}{{anchor "pkg-variables"}}{{h "section" 2}}<a name="pkg-variables">Variables</a>
+{{range .}}{{node $ .Decl | pre}}
+{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{end}}
+{{with section_funcs $ ""}}{{anchor "pkg-functions"}}{{h "section" 2}}<a name="pkg-functions">Functions</a>
+{{range .}}` + pkgsiteFuncTemplate + `{{end}}{{end}}
+{{types_index_md}}{{with section_types $ ""}}{{anchor "pkg-types"}}{{h "section" 2}}<a name="pkg-types">Types</a>
+{{range .}}` + pkgsiteTypeTemplate + `{{end}}{{end}}` + pkgsiteSectionsTemplate + `{{other_examples_md $}}`
+
+// pkgsiteFuncTemplate renders a function of a package in the pkgsite
+// layout.
+var pkgsiteFuncTemplate = `{{$name_html := html .Name}}{{anchor $name_html}}{{h "symbol" 3}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
+{{example_md $ .Name}}
+`
+
+// pkgsiteTypeTemplate renders a type, its constructors and its methods
+// in the pkgsite layout.
+var pkgsiteTypeTemplate = `{{$tname := .Name}}{{$tname_html := html .Name}}{{anchor $tname_html}}{{h "symbol" 3}}<a name="{{$tname_html}}">type</a> [{{$tname_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{stability_badge .Doc}}{{concurrency_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}{{schema_md $ .}}{{if and .Consts const_groups}}
+{{anchor (printf "%s-constants" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-constants">Constants</a>
+{{end}}{{range .Consts}}
+{{node $ .Decl | pre }}
+{{const_values_md $ .Decl}}{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}{{range .Vars}}
+{{node $ .Decl | pre }}
+{{build_md $ .Decl}}{{comment_md .Doc}}{{end}}
+
+{{example_md $ $tname}}
+{{implementations_md $ $tname}}{{range .Funcs}}{{$name_html := html .Name}}{{anchor $name_html}}{{h "member" 4}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
+{{example_md $ .Name}}{{end}}
+{{with options_for $tname}}{{anchor (printf "%s-options" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-options">Options</a>
+{{range .}}{{$name_html := html .Name}}{{anchor $name_html}}{{h "member" 5}}<a name="{{$name_html}}">func</a> [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ .Name}}
+{{permalinks_md $ .Decl .Name}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
+{{example_md $ .Name}}{{end}}
+{{end}}
+{{range .Methods}}{{$name_html := html .Name}}{{anchor (printf "%s.%s" $tname_html $name_html)}}{{h "member" 4}}<a name="{{$tname_html}}.{{$name_html}}">func</a> ({{md .Recv}}) [{{$name_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}){{pkgsite_link_md $ (printf "%s.%s" $tname .Name)}}
+{{permalinks_md $ .Decl (printf "%s.%s" $tname .Name)}}{{decl_md $ .Decl}}
+{{hazard_md $ .Decl}}{{stability_badge .Doc}}{{build_md $ .Decl}}{{comment_md .Doc}}
+{{$name := printf "%s_%s" $tname .Name}}{{example_md $ $name}}
+{{end}}{{with accessors_for $tname}}
+{{anchor (printf "%s-accessors" $tname_html)}}{{h "subsection" 4}}<a name="{{$tname_html}}-accessors">Accessors</a>
+| Method | Signature | Description |
+| --- | --- | --- |
+{{range .}}{{accessor_row $ $tname .}}
 {{end}}
+{{end}}`
+
+// pkgsiteSectionsTemplate renders the functions and types moved to
+// sections by their section directives in the pkgsite layout, see
+// declDirectives.
+var pkgsiteSectionsTemplate = `{{range $section := decl_sections}}{{anchor (section_id $section)}}{{h "section" 2}}<a name="{{section_id $section}}">{{html $section}}</a>
+{{range section_funcs $ $section}}` + pkgsiteFuncTemplate + `{{end}}{{range section_types $ $section}}` + pkgsiteTypeTemplate + `{{end}}{{end}}`
+
+// summaryHeaderTemplate renders the title and overview of a package,
+// or the documentation of a command.
+var summaryHeaderTemplate = `{{with .PDoc}}
+{{if $.IsMain}}
+> {{package_title $}}
+{{overview_md $}}{{comment_md .Doc}}
+{{else}}
+{{h "title" 1}}{{package_title $}}
+` + "`" + `{{import_stmt .}}` + "`" + `
+
+{{badges_md $}}{{stability_badge .Doc}}{{overview_md $}}{{comment_md (overview_doc .Doc)}}
+`
+
+// summarySymbolsTemplate renders the exported symbols of a package as a
+// table, with their summary sentence and a link to their source. The rows
+// hold the anchors of the symbols, and of the constants and variables
+// sections, that doc links point at.
+var summarySymbolsTemplate = `{{anchor "pkg-summary"}}{{h "section" 2}}<a name="pkg-summary">API summary</a>
+
+| Symbol | Kind | Summary |
+| --- | --- | --- |
+{{- range $i, $c := .Consts}}
+{{$decl := .Decl}}| {{if not $i}}<a name="pkg-constants"></a>{{end}}{{range $i, $n := .Names}}{{if $i}}, {{end}}[{{html $n}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ $decl}}){{end}} | const | {{synopsis_md .Doc}} |
+{{- end}}{{range $i, $v := .Vars}}
+{{$decl := .Decl}}| {{if not $i}}<a name="pkg-variables"></a>{{end}}{{range $i, $n := .Names}}{{if $i}}, {{end}}[{{html $n}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ $decl}}){{end}} | var | {{synopsis_md .Doc}} |
+{{- end}}{{range .Funcs}}
+| <a name="{{.Name}}"></a>[{{html .Name}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}) | func | {{synopsis_md .Doc}} |
+{{- end}}{{range .Types}}{{$tname_html := html .Name}}
+| <a name="{{.Name}}"></a>[{{$tname_html}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}) | type | {{synopsis_md .Doc}} |
+{{- range .Funcs}}
+| <a name="{{.Name}}"></a>[{{html .Name}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}) | func | {{synopsis_md .Doc}} |
+{{- end}}{{range .Methods}}
+| <a name="{{$tname_html}}.{{.Name}}"></a>[{{$tname_html}}.{{html .Name}}]({{$.PDoc.ImportPath|srcLink|html}}{{posLink_url $ .Decl}}) | method | {{synopsis_md .Doc}} |
+{{- end}}{{end}}
+{{types_index_md}}`
+
+// moduleTemplate renders the landing page of a module, see ConvertModule.
+var moduleTemplate = `{{h "title" 1}}{{base .Path}}
+{{with .DocURL}}
+[![Go Reference](https://pkg.go.dev/badge/{{$.Path}}.svg)]({{.}})
 {{end}}
+{{comment_md (overview_doc .Doc)}}
+{{anchor "mod-install"}}{{h "section" 2}}<a name="mod-install">Install</a>
+{{if .Library}}
+` + "``` sh" + `
+go get {{.Path}}
+` + "```" + `
+{{end}}{{range .Packages}}{{if .IsCommand}}
+` + "``` sh" + `
+go install {{.ImportPath}}@latest
+` + "```" + `
+{{end}}{{end}}
+{{anchor "mod-packages"}}{{h "section" 2}}<a name="mod-packages">Packages</a>
+
+| Package | Synopsis |
+| --- | --- |
+{{- range .Packages}}
+| [{{.ImportPath}}]({{.Dir}}) | {{synopsis_md .Doc}} |
+{{- end}}
+
+{{anchor "mod-module"}}{{h "section" 2}}<a name="mod-module">Module</a>
+
+* Module path: ` + "`{{.Path}}`" + `{{with .GoVersion}}
+* Go version: {{.}}{{end}}{{with .License}}
+* License: [{{.}}]({{$.LicenseFile}}){{end}}
+
 - - -
 Generated by [godoc2md](http://godoc.org/github.com/davecheney/godoc2md)
 `
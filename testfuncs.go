@@ -0,0 +1,90 @@
+package godoc2md
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/godoc"
+)
+
+// isTestFunc reports whether the function declared by decl is a test
+// function of the given kind, "Test" or "Benchmark", run by go test: a
+// function named with the kind, and not followed by a lower case letter,
+// taking a *testing.T or a *testing.B.
+func isTestFunc(decl *ast.FuncDecl, kind string) bool {
+	name := decl.Name.Name
+	if decl.Recv != nil || !strings.HasPrefix(name, kind) || name == "TestMain" {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(name[len(kind):]); unicode.IsLower(r) {
+		return false
+	}
+	params := decl.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == kind[:1]
+}
+
+// testFuncs returns the test functions of the given kind of the test
+// files of the package of info, sorted by name.
+func (c *Converter) testFuncs(info *godoc.PageInfo, kind string) []*ast.FuncDecl {
+	var funcs []*ast.FuncDecl
+	for _, file := range c.testFiles(info) {
+		for _, decl := range file.Decls {
+			if f, ok := decl.(*ast.FuncDecl); ok && isTestFunc(f, kind) {
+				funcs = append(funcs, f)
+			}
+		}
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Name.Name < funcs[j].Name.Name })
+	return funcs
+}
+
+// testFuncsMdFunc returns the Benchmarks section of the package of info,
+// listing its benchmarks with their doc comments, set with WithBenchmarks,
+// followed by a table to paste benchstat results into, set with
+// WithBenchstatTable, and its Tests section, set with WithTests, or the
+// empty string if there are none.
+func (c *Converter) testFuncsMdFunc(info *godoc.PageInfo) string {
+	if (!c.benchmarks && !c.tests) || info.PDoc == nil || info.FSet == nil || info.IsFiltered {
+		return ""
+	}
+	var buf strings.Builder
+	section := func(kind, id, title string) {
+		funcs := c.testFuncs(info, kind)
+		if len(funcs) == 0 {
+			return
+		}
+		buf.WriteString(c.anchorFunc(id) + c.headingFunc("section", 2) + "<a name=\"" + id + "\">" + title + "</a>\n\n")
+		for _, f := range funcs {
+			name := f.Name.Name
+			buf.WriteString(c.anchorFunc(name) + c.headingFunc("symbol", 3) + "<a name=\"" + name + "\">func</a> [" + name + "](" + c.sourceLink(info, f.Pos(), f.End()) + ")\n\n")
+			if f.Doc != nil {
+				buf.WriteString(c.commentMdFunc(f.Doc.Text()))
+			}
+		}
+		if kind == "Benchmark" && c.benchstatTable {
+			buf.WriteString("| Benchmark | sec/op | B/op | allocs/op |\n| --- | --- | --- | --- |\n")
+			for _, f := range funcs {
+				buf.WriteString("| " + strings.TrimPrefix(f.Name.Name, kind) + " |  |  |  |\n")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	if c.benchmarks {
+		section("Benchmark", "pkg-benchmarks", "Benchmarks")
+	}
+	if c.tests {
+		section("Test", "pkg-tests", "Tests")
+	}
+	return buf.String()
+}
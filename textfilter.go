@@ -0,0 +1,170 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// A proseLine is a line of the doc comments passed to the text filter,
+// with the position of its first character in the sources.
+type proseLine struct {
+	text string
+	pos  token.Position
+}
+
+// commentProse returns the lines of the doc comment cg, without comment
+// markers. The lines of code blocks, which are indented, are blanked, so
+// that the text filter only checks prose, and the lines stay in step
+// with the sources.
+func commentProse(fset *token.FileSet, cg *ast.CommentGroup) []proseLine {
+	var lines []proseLine
+	for _, c := range cg.List {
+		pos := fset.Position(c.Slash)
+		text := c.Text
+		prefix := 2
+		if strings.HasPrefix(text, "/*") {
+			text = strings.TrimSuffix(text[2:], "*/")
+		} else {
+			text = text[2:]
+			if strings.HasPrefix(text, " ") {
+				text, prefix = text[1:], 3
+			}
+		}
+		for i, line := range strings.Split(text, "\n") {
+			p := pos
+			if i == 0 {
+				p.Column += prefix
+			} else {
+				p.Line, p.Column = pos.Line+i, 1
+			}
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				line = ""
+			}
+			lines = append(lines, proseLine{line, p})
+		}
+	}
+	return lines
+}
+
+// packageProse returns the lines of the doc comments of the package
+// files of info, each comment followed by a blank line: the package doc
+// comment, and those of the declarations, exported ones unless the
+// unexported symbols are documented. It also returns the position of the
+// package clause of the first file.
+func (c *Converter) packageProse(info *godoc.PageInfo) ([]proseLine, token.Position) {
+	fset, files := parseFileSet(c.fs, info.Dirname)
+	var pkgPos token.Position
+	if len(files) > 0 {
+		pkgPos = fset.Position(files[0].Package)
+	}
+	var lines []proseLine
+	add := func(cg *ast.CommentGroup, name string) {
+		if cg != nil && (c.unexported || name == "" || ast.IsExported(name)) {
+			lines = append(append(lines, commentProse(fset, cg)...), proseLine{})
+		}
+	}
+	for _, file := range files {
+		add(file.Doc, "")
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				add(decl.Doc, decl.Name.Name)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						doc := spec.Doc
+						if doc == nil && len(decl.Specs) == 1 {
+							doc = decl.Doc
+						}
+						add(doc, spec.Name.Name)
+					case *ast.ValueSpec:
+						doc := spec.Doc
+						if doc == nil && len(decl.Specs) == 1 {
+							doc = decl.Doc
+						}
+						add(doc, spec.Names[0].Name)
+					}
+				}
+				if len(decl.Specs) > 1 {
+					add(decl.Doc, "")
+				}
+			}
+		}
+	}
+	return lines, pkgPos
+}
+
+// filterLineRx matches the findings of text filters giving a line and
+// optionally a column, such as "stdin:3:5: message" or "3: message".
+var filterLineRx = regexp.MustCompile(`^(?:[^:\s]*:)?(\d+):(?:(\d+):)?\s*(.*)$`)
+
+// filterWordRx matches the findings of text filters listing words, such
+// as aspell list.
+var filterWordRx = regexp.MustCompile(`^\S+$`)
+
+// lintTextFilter pipes the prose of the doc comments of the package,
+// see packageProse, through the text filter command set with
+// WithTextFilter and reports its findings at the position of the source
+// comments: the lines of output giving a line and a column of the input,
+// such as "3:5: message", the lines of output made of a single word,
+// where the word is first found, and the other lines at the package
+// clause.
+func (c *Converter) lintTextFilter(info *godoc.PageInfo) []Finding {
+	if c.textFilter == "" {
+		return nil
+	}
+	lines, pkgPos := c.packageProse(info)
+	var input bytes.Buffer
+	for _, l := range lines {
+		input.WriteString(l.text + "\n")
+	}
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.Command(shell, flag, c.textFilter)
+	cmd.Stdin = &input
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		// linters exit with a non-zero status when they have findings
+		return []Finding{{Pos: pkgPos, Message: fmt.Sprintf("text filter %q: %v", c.textFilter, err)}}
+	}
+
+	var findings []Finding
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		f := Finding{Pos: pkgPos, Message: line}
+		if m := filterLineRx.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if n >= 1 && n <= len(lines) && lines[n-1].pos.IsValid() {
+				f.Pos, f.Message = lines[n-1].pos, m[3]
+				if col, err := strconv.Atoi(m[2]); err == nil && col > 0 {
+					f.Pos.Column += col - 1
+				}
+			}
+		} else if filterWordRx.MatchString(line) {
+			for _, l := range lines {
+				if i := strings.Index(l.text, line); i >= 0 && l.pos.IsValid() {
+					f.Pos = l.pos
+					f.Pos.Column += i
+					break
+				}
+			}
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
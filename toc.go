@@ -0,0 +1,58 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// tocMdFunc returns the entries of the table of contents nested under
+// the Index link at the top of the output: the functions, types and
+// methods of the package, with their examples. The links use the
+// anchors of the symbol and example headings.
+func (c *Converter) tocMdFunc(info *godoc.PageInfo) string {
+	if !c.toc || info.PDoc == nil || c.dialect.tocMacro != "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	entry := func(depth int, title, anchor string) {
+		fmt.Fprintf(&buf, "\n%s* [%s](#%s)", strings.Repeat("  ", depth), title, anchor)
+	}
+	examples := func(depth int, name string) {
+		if !c.showExamples {
+			return
+		}
+		for _, eg := range info.Examples {
+			if stripExampleSuffix(eg.Name) == name {
+				_, suffix := splitExampleName(eg.Name)
+				entry(depth, "Example"+suffix, c.exampleIDFunc(info, eg))
+			}
+		}
+	}
+	funcs := func(depth int, fns []*doc.Func) {
+		for _, f := range fns {
+			entry(depth, "func "+f.Name, f.Name)
+			examples(depth+1, f.Name)
+		}
+	}
+
+	examples(1, "")
+	funcs(1, info.PDoc.Funcs)
+	for _, t := range info.PDoc.Types {
+		entry(1, "type "+t.Name, t.Name)
+		examples(2, t.Name)
+		funcs(2, t.Funcs)
+		funcs(2, c.optionFuncs[t.Name])
+		for _, m := range t.Methods {
+			entry(2, "func ("+c.mdFunc(m.Recv)+") "+m.Name, t.Name+"."+m.Name)
+			examples(3, t.Name+"_"+m.Name)
+		}
+		if len(c.accessorFuncs[t.Name]) > 0 {
+			entry(2, "Accessors", t.Name+"-accessors")
+		}
+	}
+	return buf.String()
+}
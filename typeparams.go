@@ -0,0 +1,216 @@
+package godoc2md
+
+import (
+	"bytes"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"text/template"
+
+	"golang.org/x/tools/godoc"
+)
+
+// declMdFunc renders a declaration as a Go code block. When declaration
+// links are enabled and the declaration is generic, or when package links
+// or local declaration links are enabled and the declaration uses
+// identifiers of other packages, or types of the package with local
+// declaration links, the declaration is rendered as HTML instead, so that
+// the uses of each type parameter link back to its declaration in the
+// type parameter list, as pkg.go.dev does, the qualified identifiers link
+// to their packages and the types to their headings.
+func (c *Converter) declMdFunc(info *godoc.PageInfo, decl ast.Node) string {
+	text := c.nodeText(info, decl)
+	var name string
+	var params []string
+	if c.declLinks {
+		name, params = typeParams(decl)
+	}
+	if len(params) == 0 && !c.pkgLinks && !c.localDeclLinks {
+		return preFunc(text)
+	}
+	if html, ok := c.linkDecl(text, name, params); ok {
+		return html
+	}
+	return preFunc(text)
+}
+
+// nodeText prints node the same way the "node" template function does.
+func (c *Converter) nodeText(info *godoc.PageInfo, node interface{}) string {
+	return c.pres.FuncMap()["node"].(func(*godoc.PageInfo, interface{}) string)(info, node)
+}
+
+// typeParams returns the anchor name of a declaration together with the
+// names of its type parameters, including those of a method receiver.
+func typeParams(decl ast.Node) (name string, params []string) {
+	addFields := func(fl *ast.FieldList) {
+		if fl == nil {
+			return
+		}
+		for _, f := range fl.List {
+			for _, n := range f.Names {
+				params = append(params, n.Name)
+			}
+		}
+	}
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		if d.Tok != token.TYPE || len(d.Specs) != 1 {
+			return "", nil
+		}
+		ts, ok := d.Specs[0].(*ast.TypeSpec)
+		if !ok {
+			return "", nil
+		}
+		addFields(ts.TypeParams)
+		return ts.Name.Name, params
+	case *ast.FuncDecl:
+		name = d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) == 1 {
+			recv := d.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			var indices []ast.Expr
+			switch r := recv.(type) {
+			case *ast.IndexExpr:
+				recv, indices = r.X, []ast.Expr{r.Index}
+			case *ast.IndexListExpr:
+				recv, indices = r.X, r.Indices
+			}
+			if id, ok := recv.(*ast.Ident); ok {
+				name = id.Name + "." + name
+			}
+			for _, idx := range indices {
+				if id, ok := idx.(*ast.Ident); ok && id.Name != "_" {
+					params = append(params, id.Name)
+				}
+			}
+		}
+		addFields(d.Type.TypeParams)
+		return name, params
+	}
+	return "", nil
+}
+
+// linkDecl renders the printed declaration text as an HTML <pre>
+// block, where the first occurrence of each type parameter becomes the
+// anchor "name.param" and the later ones link to it, where the
+// identifiers qualified with the name of another package link to their
+// documentation, and where the types of the package other than name link
+// to their headings with local declaration links. It reports whether any
+// link was rendered.
+func (c *Converter) linkDecl(text, name string, params []string) (string, bool) {
+	isParam := make(map[string]bool, len(params))
+	for _, p := range params {
+		isParam[p] = true
+	}
+	declared := make(map[string]bool, len(params))
+
+	src := []byte(text)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	type tokenAt struct {
+		tok    token.Token
+		lit    string
+		offset int
+	}
+	var toks []tokenAt
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		toks = append(toks, tokenAt{tok, lit, file.Offset(pos)})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<pre>")
+	last, linked := 0, false
+	// the two tokens preceding the current one, for qualified identifiers,
+	// and the two following it, to tell the names of fields, parameters
+	// and methods from types
+	var prev, prev2, next, next2 tokenAt
+	for i, t := range toks {
+		tok, lit, offset := t.tok, t.lit, t.offset
+		next, next2 = tokenAt{}, tokenAt{}
+		if i+1 < len(toks) {
+			next = toks[i+1]
+		}
+		if i+2 < len(toks) {
+			next2 = toks[i+2]
+		}
+		switch {
+		case tok == token.IDENT && isParam[lit]:
+			template.HTMLEscape(&buf, src[last:offset])
+			anchor := template.HTMLEscapeString(name + "." + lit)
+			if !declared[lit] {
+				declared[lit] = true
+				buf.WriteString(`<a id="` + anchor + `">` + lit + `</a>`)
+			} else {
+				buf.WriteString(`<a href="#` + anchor + `">` + lit + `</a>`)
+			}
+			last, linked = offset+len(lit), true
+		case c.localDeclLinks && tok == token.IDENT && prev.tok != token.PERIOD && lit != name && !declaresName(next.tok, next2.tok):
+			url, ok := c.typeURL(lit)
+			if !ok {
+				break
+			}
+			template.HTMLEscape(&buf, src[last:offset])
+			buf.WriteString(`<a href="` + template.HTMLEscapeString(url) + `">` + lit + `</a>`)
+			last, linked = offset+len(lit), true
+		case (c.pkgLinks || c.localDeclLinks) && tok == token.IDENT && prev.tok == token.PERIOD && prev2.tok == token.IDENT &&
+			!isParam[prev2.lit] && prev2.offset >= last:
+			url, ok := c.qualifiedURL(prev2.lit, lit)
+			if !ok {
+				break
+			}
+			template.HTMLEscape(&buf, src[last:prev2.offset])
+			buf.WriteString(`<a href="` + template.HTMLEscapeString(url) + `">`)
+			template.HTMLEscape(&buf, src[prev2.offset:offset+len(lit)])
+			buf.WriteString(`</a>`)
+			last, linked = offset+len(lit), true
+		}
+		prev2, prev = prev, t
+	}
+	template.HTMLEscape(&buf, src[last:])
+	buf.WriteString("</pre>")
+	return buf.String(), linked
+}
+
+// declaresName reports whether an identifier followed by the tokens tok
+// and tok2 in a declaration is the name of a field, a parameter or a
+// method, such as Client in "Client *Client" or "Client() error", rather
+// than a type, such as Client in "Client[T]".
+func declaresName(tok, tok2 token.Token) bool {
+	switch tok {
+	case token.LBRACK:
+		return tok2 != token.IDENT
+	case token.IDENT, token.MUL, token.MAP, token.CHAN, token.ARROW,
+		token.FUNC, token.STRUCT, token.INTERFACE, token.LPAREN, token.PERIOD, token.ELLIPSIS:
+		return true
+	}
+	return false
+}
+
+// typeURL returns the link to the heading of the type name of the package
+// being converted: its anchor, or the page of the type written by
+// ConvertSplit.
+func (c *Converter) typeURL(name string) (string, bool) {
+	for _, t := range c.splitTypes {
+		if t.Name == name {
+			return typeFile(t) + "#" + name, true
+		}
+	}
+	if c.pdoc == nil {
+		return "", false
+	}
+	for _, t := range c.pdoc.Types {
+		if t.Name == name {
+			return "#" + name, true
+		}
+	}
+	return "", false
+}
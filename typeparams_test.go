@@ -0,0 +1,64 @@
+package godoc2md
+
+import (
+	"go/doc"
+	"testing"
+)
+
+func TestLinkDeclLocal(t *testing.T) {
+	types := []*doc.Type{{Name: "Client"}, {Name: "Server"}}
+	testData := []struct {
+		text     string
+		name     string
+		split    bool
+		expected string
+	}{
+		{"type Server struct {\n\tClient Client\n\tItems  []Client\n\tbuf    [8]byte\n}", "Server", false,
+			"<pre>type Server struct {\n\tClient <a href=\"#Client\">Client</a>\n\tItems  []<a href=\"#Client\">Client</a>\n\tbuf    [8]byte\n}</pre>"},
+		{"func (s *Server) Client() Client", "Server.Client", false,
+			"<pre>func (s *<a href=\"#Server\">Server</a>) Client() <a href=\"#Client\">Client</a></pre>"},
+		{"func New(client Client, opts ...Server) Client[int]", "New", false,
+			"<pre>func New(client <a href=\"#Client\">Client</a>, opts ...<a href=\"#Server\">Server</a>) <a href=\"#Client\">Client</a>[int]</pre>"},
+		{"type Server struct {\n\tClient\n}", "Server", true,
+			"<pre>type Server struct {\n\t<a href=\"Client.md#Client\">Client</a>\n}</pre>"},
+		{"func Dial(addr string) (*Client, error)", "Dial", true,
+			"<pre>func Dial(addr string) (*<a href=\"Client.md#Client\">Client</a>, error)</pre>"},
+	}
+	for n, tt := range testData {
+		c, err := New(WithDeclLinks(true), WithLocalDeclLinks(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.pdoc = &doc.Package{Name: "pkg", Types: types}
+		if tt.split {
+			c.splitTypes = types
+		}
+		if actual, _ := c.linkDecl(tt.text, tt.name, nil); actual != tt.expected {
+			t.Errorf("%d: linkDecl(%q) = %q, expected %q", n, tt.text, actual, tt.expected)
+		}
+	}
+}
+
+func TestTypeURL(t *testing.T) {
+	types := []*doc.Type{{Name: "Client"}, {Name: "Server"}}
+	testData := []struct {
+		name     string
+		split    bool
+		expected string
+		ok       bool
+	}{
+		{"Client", false, "#Client", true},
+		{"Client", true, "Client.md#Client", true},
+		{"Other", false, "", false},
+		{"Other", true, "", false},
+	}
+	for n, tt := range testData {
+		c := &Converter{pdoc: &doc.Package{Name: "pkg", Types: types}}
+		if tt.split {
+			c.splitTypes = types
+		}
+		if actual, ok := c.typeURL(tt.name); actual != tt.expected || ok != tt.ok {
+			t.Errorf("%d: typeURL(%q) = %q, %t, expected %q, %t", n, tt.name, actual, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package godoc2md
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// vanityRoots are the repositories of well-known vanity import paths,
+// used when their go-import meta tags can't be fetched, such as offline.
+// The roots ending with a slash map each of their first path elements,
+// such as go.uber.org/zap to github.com/uber-go/zap.
+var vanityRoots = map[string]string{
+	"cloud.google.com/go":        "github.com/googleapis/google-cloud-go",
+	"go.etcd.io/":                "github.com/etcd-io/",
+	"go.opentelemetry.io/otel":   "github.com/open-telemetry/opentelemetry-go",
+	"go.uber.org/":               "github.com/uber-go/",
+	"google.golang.org/api":      "github.com/googleapis/google-api-go-client",
+	"google.golang.org/grpc":     "github.com/grpc/grpc-go",
+	"google.golang.org/protobuf": "github.com/protocolbuffers/protobuf-go",
+	"gopkg.in/yaml.v2":           "github.com/go-yaml/yaml",
+	"gopkg.in/yaml.v3":           "github.com/go-yaml/yaml",
+	"honnef.co/go/tools":         "github.com/dominikh/go-tools",
+	"k8s.io/":                    "github.com/kubernetes/",
+	"sigs.k8s.io/":               "github.com/kubernetes-sigs/",
+}
+
+// goImportRx matches the go-import meta tags of the pages served for
+// "go get", capturing the import path prefix and the repository URL.
+var goImportRx = regexp.MustCompile(`<meta\s+name=["']go-import["']\s+content=["'](\S+)\s+\S+\s+(\S+?)["']`)
+
+// noVanity is the repository of the import paths that couldn't be
+// resolved into a repository.
+const noVanity = "-"
+
+// hostedPath returns the import path src rewritten with the repository
+// of its vanity import path, such as github.com/uber-go/zap/zapcore for
+// go.uber.org/zap/zapcore, when vanity resolution is enabled and src is
+// on none of the known source hosts, or src.
+func (c *Converter) hostedPath(src string) string {
+	if !c.vanity || !strings.Contains(strings.SplitN(src, "/", 2)[0], ".") {
+		return src
+	}
+	if h, _, ok := sourceHost(c.sourceHosts, src, c.ref); ok && h.Pattern != otherHostPattern {
+		return src
+	}
+	root, repo, ok := c.vanityRoot(src)
+	if !ok {
+		return src
+	}
+	return repo + strings.TrimPrefix(src, root)
+}
+
+// vanityRoot returns the vanity import path prefix of src and the path of
+// its repository, such as go.uber.org/zap and github.com/uber-go/zap,
+// from the vanity cache, the go-import meta tags served at src, or the
+// well-known vanity roots, in this order. The resolutions are saved in
+// the vanity cache, if set with WithVanity.
+func (c *Converter) vanityRoot(src string) (root, repo string, ok bool) {
+	if c.vanityPaths == nil {
+		c.vanityPaths = make(map[string]string)
+		if c.vanityCache != "" {
+			c.vanityPaths = readCache(c.vanityCache)
+		}
+	}
+	for prefix, r := range c.vanityPaths {
+		if src == prefix || strings.HasPrefix(src, prefix+"/") {
+			return prefix, r, r != noVanity
+		}
+	}
+
+	root, repo, ok = fetchGoImport(src)
+	if !ok {
+		root, repo, ok = knownVanityRoot(src)
+	}
+	if !ok {
+		// not saved, to be fetched again when online
+		c.vanityPaths[src] = noVanity
+		return "", "", false
+	}
+	c.vanityPaths[root] = repo
+	if c.vanityCache != "" {
+		saved := make(map[string]string)
+		for prefix, r := range c.vanityPaths {
+			if r != noVanity {
+				saved[prefix] = r
+			}
+		}
+		_ = writeCache(c.vanityCache, saved)
+	}
+	return root, repo, true
+}
+
+// fetchGoImport returns the import path prefix of src and the path of its
+// repository, from the go-import meta tags of https://src?go-get=1.
+func fetchGoImport(src string) (root, repo string, ok bool) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("https://" + src + "?go-get=1")
+	if err != nil {
+		return "", "", false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", false
+	}
+	// the meta tags are in the head of the page
+	page, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return "", "", false
+	}
+	for _, m := range goImportRx.FindAllStringSubmatch(string(page), -1) {
+		if src != m[1] && !strings.HasPrefix(src, m[1]+"/") {
+			continue
+		}
+		repo = strings.TrimSuffix(m[2], ".git")
+		if i := strings.Index(repo, "://"); i >= 0 {
+			repo = repo[i+3:]
+		}
+		return m[1], repo, true
+	}
+	return "", "", false
+}
+
+// knownVanityRoot returns the vanity import path prefix of src and the
+// path of its repository from vanityRoots.
+func knownVanityRoot(src string) (root, repo string, ok bool) {
+	for prefix, r := range vanityRoots {
+		if !strings.HasSuffix(prefix, "/") {
+			if src == prefix || strings.HasPrefix(src, prefix+"/") {
+				return prefix, r, true
+			}
+			continue
+		}
+		if rest := strings.TrimPrefix(src, prefix); rest != src && rest != "" {
+			elem := strings.SplitN(rest, "/", 2)[0]
+			return prefix + elem, r + elem, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,116 @@
+package godoc2md
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	// htmlAnchorRx matches the anchors of the HTML of the output.
+	htmlAnchorRx = regexp.MustCompile(`<a\s[^>]*\b(name|id)="([^"]*)"`)
+	// htmlLinkRx matches the intra-document links of the HTML of the
+	// output.
+	htmlLinkRx = regexp.MustCompile(`<a\s[^>]*\bhref="#([^"]*)"`)
+)
+
+// verifyMarkdown parses the Markdown src as GFM and returns its defects,
+// at their lines: the intra-document links to anchors and headings it
+// lacks, with the IDs that slug generates for headings unless it's nil,
+// the anchors it declares twice, the links without destination and the
+// code blocks left open.
+func verifyMarkdown(src []byte, slug func(heading string) string) []string {
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(src))
+	line := func(offset int) int { return bytes.Count(src[:offset], []byte("\n")) + 1 }
+
+	type finding struct {
+		offset int
+		msg    string
+	}
+	var findings []finding
+	var links []finding // to anchors, by ID
+	anchors := make(map[string]bool)
+	declared := make(map[string]bool) // anchors, by attribute and ID
+	html := func(s []byte, offset int) {
+		for _, m := range htmlAnchorRx.FindAllSubmatchIndex(s, -1) {
+			attr, id := string(s[m[2]:m[3]]), string(s[m[4]:m[5]])
+			if declared[attr+"="+id] {
+				findings = append(findings, finding{offset + m[0], "duplicate anchor " + strconv.Quote(id)})
+			}
+			declared[attr+"="+id], anchors[id] = true, true
+		}
+		for _, m := range htmlLinkRx.FindAllSubmatchIndex(s, -1) {
+			links = append(links, finding{offset + m[0], string(s[m[2]:m[3]])})
+		}
+	}
+	slugs := make(map[string]int) // of the headings, to number those repeated
+	var offset int                // of the current block
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			offset = n.Lines().At(0).Start
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			if slug == nil {
+				break
+			}
+			id := slug(string(n.Text(src)))
+			if k := slugs[id]; k > 0 {
+				slugs[id]++
+				id = fmt.Sprintf("%s-%d", id, k)
+			} else {
+				slugs[id] = 1
+			}
+			anchors[id] = true
+		case *ast.HTMLBlock:
+			for i := 0; i < n.Lines().Len(); i++ {
+				seg := n.Lines().At(i)
+				html(seg.Value(src), seg.Start)
+			}
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				seg := n.Segments.At(i)
+				html(seg.Value(src), seg.Start)
+			}
+		case *ast.Link:
+			dest := string(n.Destination)
+			switch {
+			case dest == "":
+				findings = append(findings, finding{offset, "link " + strconv.Quote(string(n.Text(src))) + " without destination"})
+			case strings.HasPrefix(dest, "#"):
+				links = append(links, finding{offset, dest[1:]})
+			}
+		case *ast.FencedCodeBlock:
+			if n.Lines().Len() == 0 {
+				break
+			}
+			fence := bytes.TrimLeft(src[n.Lines().At(n.Lines().Len()-1).Stop:], " ")
+			if !bytes.HasPrefix(fence, []byte("```")) && !bytes.HasPrefix(fence, []byte("~~~")) {
+				findings = append(findings, finding{offset - 1, "code block left open"}) // at its fence
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, l := range links {
+		if !anchors[l.msg] {
+			findings = append(findings, finding{l.offset, "link to missing anchor #" + l.msg})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].offset < findings[j].offset })
+	var msgs []string
+	for _, f := range findings {
+		msgs = append(msgs, fmt.Sprintf("line %d: %s", line(f.offset), f.msg))
+	}
+	return msgs
+}
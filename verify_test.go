@@ -0,0 +1,27 @@
+package godoc2md
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerifyMarkdown(t *testing.T) {
+	testData := []struct {
+		md       string
+		slug     func(string) string
+		expected []string
+	}{
+		{"## <a name=\"Foo\">func</a> [Foo](foo.go)\n\nSee [Foo](#Foo) and [Bar](#func-foo).\n", githubSlug, nil},
+		{"## Overview\n\n* [Overview](#overview)\n* [Index](#pkg-index)\n", githubSlug, []string{"line 4: link to missing anchor #pkg-index"}},
+		{"## Overview\n\n[Overview](#overview)\n", nil, []string{"line 3: link to missing anchor #overview"}},
+		{"<a id=\"T\"></a>\n## <a name=\"T\">type</a> T\n<a id=\"T\"></a>\n", nil, []string{"line 3: duplicate anchor \"T\""}},
+		{"Text\n\n[empty]()\n", githubSlug, []string{"line 3: link \"empty\" without destination"}},
+		{"Text\n\n``` go\nx := 1\n", githubSlug, []string{"line 3: code block left open"}},
+		{"``` go\nx := 1\n```\n\n<pre><a href=\"#x\">x</a></pre>\n", githubSlug, []string{"line 5: link to missing anchor #x"}},
+	}
+	for _, tt := range testData {
+		if got := verifyMarkdown([]byte(tt.md), tt.slug); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("verifyMarkdown(%q): expected %q, got %q", tt.md, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package godoc2md
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watch calls regenerate once, then again whenever the Go files of the
+// packages paths, as returned by Packages, the header file or the files
+// set with WithWatchFiles change, until regenerate returns an error,
+// which Watch returns. The files are polled every interval, which keeps
+// Watch free of platform specific file system notifications.
+func (c *Converter) Watch(paths []string, interval time.Duration, regenerate func() error) error {
+	c.mu.Lock()
+	dirs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if dir := c.watchDir(path); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	c.mu.Unlock()
+
+	hashes := make([]string, len(dirs))
+	var filesHash string
+	for {
+		changed := false
+		for i, dir := range dirs {
+			if h := c.sourceHash(dir); h != hashes[i] {
+				hashes[i], changed = h, true
+			}
+		}
+		if h := watchFilesHash(c.watchFiles); h != filesHash {
+			filesHash, changed = h, true
+		}
+		if changed {
+			if err := regenerate(); err != nil {
+				return err
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// watchFilesHash returns the hash of the files, and of the files of the
+// directories, of names. The files which can't be read are hashed as
+// missing, until they can.
+func watchFilesHash(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, name := range names {
+		files := []string{name}
+		if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+			files, _ = filepath.Glob(filepath.Join(name, "*"))
+		}
+		for _, file := range files {
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				fmt.Fprintf(h, "%s missing\n", file)
+				continue
+			}
+			fmt.Fprintf(h, "%s %d\n", file, len(src))
+			h.Write(src)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// watchDir returns the directory of the package path, resolved like
+// paths does, or the empty string if it isn't found.
+func (c *Converter) watchDir(path string) string {
+	if dir, ok := c.pkgDirs[path]; ok {
+		return dir
+	}
+	if isLocalPattern(path) {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return ""
+	}
+	bp, err := build.Import(path, "", build.FindOnly)
+	if err != nil {
+		return ""
+	}
+	return bp.Dir
+}
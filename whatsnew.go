@@ -0,0 +1,44 @@
+package godoc2md
+
+import (
+	"strings"
+
+	"golang.org/x/tools/godoc"
+)
+
+// whatsNewMdFunc returns the "What's new" section of the documentation,
+// listing the exported functions, types and methods of info that the
+// previous output of the conversion, set with WithPrevious, didn't
+// document. It returns the empty string when disabled, without previous
+// output, or when nothing was added.
+func (c *Converter) whatsNewMdFunc(info *godoc.PageInfo) string {
+	if !c.whatsNew || len(c.previous) == 0 || info.PDoc == nil {
+		return ""
+	}
+	old, _ := symbolSections(string(c.previous))
+	var buf strings.Builder
+	entry := func(title, anchor string) {
+		if _, ok := old[anchor]; !ok {
+			buf.WriteString("* [" + title + "](#" + anchor + ")\n")
+		}
+	}
+	for _, f := range info.PDoc.Funcs {
+		entry("func "+f.Name, f.Name)
+	}
+	for _, t := range info.PDoc.Types {
+		entry("type "+t.Name, t.Name)
+		for _, f := range t.Funcs {
+			entry("func "+f.Name, f.Name)
+		}
+		for _, f := range c.optionFuncs[t.Name] {
+			entry("func "+f.Name, f.Name)
+		}
+		for _, m := range t.Methods {
+			entry("func ("+c.mdFunc(m.Recv)+") "+m.Name, t.Name+"."+m.Name)
+		}
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return c.anchorFunc("pkg-whats-new") + c.headingFunc("section", 2) + "<a name=\"pkg-whats-new\">What's new</a>\n\n" + buf.String() + "\n"
+}